@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/google/uuid"
+	"go.uber.org/multierr"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Action is a kind of audited operation.
+type Action string
+
+const (
+	// ActionCreate is a media creation.
+	ActionCreate Action = "create"
+	// ActionUpdate is a media metadata update.
+	ActionUpdate Action = "update"
+	// ActionDelete is a media deletion.
+	ActionDelete Action = "delete"
+)
+
+// Entry is a record of a mutating operation.
+type Entry struct {
+	// Time is the time of the operation.
+	Time time.Time `json:"time"`
+	// Action is the kind of the operation.
+	Action Action `json:"action"`
+	// Key is the name of the key or token the operation was authorized with.
+	Key string `json:"key"`
+	// ClientIP is the IP address of the client.
+	ClientIP string `json:"client_ip"`
+	// RequestID is the ID of the HTTP request, may be empty.
+	RequestID string `json:"request_id"`
+	// Repo is the repository ID.
+	Repo string `json:"repo"`
+	// Item is the media ID.
+	Item uuid.UUID `json:"item"`
+	// Before is the media before the operation, nil for ActionCreate.
+	// The file paths of media are not recorded, they'd reveal the storage layout to clients.
+	Before *media.Media `json:"before"`
+	// After is the media after the operation, nil for ActionDelete.
+	After *media.Media `json:"after"`
+}
+
+// Query is a filter of log entries, zero value fields are not filtered on.
+type Query struct {
+	// Repo is the repository ID.
+	Repo string
+	// Item is the media ID.
+	Item uuid.UUID
+	// Since is the inclusive lower bound of the entry time.
+	Since time.Time
+	// Until is the exclusive upper bound of the entry time.
+	Until time.Time
+	// Limit is the maximum number of returned entries.
+	Limit int
+}
+
+// Matches returns whether an entry matches the query.
+func (q *Query) Matches(e *Entry) bool {
+	if q.Repo != "" && e.Repo != q.Repo {
+		return false
+	}
+	if q.Item != uuid.Nil && e.Item != q.Item {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Log is an append-only audit log, persisted to a file with an entry per line.
+type Log struct {
+	path string
+	f    *os.File
+	mu   sync.Mutex
+}
+
+// NewFile creates a Log appending to a file, the file is created if it does not exist.
+func NewFile(path string) (*Log, error) {
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to make audit log directories")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log file")
+	}
+
+	return &Log{path: path, f: f}, nil
+}
+
+// Path returns the path of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends an entry to the log, the entry time is set to the current time if it is zero.
+func (l *Log) Record(e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	e0 := *e
+	e0.Before, e0.After = withoutPaths(e.Before), withoutPaths(e.After)
+
+	b, err := json.Marshal(&e0)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize audit log entry")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write audit log entry")
+	}
+
+	return nil
+}
+
+// withoutPaths returns a copy of media without its file paths and the paths of its derivatives, nil if it's nil.
+func withoutPaths(m *media.Media) *media.Media {
+	if m == nil {
+		return nil
+	}
+
+	m0 := *m
+	m0.Path = ""
+	if m.Derivatives != nil {
+		m0.Derivatives = make(map[string]*media.Derivative, len(m.Derivatives))
+		for name, d := range m.Derivatives {
+			d0 := *d
+			d0.Path = ""
+			m0.Derivatives[name] = &d0
+		}
+	}
+
+	return &m0
+}
+
+// Query reads the entries matching a query and an optional filter, newest first.
+func (l *Log) Query(q Query, filter func(*Entry) bool) (res []*Entry, err error) {
+	// hold the lock, so that no partially written entries are read
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log file")
+	}
+	defer func() {
+		if err0 := f.Close(); err0 != nil {
+			err = multierr.Append(err, errors.Wrap(err0, "failed to close audit log file"))
+		}
+	}()
+
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue // skip empty lines
+		}
+
+		var e Entry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return nil, errors.Wrap(err, "failed to read audit log entry")
+		}
+
+		if q.Matches(&e) && (filter == nil || filter(&e)) {
+			res = append(res, &e)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read audit log file")
+	}
+
+	slices.Reverse(res)
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+
+	return res, err
+}
+
+// Close closes the log file.
+// The log should not be used anymore after calling Close.
+func (l *Log) Close() error {
+	return l.f.Close()
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogQuery(t *testing.T) {
+	l, err := NewFile(filepath.Join(t.TempDir(), "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	var (
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		items = []uuid.UUID{uuid.New(), uuid.New()}
+	)
+	// entries a0..a3 in repository a and b0..b1 in repository b, an hour apart
+	names := []string{"a0", "b0", "a1", "a2", "b1", "a3"}
+	for i, name := range names {
+		e := &Entry{
+			Time:   start.Add(time.Duration(i) * time.Hour),
+			Action: ActionCreate,
+			Key:    name,
+			Repo:   name[:1],
+			Item:   items[i%2],
+		}
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  Query
+		filter func(*Entry) bool
+		want   []string // the keys of the returned entries
+	}{
+		{name: "all", want: []string{"a3", "b1", "a2", "a1", "b0", "a0"}},
+		{name: "repository", query: Query{Repo: "a"}, want: []string{"a3", "a2", "a1", "a0"}},
+		{name: "item", query: Query{Item: items[1]}, want: []string{"a3", "a2", "b0"}},
+		{name: "time range", query: Query{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}, want: []string{"a1", "b0"}},
+		{name: "limit", query: Query{Limit: 2}, want: []string{"a3", "b1"}},
+		{name: "limit after filtering", query: Query{Repo: "b", Limit: 1}, want: []string{"b1"}},
+		{name: "filter", filter: func(e *Entry) bool { return e.Repo == "b" }, want: []string{"b1", "b0"}},
+		{name: "no match", query: Query{Repo: "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := l.Query(tt.query, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			for _, e := range entries {
+				keys = append(keys, e.Key)
+			}
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("got entries %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRecordWithoutPaths(t *testing.T) {
+	l, err := NewFile(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	m := &media.Media{
+		ID:          uuid.New(),
+		Path:        "/srv/nero/secret/file.png",
+		Derivatives: map[string]*media.Derivative{"small": {Path: "/srv/nero/secret/.thumbnails/small.png", Width: 10}},
+	}
+	if err := l.Record(&Entry{Action: ActionDelete, Repo: "a", Item: m.ID, Before: m}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(l.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "/srv/nero") {
+		t.Errorf("file paths recorded: %s", b)
+	}
+	if m.Path == "" || m.Derivatives["small"].Path == "" {
+		t.Error("recorded media modified")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/server/api"
+	v1 "github.com/zlataovce/nero/server/api/v1"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// handleAudit handles the audit sub-command.
+func (ac *appContext) handleAudit(cCtx *cli.Context) error {
+	c, err := v1.NewClientWithResponses(cCtx.String("url"))
+	if err != nil {
+		return errors.Wrap(err, "failed to create client")
+	}
+
+	params := &v1.GetAuditParams{
+		Repo:     api.MakeOptString(cCtx.String("repo")),
+		XNeroKey: api.MakeOptString(cCtx.String("key")),
+	}
+	if cCtx.IsSet("item") {
+		uid, err := uuid.Parse(cCtx.String("item"))
+		if err != nil {
+			return errors.Wrap(err, "could not parse item id")
+		}
+
+		params.Item = &uid
+	}
+	if cCtx.IsSet("since") {
+		since, err := time.Parse(time.RFC3339, cCtx.String("since"))
+		if err != nil {
+			return errors.Wrap(err, "could not parse since time")
+		}
+
+		params.Since = &since
+	}
+	if cCtx.IsSet("until") {
+		until, err := time.Parse(time.RFC3339, cCtx.String("until"))
+		if err != nil {
+			return errors.Wrap(err, "could not parse until time")
+		}
+
+		params.Until = &until
+	}
+	if cCtx.IsSet("limit") {
+		limit := cCtx.Int("limit")
+		params.Limit = &limit
+	}
+
+	res, err := c.GetAuditWithResponse(cCtx.Context, params)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+
+	code := res.StatusCode()
+	if code > 399 {
+		ac.logger.Error(
+			"request completed with errors",
+			zap.String("status", res.Status()),
+			zap.Int("code", code),
+			zap.ByteString("body", res.Body),
+		)
+
+		// error out to force an error exit code
+		return fmt.Errorf("request completed with error status code %d", code)
+	}
+
+	// print the entries as-is, so that they can be piped into other tools
+	_, err = os.Stdout.Write(res.Body)
+	return err
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"github.com/zlataovce/nero"
+	"github.com/zlataovce/nero/config"
 	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/registry"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
 	"os"
@@ -23,3 +26,23 @@ func (ac *appContext) handleConfig(cCtx *cli.Context) error {
 	ac.logger.Info("example configuration saved successfully", zap.String("path", path))
 	return nil
 }
+
+// handleConfigCheck handles the config check sub-command.
+func (ac *appContext) handleConfigCheck(cCtx *cli.Context) error {
+	path := filepath.Clean(cCtx.String("config"))
+
+	problems, err := config.Check(path, registry.ValidateRepo)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in configuration", len(problems))
+	}
+
+	ac.logger.Info("configuration is valid", zap.String("path", path))
+	return nil
+}
@@ -1,10 +1,79 @@
 package main
 
 import (
+	"github.com/zlataovce/nero/config"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/logging"
+	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
 )
 
 // appContext is the context of the CLI application.
 type appContext struct {
 	logger *zap.Logger
+	log    *logging.Logger
+}
+
+// setupLogger sets up the logger from the global flags, it is the CLI application Before hook.
+func (ac *appContext) setupLogger(cCtx *cli.Context) (err error) {
+	ac.log, err = logging.New(logOptions(cCtx, (*config.Log)(nil).Defaults()))
+	if err != nil {
+		return err
+	}
+
+	ac.logger = ac.log.Logger
+	return nil
+}
+
+// syncLogger flushes the logger, it is the CLI application After hook.
+func (ac *appContext) syncLogger(_ *cli.Context) error {
+	if ac.logger != nil {
+		_ = ac.logger.Sync()
+	}
+
+	return nil
+}
+
+// requireRepo checks that the target repository flag of the client commands is set,
+// it is the Before hook of the commands operating on a single repository.
+func requireRepo(cCtx *cli.Context) error {
+	if cCtx.String("repo") == "" {
+		return errors.New(`required flag "repo" not set`)
+	}
+
+	return nil
+}
+
+// configureLogger reconfigures the logger from a logging configuration section, the global flags take precedence.
+func (ac *appContext) configureLogger(cCtx *cli.Context, l *config.Log) error {
+	return ac.log.Reconfigure(logOptions(cCtx, l))
+}
+
+// logOptions makes logger options from a logging configuration section, overridden by explicitly set global flags.
+func logOptions(cCtx *cli.Context, l *config.Log) logging.Options {
+	opts := logging.Options{
+		Level:   l.Level,
+		Format:  l.Format,
+		Outputs: l.Outputs,
+	}
+	if lr := l.Rotation; lr != nil {
+		opts.Rotation = &logging.Rotation{
+			MaxSize:    lr.MaxSize,
+			MaxAge:     lr.MaxAge,
+			MaxBackups: lr.MaxBackups,
+			Compress:   lr.Compress,
+		}
+	}
+
+	if cCtx.IsSet("log-level") {
+		opts.Level = cCtx.String("log-level")
+	}
+	if cCtx.IsSet("log-format") {
+		opts.Format = cCtx.String("log-format")
+	}
+	if cCtx.IsSet("log-output") {
+		opts.Outputs = cCtx.StringSlice("log-output")
+	}
+
+	return opts
 }
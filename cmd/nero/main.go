@@ -3,20 +3,38 @@ package main
 import (
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
+	"net/http"
 	"os"
 )
 
 // main is the application entrypoint.
 func main() {
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
-
-	appCtx := &appContext{
-		logger: logger,
-	}
+	appCtx := &appContext{}
 	app := &cli.App{
 		Name:  "nero",
 		Usage: "CLI interface for the nero server",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "log-level",
+				Usage:   "the minimum logged level (debug, info, warn or error), overrides the configuration",
+				Value:   "info",
+				EnvVars: []string{"NERO_LOG_LEVEL"},
+			},
+			&cli.StringFlag{
+				Name:    "log-format",
+				Usage:   "the log format (json or console), overrides the configuration",
+				Value:   "json",
+				EnvVars: []string{"NERO_LOG_FORMAT"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "log-output",
+				Usage:   "the log outputs (stdout, stderr or file paths), overrides the configuration",
+				Value:   cli.NewStringSlice("stderr"),
+				EnvVars: []string{"NERO_LOG_OUTPUT"},
+			},
+		},
+		Before: appCtx.setupLogger,
+		After:  appCtx.syncLogger,
 		Commands: []*cli.Command{
 			{
 				Name:  "server",
@@ -32,6 +50,35 @@ func main() {
 				},
 				Action: appCtx.handleServer,
 			},
+			{
+				Name:  "repo",
+				Usage: "repository maintenance commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "thumbnails",
+						Usage: "regenerates the thumbnails of repositories, the server must not be running",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "config",
+								Aliases: []string{"c"},
+								Usage:   "the configuration path, defaults to config.toml",
+								Value:   "config.toml",
+								EnvVars: []string{"NERO_CONFIG_PATH"},
+							},
+							&cli.StringSliceFlag{
+								Name:    "repo",
+								Aliases: []string{"r"},
+								Usage:   "the repositories to regenerate, defaults to all configured repositories",
+							},
+							&cli.BoolFlag{
+								Name:  "missing",
+								Usage: "only generate thumbnails of media missing some of them",
+							},
+						},
+						Action: appCtx.handleRepoThumbnails,
+					},
+				},
+			},
 			{
 				Name:  "client",
 				Usage: "client commands",
@@ -43,10 +90,9 @@ func main() {
 						Required: true,
 					},
 					&cli.StringFlag{
-						Name:     "repo",
-						Aliases:  []string{"r"},
-						Usage:    "the target repo",
-						Required: true,
+						Name:    "repo",
+						Aliases: []string{"r"},
+						Usage:   "the target repo, required by all commands except audit",
 					},
 					&cli.StringFlag{
 						Name:    "key",
@@ -56,8 +102,9 @@ func main() {
 				},
 				Subcommands: []*cli.Command{
 					{
-						Name:  "upload",
-						Usage: "upload commands",
+						Name:   "upload",
+						Usage:  "upload commands",
+						Before: requireRepo,
 						Flags: []cli.Flag{
 							&cli.StringFlag{
 								Name:     "path",
@@ -100,8 +147,9 @@ func main() {
 						},
 					},
 					{
-						Name:  "delete",
-						Usage: "deletes media",
+						Name:   "delete",
+						Usage:  "deletes media",
+						Before: requireRepo,
 						Flags: []cli.Flag{
 							&cli.StringFlag{
 								Name:     "id",
@@ -112,6 +160,77 @@ func main() {
 						},
 						Action: appCtx.handleDelete,
 					},
+					{
+						Name:  "audit",
+						Usage: "queries the audit log, of all accessible repositories if no repo is set",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "item",
+								Aliases: []string{"i"},
+								Usage:   "the media id to filter on",
+							},
+							&cli.StringFlag{
+								Name:  "since",
+								Usage: "the inclusive lower bound of the entry time, in RFC 3339 format",
+							},
+							&cli.StringFlag{
+								Name:  "until",
+								Usage: "the exclusive upper bound of the entry time, in RFC 3339 format",
+							},
+							&cli.IntFlag{
+								Name:    "limit",
+								Aliases: []string{"l"},
+								Usage:   "the maximum number of entries",
+							},
+						},
+						Action: appCtx.handleAudit,
+					},
+				},
+			},
+			{
+				Name:  "webhook",
+				Usage: "webhook commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "listen",
+						Usage: "launches a local webhook receiver, logging received deliveries",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "host",
+								Usage: "the listening host, defaults to :9000",
+								Value: ":9000",
+							},
+							&cli.StringFlag{
+								Name:    "secret",
+								Aliases: []string{"s"},
+								Usage:   "the webhook secret, signatures are not verified if empty",
+							},
+							&cli.IntFlag{
+								Name:  "status",
+								Usage: "the response status code, used for testing retries",
+								Value: http.StatusNoContent,
+							},
+						},
+						Action: appCtx.handleWebhookListen,
+					},
+				},
+			},
+			{
+				Name:  "tracing",
+				Usage: "tracing commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "listen",
+						Usage: "launches a local OTLP/HTTP trace collector stand-in, logging received spans",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "host",
+								Usage: "the listening host, defaults to :4318",
+								Value: ":4318",
+							},
+						},
+						Action: appCtx.handleTracingListen,
+					},
 				},
 			},
 			{
@@ -127,11 +246,32 @@ func main() {
 					},
 				},
 				Action: appCtx.handleConfig,
+				Subcommands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "checks a configuration file, printing all problems",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "config",
+								Aliases: []string{"c"},
+								Usage:   "the configuration path, defaults to config.toml",
+								Value:   "config.toml",
+								EnvVars: []string{"NERO_CONFIG_PATH"},
+							},
+						},
+						Action: appCtx.handleConfigCheck,
+					},
+				},
 			},
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
+		logger := appCtx.logger
+		if logger == nil { // failed to set up logging
+			logger, _ = zap.NewProduction()
+		}
+
 		logger.Fatal("failed to run cli", zap.Error(err))
 	}
 }
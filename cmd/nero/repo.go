@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"github.com/zlataovce/nero/config"
+	"github.com/zlataovce/nero/derivative"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/registry"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"slices"
+	"sync/atomic"
+)
+
+// handleRepoThumbnails handles the repo thumbnails sub-command.
+func (ac *appContext) handleRepoThumbnails(cCtx *cli.Context) error {
+	cfg, err := config.ParseWithDefaults(cCtx.String("config"), registry.ValidateRepo)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	ids := cCtx.StringSlice("repo")
+	if len(ids) == 0 {
+		for id := range cfg.Repos {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+	}
+	for _, id := range ids {
+		if _, ok := cfg.Repos[id]; !ok {
+			return fmt.Errorf("unknown repository %s", id)
+		}
+	}
+
+	thumbnailer := derivative.NewThumbnailer(derivative.ThumbnailerOptions{Quality: cfg.Thumbnails.Quality}, ac.logger)
+	defer thumbnailer.Close()
+
+	for _, id := range ids {
+		if err := ac.generateThumbnails(cCtx, thumbnailer, id, cfg.Repos[id], cfg.Thumbnails.Workers); err != nil {
+			return errors.Wrapf(err, "failed to generate thumbnails of repository %s", id)
+		}
+	}
+
+	return nil
+}
+
+// generateThumbnails generates the thumbnails of the items of a repository.
+func (ac *appContext) generateThumbnails(
+	cCtx *cli.Context,
+	thumbnailer *derivative.Thumbnailer,
+	id string,
+	repoConfig *config.Repo,
+	workers int,
+) (err error) {
+	r, err := repo.NewFile(id, repoConfig.Path, repoConfig.LockPath, repoConfig.Meta, ac.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to open repository")
+	}
+	defer func() {
+		if err0 := r.Close(); err0 != nil {
+			err = multierr.Append(err, errors.Wrap(err0, "failed to close repository"))
+		}
+	}()
+
+	presets := registry.Thumbnails(repoConfig)
+	r.SetThumbnails(presets)
+
+	var generated, skipped atomic.Int64
+
+	g, ctx := errgroup.WithContext(cCtx.Context)
+	g.SetLimit(workers)
+	for _, m := range r.Items() {
+		if cCtx.Bool("missing") && !missingThumbnails(m, presets) {
+			continue
+		}
+
+		m := m
+		g.Go(func() error {
+			if err := thumbnailer.Generate(ctx, r, m); err != nil {
+				if !errors.Is(err, derivative.ErrUnsupported) {
+					return errors.Wrapf(err, "failed to generate thumbnails of media %s", m.ID)
+				}
+
+				ac.logger.Warn("skipping media", zap.String("repo", id), zap.String("id", m.ID.String()), zap.Error(err))
+				skipped.Add(1)
+				return nil
+			}
+
+			generated.Add(1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	ac.logger.Info(
+		"generated thumbnails",
+		zap.String("repo", id),
+		zap.Int("presets", len(presets)),
+		zap.Int64("items", generated.Load()),
+		zap.Int64("skipped", skipped.Load()),
+	)
+	return nil
+}
+
+// missingThumbnails checks whether media lack the thumbnail of a preset.
+func missingThumbnails(m *media.Media, presets map[string]repo.Thumbnail) bool {
+	for name := range presets {
+		if _, ok := m.Derivatives[name]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,32 +3,62 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/zlataovce/nero/audit"
 	"github.com/zlataovce/nero/config"
+	"github.com/zlataovce/nero/derivative"
 	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/metrics"
+	"github.com/zlataovce/nero/registry"
 	"github.com/zlataovce/nero/repo"
 	"github.com/zlataovce/nero/server"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/auth"
+	"github.com/zlataovce/nero/server/events"
+	"github.com/zlataovce/nero/server/ratelimit"
+	"github.com/zlataovce/nero/server/webhook"
+	"github.com/zlataovce/nero/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
-	"golang.org/x/exp/maps"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 type httpServer struct {
-	servers []*http.Server
-	errChan chan error
+	servers   []*http.Server
+	listeners []net.Listener
+	errChan   chan error
 
 	logger *zap.Logger
 }
 
-func (hs *httpServer) add(s *http.Server) {
+func (hs *httpServer) add(s *http.Server, l net.Listener) {
 	hs.servers = append(hs.servers, s)
+	hs.listeners = append(hs.listeners, l)
 	go func() {
-		hs.logger.Info("listening for http requests", zap.String("addr", s.Addr))
-		hs.errChan <- s.ListenAndServe()
+		hs.logger.Info(
+			"listening for http requests",
+			zap.String("addr", l.Addr().String()),
+			zap.String("network", l.Addr().Network()),
+			zap.Bool("tls", s.TLSConfig != nil),
+		)
+		if s.TLSConfig != nil {
+			hs.errChan <- s.ServeTLS(l, "", "") // the certificate is provided by the TLS config
+			return
+		}
+
+		hs.errChan <- s.Serve(l)
 	}()
 }
 
@@ -40,54 +70,249 @@ func (hs *httpServer) shutdown(ctx context.Context) (err error) {
 	return err
 }
 
+// close closes all servers immediately, closing their listeners, i.e. removing Unix domain sockets.
+// Servers already shut down are unaffected.
+func (hs *httpServer) close() {
+	for _, s := range hs.servers {
+		_ = s.Close()
+	}
+	for _, l := range hs.listeners { // the servers may not have started serving them yet
+		_ = l.Close()
+	}
+}
+
 // handleServer handles the server sub-command.
 func (ac *appContext) handleServer(cCtx *cli.Context) (err error) {
-	cfg, err := config.ParseWithDefaults(cCtx.String("config"))
+	cfg, err := config.ParseWithDefaults(cCtx.String("config"), registry.ValidateRepo)
 	if err != nil {
 		return errors.Wrap(err, "failed to load config")
 	}
+	if err := ac.configureLogger(cCtx, cfg.Log); err != nil {
+		return errors.Wrap(err, "failed to configure logger")
+	}
+
+	if cfg.Tracing.Enabled() {
+		tp, err := tracing.New(cCtx.Context, tracing.Options{
+			Endpoint:       cfg.Tracing.Endpoint,
+			Headers:        cfg.Tracing.Headers,
+			ServiceName:    cfg.Tracing.ServiceName,
+			ServiceVersion: server.ReadBuildInfo().Version,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create tracer provider")
+		}
+		defer func() {
+			// the signal context is done by now, flush with a fresh one
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err0 := tp.Shutdown(ctx); err0 != nil {
+				err = multierr.Append(err, errors.Wrap(err0, "failed to shutdown tracer provider"))
+			}
+		}()
+
+		ac.logger.Info("exporting traces", zap.String("endpoint", cfg.Tracing.Endpoint))
+	}
+
+	var (
+		errChan = make(chan error)
+		httpSrv = &httpServer{errChan: errChan, logger: ac.logger}
+		// the admin server is shut down last, so that probes and metrics are available while shutting down
+		adminSrv = &httpServer{errChan: errChan, logger: ac.logger}
 
-	repos0 := make(map[string]*repo.Repository, len(cfg.Repos))
-	for repoId, repoConfig := range cfg.Repos {
-		if _, ok := repos0[repoId]; ok {
-			return fmt.Errorf("duplicate repository ID %s, path %s", repoId, repoConfig.Path)
+		repos      = &repo.Set{}
+		accessLogs []*accessLogFile // reopened on reload
+		loaded     atomic.Bool      // whether all repositories were loaded and the servers were set up
+		draining   atomic.Bool      // whether the server is shutting down
+		metrics0   *metrics.Metrics
+	)
+	// if setup fails after some servers were started, or after shutting down
+	defer adminSrv.close()
+	defer httpSrv.close()
+	if cfg.HTTP.Admin.Enabled() {
+		// start the admin listener first, so that liveness can be probed while loading repositories
+		registry := prometheus.NewRegistry()
+		if metrics0, err = metrics.New(registry, repos.Values); err != nil {
+			return errors.Wrap(err, "failed to register metrics")
 		}
 
-		r, err := repo.NewFile(repoId, repoConfig.Path, repoConfig.LockPath, repoConfig.Meta, ac.logger)
+		handler, err := server.NewAdminRouter(server.AdminOptions{
+			Gatherer: registry,
+			Ready: func() error {
+				if draining.Load() {
+					return errors.New("shutting down")
+				}
+
+				return checkReady(loaded.Load(), repos)
+			},
+			Pprof: cfg.HTTP.Admin.Pprof,
+		}, ac.logger)
 		if err != nil {
-			return errors.Wrap(err, "failed to create repository")
+			return errors.Wrap(err, "failed to create admin router")
 		}
 
-		repos0[repoId] = r
+		l, err := server.Listen(cfg.HTTP.Admin.Host, server.ListenOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to listen on admin host")
+		}
 
-		ac.logger.Info(
-			"registered repository",
-			zap.String("repo", repoId),
-			zap.String("path", repoConfig.Path),
-		)
+		adminSrv.add(&http.Server{Handler: handler}, l)
 	}
-	defer func() {
-		for _, r := range repos0 {
-			if err0 := r.Close(); err0 != nil {
-				err = multierr.Append(err, errors.Wrap(err0, "failed to close repository"))
+
+	var auditLog *audit.Log
+	if cfg.Audit.Enabled() {
+		if auditLog, err = audit.NewFile(cfg.Audit.Path); err != nil {
+			return errors.Wrap(err, "failed to open audit log")
+		}
+		defer func() {
+			if err0 := auditLog.Close(); err0 != nil {
+				err = multierr.Append(err, errors.Wrap(err0, "failed to close audit log"))
 			}
+		}()
+
+		ac.logger.Info("opened audit log", zap.String("path", auditLog.Path()))
+	}
+
+	var dispatcher *webhook.Dispatcher
+	if cfg.Webhooks.Enabled() {
+		if dispatcher, err = makeDispatcher(cfg.Webhooks, ac.logger); err != nil {
+			return errors.Wrap(err, "failed to create webhook dispatcher")
 		}
-	}()
+		defer func() {
+			if err0 := dispatcher.Close(); err0 != nil {
+				err = multierr.Append(err, errors.Wrap(err0, "failed to close webhook dispatcher"))
+			}
+		}()
 
-	var (
-		repos   = maps.Values(repos0)
-		httpSrv = &httpServer{
-			errChan: make(chan error),
-			logger:  ac.logger,
+		ac.logger.Info("registered webhooks", zap.Int("count", len(cfg.Webhooks.Hooks)))
+	}
+
+	var broker *events.Broker
+	if cfg.HTTP.Nero.Enabled() {
+		broker = events.NewBroker(cfg.HTTP.Nero.EventBuffer)
+	}
+
+	// background work on repositories, i.e. backfilling dimensions and placeholders, is abandoned on exit
+	bgCtx, cancelBg := context.WithCancel(cCtx.Context)
+	defer cancelBg()
+
+	thumbnailer := derivative.NewThumbnailer(derivative.ThumbnailerOptions{
+		Workers:   cfg.Thumbnails.Workers,
+		QueueSize: cfg.Thumbnails.QueueSize,
+		Quality:   cfg.Thumbnails.Quality,
+	}, ac.logger)
+	defer thumbnailer.Close()
+
+	var resizer *derivative.Resizer
+	if cfg.HTTP.Nekos.Enabled() && cfg.Resize.Enabled() {
+		resizer = derivative.NewResizer(derivative.ResizerOptions{
+			Sizes:     cfg.Resize.Sizes,
+			CacheSize: int64(cfg.Resize.CacheSize) << 20,
+			Quality:   cfg.Resize.Quality,
+		}, ac.logger)
+	}
+
+	regOpts := registry.Options{
+		Attach: func(r *repo.Repository) {
+			r.Listen(thumbnailer.Listener(r))
+			if dispatcher != nil {
+				r.Listen(dispatcher.Handle)
+			}
+			if broker != nil {
+				r.Listen(broker.Publish)
+			}
+			if metrics0 != nil {
+				r.Observe(metrics0)
+			}
+
+			go ac.backfill(bgCtx, r)
+		},
+		Detach: func(r *repo.Repository) {
+			thumbnailer.Detach(r)
+			if resizer != nil {
+				resizer.Detach(r)
+			}
+		},
+	}
+	if cfg.Registry.Enabled() {
+		regOpts.OverlayPath = cfg.Registry.Path
+		regOpts.Root = cfg.Registry.Root
+	}
+
+	reg := registry.New(repos, regOpts, ac.logger)
+	defer func() {
+		if err0 := reg.Close(); err0 != nil {
+			err = multierr.Append(err, err0)
 		}
-	)
+	}()
+	if err := reg.Load(cfg.Repos); err != nil {
+		return errors.Wrap(err, "failed to load repositories")
+	}
+
 	if cfg.HTTP.Nero.Enabled() {
-		handler, err := server.NewNeroRouter(repos, ac.logger)
+		opts, err := makeRouterOptions(cfg.HTTP.Nero, repos)
+		if err != nil {
+			return errors.Wrap(err, "failed to configure nero api router")
+		}
+		opts.AuditLog = auditLog
+		opts.Registry = reg
+		opts.AdminKey = cfg.HTTP.Nero.AdminKey
+		opts.MaxBodySize = int64(cfg.HTTP.Nero.MaxBodySize) << 20
+		opts.Metrics = metrics0
+		opts.Tracing = cfg.Tracing.Enabled()
+		var accessLog *accessLogFile
+		if opts.AccessLogger, accessLog, err = makeAccessLogger(cfg.HTTP.Nero.AccessLog, ac.logger); err != nil {
+			return errors.Wrap(err, "failed to create nero api access logger")
+		}
+		if accessLog != nil {
+			defer accessLog.Close()
+			accessLogs = append(accessLogs, accessLog)
+		}
+		defer opts.AccessLogger.Sync()
+		opts.Broker = broker
+		if jwtConfig := cfg.HTTP.Nero.JWT; jwtConfig.Enabled() {
+			opts.JWTVerifier, err = auth.NewJWTVerifier(auth.JWTOptions{
+				Secret:      jwtConfig.Secret,
+				KeyPath:     jwtConfig.KeyPath,
+				JWKSPath:    jwtConfig.JWKSPath,
+				Issuer:      jwtConfig.Issuer,
+				Audience:    jwtConfig.Audience,
+				ScopeClaim:  jwtConfig.ScopeClaim,
+				ScopePrefix: jwtConfig.ScopePrefix,
+				AdminScope:  jwtConfig.AdminScope,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to create jwt verifier")
+			}
+		}
+
+		if cfg.HTTP.Nero.TLSClientCA != "" {
+			certOpts := auth.CertOptions{ScopePrefix: "repo:", AdminScope: "admin"}
+			if jwtConfig := cfg.HTTP.Nero.JWT; jwtConfig != nil { // share the scope mapping with tokens
+				certOpts = auth.CertOptions{ScopePrefix: jwtConfig.ScopePrefix, AdminScope: jwtConfig.AdminScope}
+			}
+
+			opts.CertMapper = auth.NewCertMapper(certOpts)
+		}
+
+		handler, err := server.NewNeroRouter(repos, opts, ac.logger)
 		if err != nil {
 			return errors.Wrap(err, "failed to create nero api router")
 		}
 
-		httpSrv.add(&http.Server{Addr: cfg.HTTP.Nero.Host, Handler: handler})
+		s, err := makeHTTPServer(cfg.HTTP.Nero, handler, true, ac.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create nero api server")
+		}
+		s.RegisterOnShutdown(opts.Broker.Close) // end event streams, so that they don't hold up the shutdown
+
+		l, err := listen(cfg.HTTP.Nero)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen on nero api host")
+		}
+
+		httpSrv.add(s, l)
 	}
 	if cfg.HTTP.Nekos.Enabled() {
 		var baseURL *url.URL
@@ -97,26 +322,352 @@ func (ac *appContext) handleServer(cCtx *cli.Context) (err error) {
 			}
 		}
 
-		handler, err := server.NewNekosRouter(repos, baseURL, ac.logger)
+		opts, err := makeRouterOptions(cfg.HTTP.Nekos, repos)
+		if err != nil {
+			return errors.Wrap(err, "failed to configure nekos api router")
+		}
+		opts.Metrics = metrics0
+		opts.Tracing = cfg.Tracing.Enabled()
+		var accessLog *accessLogFile
+		if opts.AccessLogger, accessLog, err = makeAccessLogger(cfg.HTTP.Nekos.AccessLog, ac.logger); err != nil {
+			return errors.Wrap(err, "failed to create nekos api access logger")
+		}
+		if accessLog != nil {
+			defer accessLog.Close()
+			accessLogs = append(accessLogs, accessLog)
+		}
+		defer opts.AccessLogger.Sync()
+
+		opts.Resizer = resizer
+
+		handler, err := server.NewNekosRouter(repos, baseURL, opts, ac.logger)
 		if err != nil {
 			return errors.Wrap(err, "failed to create nekos api router")
 		}
 
-		httpSrv.add(&http.Server{Addr: cfg.HTTP.Nekos.Host, Handler: handler})
+		s, err := makeHTTPServer(cfg.HTTP.Nekos, handler, false, ac.logger)
+		if err != nil {
+			return errors.Wrap(err, "failed to create nekos api server")
+		}
+
+		l, err := listen(cfg.HTTP.Nekos)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen on nekos api host")
+		}
+
+		httpSrv.add(s, l)
 	}
 
-	ctx, stop := signal.NotifyContext(cCtx.Context, os.Interrupt)
+	loaded.Store(true)
+
+	ctx, stop := signal.NotifyContext(cCtx.Context, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	select {
-	case <-ctx.Done():
-		ac.logger.Info("shutting down gracefully")
-		if err = httpSrv.shutdown(ctx); err != nil {
-			err = errors.Wrap(err, "failed to shutdown http server")
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop() // a second signal terminates the process immediately
+			ac.logger.Info(
+				"shutting down gracefully",
+				zap.Duration("readiness_delay", cfg.Shutdown.ReadinessDelay),
+				zap.Duration("drain_timeout", cfg.Shutdown.DrainTimeout),
+			)
+
+			// the remaining resources are closed by the deferred calls, in reverse order of creation
+			return ac.shutdown([]shutdownStage{
+				{name: "readiness", run: func() error {
+					draining.Store(true)
+					time.Sleep(cfg.Shutdown.ReadinessDelay)
+					return nil
+				}},
+				{name: "drain", run: func() error {
+					// the signal context is done by now, drain with a fresh one
+					ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.DrainTimeout)
+					defer cancel()
+
+					if err := httpSrv.shutdown(ctx); err != nil {
+						httpSrv.close()
+						return errors.Wrap(err, "failed to drain in-flight requests, closed their connections")
+					}
+
+					return nil
+				}},
+				{name: "thumbnails", run: thumbnailer.Close},
+				{name: "repositories", run: reg.Close},
+			})
+		case err = <-errChan:
+			return errors.Wrap(err, "http server errored")
+		case <-hup:
+			ac.logger.Info("reloading configuration")
+			if err := ac.reload(cCtx, reg, accessLogs); err != nil {
+				ac.logger.Error("failed to reload configuration, keeping the previous one", zap.Error(err))
+			}
+		}
+	}
+}
+
+// shutdownStage is a step of a graceful shutdown.
+type shutdownStage struct {
+	name string
+	run  func() error
+}
+
+// shutdown runs shutdown stages in order, a failed stage is reported and the rest are still run.
+func (ac *appContext) shutdown(stages []shutdownStage) (err error) {
+	for _, stage := range stages {
+		start := time.Now()
+		if err0 := stage.run(); err0 != nil {
+			ac.logger.Error("shutdown stage failed", zap.String("stage", stage.name), zap.Error(err0))
+			err = multierr.Append(err, errors.Wrapf(err0, "shutdown stage %s failed", stage.name))
+			continue
 		}
-	case err = <-httpSrv.errChan:
-		err = errors.Wrap(err, "http server errored")
+
+		ac.logger.Info("completed shutdown stage", zap.String("stage", stage.name), zap.Duration("took", time.Since(start)))
 	}
 
 	return err
 }
+
+// reload reloads the configuration file, reconfiguring the logger and reconciling the served repositories.
+// Other sections are only read on startup, changing them requires a restart.
+// Managed repositories are reloaded from the managed configuration overlay too.
+// Access log files are reopened, so that they can be rotated.
+func (ac *appContext) reload(cCtx *cli.Context, reg *registry.Registry, accessLogs []*accessLogFile) error {
+	cfg, err := config.ParseWithDefaults(cCtx.String("config"), registry.ValidateRepo)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+	if err := ac.configureLogger(cCtx, cfg.Log); err != nil {
+		return errors.Wrap(err, "failed to configure logger")
+	}
+	for _, al := range accessLogs {
+		if err := al.reopen(); err != nil {
+			return err
+		}
+	}
+
+	return reg.Load(cfg.Repos)
+}
+
+// backfill probes the dimensions and computes the placeholders of repository items that don't have them yet,
+// i.e. created by older versions.
+func (ac *appContext) backfill(ctx context.Context, r *repo.Repository) {
+	n, err := r.Backfill(ctx)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, repo.ErrClosed) {
+			ac.logger.Error("failed to backfill media properties", zap.String("repo", r.ID()), zap.Error(err))
+		}
+		return
+	}
+
+	if n > 0 {
+		ac.logger.Info("backfilled media properties", zap.String("repo", r.ID()), zap.Int("items", n))
+	}
+}
+
+// checkReady checks whether the loaded repositories are ready to serve requests.
+func checkReady(loaded bool, repos *repo.Set) error {
+	if !loaded {
+		return errors.New("repositories not loaded")
+	}
+
+	for _, r := range repos.Values() {
+		if err := r.CheckWritable(); err != nil {
+			return errors.Wrapf(err, "repository %s storage not writable", r.ID())
+		}
+	}
+
+	return nil
+}
+
+// makeRouterOptions creates the router options common to both APIs from a server configuration section,
+// API keys of rate limited clients are matched against the keys of repos.
+func makeRouterOptions(hs *config.HTTPServer, repos *repo.Set) (opts server.RouterOptions, err error) {
+	if opts.TrustedProxies, err = api.ParsePrefixes(hs.TrustedProxies); err != nil {
+		return opts, errors.Wrap(err, "failed to parse trusted proxies")
+	}
+
+	if rl := hs.RateLimit; rl != nil {
+		var global *ratelimit.Rule
+		if rl.Limit > 0 {
+			global = &ratelimit.Rule{Limit: rl.Limit, Period: rl.Period}
+		}
+
+		routes := make(map[string]ratelimit.Rule, len(rl.Routes))
+		for pattern, rule := range rl.Routes {
+			routes[pattern] = ratelimit.Rule{Limit: rule.Limit, Period: rule.Period}
+		}
+
+		opts.Limiter = ratelimit.New(global, routes)
+
+		switch rl.By {
+		case "ip":
+			opts.LimiterKeyFunc = ratelimit.ByIP
+		case "key":
+			opts.LimiterKeyFunc = ratelimit.ByKey(repos)
+		default:
+			return opts, fmt.Errorf("unknown rate limit client identification method %s", rl.By)
+		}
+	}
+
+	return opts, nil
+}
+
+// makeHTTPServer creates an HTTP server from an HTTP API configuration section, serving TLS or h2c if configured.
+// Client certificates are only requested if clientCerts is true.
+func makeHTTPServer(hs *config.HTTPServer, handler http.Handler, clientCerts bool, logger *zap.Logger) (*http.Server, error) {
+	s := &http.Server{Handler: handler}
+	if hs.TLSEnabled() {
+		opts := server.TLSOptions{CertPath: hs.TLSCert, KeyPath: hs.TLSKey}
+		if clientCerts {
+			opts.ClientCAPath = hs.TLSClientCA
+			opts.RequireClientCert = hs.TLSClientAuth == "required"
+		}
+
+		tlsConfig, err := server.NewTLSConfig(opts, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to configure tls")
+		}
+
+		s.TLSConfig = tlsConfig // HTTP/2 is negotiated with ALPN
+	} else if hs.H2C {
+		s.Handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	return s, nil
+}
+
+// listen opens the listener of an HTTP API configuration section.
+func listen(hs *config.HTTPServer) (net.Listener, error) {
+	mode, err := hs.SocketFileMode()
+	if err != nil {
+		return nil, err
+	}
+
+	return server.Listen(hs.Host, server.ListenOptions{SocketMode: mode, SocketGroup: hs.SocketGroup})
+}
+
+// makeAccessLogger creates the access logger from an access log configuration section.
+// Entries are written to a separate JSON file, if configured, otherwise to the main logger.
+// The separate file is returned too, nil if there is none.
+func makeAccessLogger(al *config.AccessLog, logger *zap.Logger) (*zap.Logger, *accessLogFile, error) {
+	if al == nil {
+		return logger.Named("access").WithOptions(zap.WithCaller(false)), nil, nil // the caller is always the middleware
+	}
+
+	var (
+		core = logger.Core()
+		file *accessLogFile
+	)
+	if al.Path != "" {
+		var err error
+		if file, err = openAccessLogFile(al.Path); err != nil {
+			return nil, nil, err
+		}
+
+		core = zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), file, zapcore.InfoLevel)
+	}
+	if al.SampleInitial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, al.SampleInitial, al.SampleThereafter)
+	}
+
+	return zap.New(core).Named("access"), file, nil
+}
+
+// accessLogFile is a separate access log file, reopened on reload, so that it can be rotated by external tools.
+type accessLogFile struct {
+	path string
+	f    *os.File
+	mu   sync.Mutex // guards f, held during writes
+}
+
+func openAccessLogFile(path string) (*accessLogFile, error) {
+	af := &accessLogFile{path: path}
+	if err := af.reopen(); err != nil {
+		return nil, err
+	}
+
+	return af, nil
+}
+
+func (af *accessLogFile) Write(p []byte) (int, error) {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	return af.f.Write(p)
+}
+
+func (af *accessLogFile) Sync() error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	return af.f.Sync()
+}
+
+// reopen opens the file at the path again and closes the previous one, the previous one is kept on error.
+func (af *accessLogFile) reopen() error {
+	f, err := os.OpenFile(af.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return errors.Wrap(err, "failed to open access log file")
+	}
+
+	af.mu.Lock()
+	old := af.f
+	af.f = f
+	af.mu.Unlock()
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			return errors.Wrap(err, "failed to close previous access log file")
+		}
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (af *accessLogFile) Close() error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	return af.f.Close()
+}
+
+// makeDispatcher creates a webhook dispatcher from the webhook configuration section.
+func makeDispatcher(w *config.Webhooks, logger *zap.Logger) (*webhook.Dispatcher, error) {
+	hooks := make([]*webhook.Hook, 0, len(w.Hooks))
+	for name, hookConfig := range w.Hooks {
+		h := &webhook.Hook{
+			Name:   name,
+			URL:    hookConfig.URL,
+			Secret: hookConfig.Secret,
+			Repos:  hookConfig.Repos,
+		}
+		for _, e := range hookConfig.Events {
+			switch e {
+			case "create":
+				h.Events = append(h.Events, repo.EventCreate)
+			case "update":
+				h.Events = append(h.Events, repo.EventUpdate)
+			case "delete":
+				h.Events = append(h.Events, repo.EventDelete)
+			default:
+				return nil, fmt.Errorf("unknown event type %s in webhook %s", e, name)
+			}
+		}
+
+		hooks = append(hooks, h)
+	}
+
+	return webhook.NewDispatcher(hooks, webhook.Options{
+		QueuePath:   w.QueuePath,
+		MaxAttempts: w.MaxAttempts,
+		MinBackoff:  w.MinBackoff,
+		MaxBackoff:  w.MaxBackoff,
+		Timeout:     w.Timeout,
+	}, logger)
+}
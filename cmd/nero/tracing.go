@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/hex"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/urfave/cli/v2"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+// handleTracingListen handles the tracing listen sub-command.
+func (ac *appContext) handleTracingListen(cCtx *cli.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			ac.logger.Error("failed to read request body", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(b, &req); err != nil {
+			ac.logger.Error("failed to decode export request", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					attrs := make(map[string]string, len(s.Attributes))
+					for _, kv := range s.Attributes {
+						attrs[kv.Key] = formatValue(kv.Value)
+					}
+
+					ac.logger.Info(
+						"received span",
+						zap.String("name", s.Name),
+						zap.String("scope", ss.Scope.GetName()),
+						zap.String("trace_id", hex.EncodeToString(s.TraceId)),
+						zap.String("span_id", hex.EncodeToString(s.SpanId)),
+						zap.String("parent_span_id", hex.EncodeToString(s.ParentSpanId)),
+						zap.Duration("duration", time.Duration(s.EndTimeUnixNano-s.StartTimeUnixNano)),
+						zap.Any("attributes", attrs),
+					)
+				}
+			}
+		}
+
+		b, err = proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(b)
+	})
+
+	srv := &http.Server{Addr: cCtx.String("host"), Handler: mux}
+	go func() {
+		ctx, stop := signal.NotifyContext(cCtx.Context, os.Interrupt)
+		defer stop()
+
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	ac.logger.Info("listening for otlp trace exports", zap.String("addr", srv.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "http server errored")
+	}
+
+	return nil
+}
+
+// formatValue formats a scalar attribute value, falling back to the text format of other values.
+func formatValue(v *commonpb.AnyValue) string {
+	switch v0 := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v0.StringValue
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v0.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v0.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v0.BoolValue)
+	}
+
+	return v.String()
+}
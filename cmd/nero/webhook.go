@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/server/webhook"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// handleWebhookListen handles the webhook listen sub-command.
+func (ac *appContext) handleWebhookListen(cCtx *cli.Context) error {
+	var (
+		secret = cCtx.String("secret")
+		status = cCtx.Int("status")
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			ac.logger.Error("failed to read request body", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		signature := r.Header.Get(webhook.SignatureHeader)
+		if secret != "" && !webhook.Verify(secret, b, signature) {
+			ac.logger.Warn(
+				"rejected delivery with invalid signature",
+				zap.String("delivery", r.Header.Get(webhook.DeliveryHeader)),
+				zap.String("signature", signature),
+			)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ac.logger.Info(
+			"received delivery",
+			zap.String("delivery", r.Header.Get(webhook.DeliveryHeader)),
+			zap.String("event", r.Header.Get(webhook.EventHeader)),
+			zap.ByteString("body", b),
+		)
+		w.WriteHeader(status)
+	})
+
+	srv := &http.Server{Addr: cCtx.String("host"), Handler: handler}
+	go func() {
+		ctx, stop := signal.NotifyContext(cCtx.Context, os.Interrupt)
+		defer stop()
+
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	ac.logger.Info("listening for webhook deliveries", zap.String("addr", srv.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "http server errored")
+	}
+
+	return nil
+}
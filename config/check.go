@@ -0,0 +1,414 @@
+package config
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/zlataovce/nero/internal/errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxResizeSize is the maximum width and height images can be resized to.
+const maxResizeSize = 8192
+
+// thumbnailNamePattern matches valid thumbnail preset names, they are a part of file names.
+var thumbnailNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Problem is a problem with the configuration.
+type Problem struct {
+	// File is the path of the file defining the key, empty if not known, i.e. for environment variable overrides.
+	File string
+	// Line is the line of the key in the file, 0 if not known.
+	Line int
+	// Key is the offending key.
+	Key string
+	// Message is the description of the problem.
+	Message string
+}
+
+// String returns the problem prefixed with its location.
+func (p Problem) String() string {
+	var sb strings.Builder
+	if p.File != "" {
+		sb.WriteString(p.File)
+		if p.Line > 0 {
+			sb.WriteString(":" + strconv.Itoa(p.Line))
+		}
+		sb.WriteString(": ")
+	}
+	if p.Key != "" {
+		sb.WriteString(p.Key + ": ")
+	}
+
+	sb.WriteString(p.Message)
+	return sb.String()
+}
+
+// RepoValidator checks the settings of a repository interpreted by other packages, i.e. its metadata,
+// returns problems with keys relative to the repository section.
+type RepoValidator func(r *Repo) []Problem
+
+// ProblemsError is an error about problems with the configuration.
+type ProblemsError []Problem
+
+// Error returns all problems separated by semicolons.
+func (pe ProblemsError) Error() string {
+	problems := make([]string, len(pe))
+	for i, p := range pe {
+		problems[i] = p.String()
+	}
+
+	return fmt.Sprintf("invalid configuration (%d problems): %s", len(pe), strings.Join(problems, "; "))
+}
+
+// Check parses the configuration from a file like ParseWithDefaults, but returns all problems found instead
+// of the first one. An error is only returned if the configuration can't be decoded at all, i.e. a syntax error.
+// The managed configuration overlay is checked too, repository settings are checked with validate if not nil.
+func Check(path string, validate RepoValidator) ([]Problem, error) {
+	_, problems, err := check(path, validate)
+	return problems, err
+}
+
+func check(path string, validate RepoValidator) (*Config, []Problem, error) {
+	p, err := parse(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := p.cfg.Defaults()
+
+	problems := append(p.problems, cfg.validate(validate)...)
+	for i := range problems {
+		p.locate(&problems[i])
+	}
+	if cfg.Registry.Enabled() {
+		problems = append(problems, cfg.checkOverlay(validate)...)
+	}
+
+	return cfg, problems, nil
+}
+
+// Validate checks the configuration for semantic problems, it should be completed with default values first.
+// Repository settings are checked with validate if not nil. Returns a ProblemsError with all problems found.
+func (c *Config) Validate(validate RepoValidator) error {
+	if problems := c.validate(validate); len(problems) > 0 {
+		return ProblemsError(problems)
+	}
+
+	return nil
+}
+
+func (c *Config) validate(validate RepoValidator) []Problem {
+	var problems []Problem
+	add := func(message string, key ...string) {
+		problems = append(problems, Problem{Key: toml.Key(key).String(), Message: message})
+	}
+
+	for _, name := range []string{"nero", "nekos"} {
+		hs := c.HTTP.Nero
+		if name == "nekos" {
+			hs = c.HTTP.Nekos
+		}
+
+		if hs.Enabled() {
+			if err := validateHost(hs.Host); err != nil {
+				add(err.Error(), "http", name, "host")
+			}
+		}
+		if _, err := hs.SocketFileMode(); err != nil {
+			add(err.Error(), "http", name, "socket_mode")
+		}
+		if hs.TLSEnabled() != (hs.TLSKey != "") {
+			add("tls_cert and tls_key must be specified together", "http", name, "tls_key")
+		}
+		if hs.TLSClientCA != "" && !hs.TLSEnabled() {
+			add("client certificates require tls", "http", name, "tls_client_ca")
+		}
+		if hs.TLSClientAuth != "optional" && hs.TLSClientAuth != "required" {
+			add(fmt.Sprintf("unknown client certificate mode %s, expected optional or required", hs.TLSClientAuth), "http", name, "tls_client_auth")
+		}
+		if hs.H2C && hs.TLSEnabled() {
+			add("h2c is only supported without tls, http/2 is always enabled with tls", "http", name, "h2c")
+		}
+		if hs.BaseURL != "" {
+			if err := validateURL(hs.BaseURL); err != nil {
+				add(err.Error(), "http", name, "base_url")
+			}
+		}
+	}
+	if c.HTTP.Admin.Enabled() {
+		if err := validateHost(c.HTTP.Admin.Host); err != nil {
+			add(err.Error(), "http", "admin", "host")
+		}
+	}
+
+	if c.Resize != nil {
+		for _, size := range c.Resize.Sizes {
+			if size <= 0 || size > maxResizeSize {
+				add(fmt.Sprintf("invalid size %d, expected 1 to %d", size, maxResizeSize), "resize", "sizes")
+				break
+			}
+		}
+		if c.Resize.Quality < 1 || c.Resize.Quality > 100 {
+			add(fmt.Sprintf("invalid quality %d, expected 1 to 100", c.Resize.Quality), "resize", "quality")
+		}
+	}
+	if c.Thumbnails.Quality < 1 || c.Thumbnails.Quality > 100 {
+		add(fmt.Sprintf("invalid quality %d, expected 1 to 100", c.Thumbnails.Quality), "thumbnails", "quality")
+	}
+
+	if c.Webhooks != nil {
+		for _, hookId := range sortedKeys(c.Webhooks.Hooks) {
+			if err := validateURL(c.Webhooks.Hooks[hookId].URL); err != nil {
+				add(err.Error(), "webhooks", "hooks", hookId, "url")
+			}
+		}
+	}
+
+	return append(problems, validateRepos(c.Repos, validate)...)
+}
+
+// checkOverlay checks the repositories of the managed configuration overlay, the problems are located in its file.
+func (c *Config) checkOverlay(validate RepoValidator) []Problem {
+	path := filepath.Clean(c.Registry.Path)
+
+	o, err := ParseOverlay(path)
+	if err != nil {
+		return []Problem{{File: path, Message: err.Error()}}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return []Problem{{File: path, Message: err.Error()}}
+	}
+
+	problems := validateRepos(o.Repos, validate)
+	for _, repoId := range sortedKeys(o.Repos) {
+		if _, ok := c.Repos[repoId]; ok {
+			problems = append(problems, Problem{
+				Key:     toml.Key{"repos", repoId}.String(),
+				Message: "repository is defined in both the configuration and the managed overlay",
+			})
+		}
+	}
+	for i := range problems {
+		problems[i].File = path
+		problems[i].Line = locateKey(data, splitKey(problems[i].Key))
+	}
+
+	return problems
+}
+
+// validateRepos checks repository configuration for semantic problems.
+func validateRepos(repos map[string]*Repo, validate RepoValidator) []Problem {
+	var (
+		problems  []Problem
+		paths     = make(map[string]string)
+		lockPaths = make(map[string]string)
+	)
+	add := func(message string, key ...string) {
+		problems = append(problems, Problem{Key: toml.Key(key).String(), Message: message})
+	}
+
+	for _, repoId := range sortedKeys(repos) {
+		r := repos[repoId]
+		if r.Path == "" {
+			add("missing repository path", "repos", repoId, "path")
+			continue
+		}
+
+		if path, err := filepath.Abs(r.Path); err == nil {
+			if other, ok := paths[path]; ok {
+				add(fmt.Sprintf("path %s is also used by repository %s", r.Path, other), "repos", repoId, "path")
+			}
+			paths[path] = repoId
+		}
+		if lockPath, err := filepath.Abs(r.LockPath); err == nil {
+			if other, ok := lockPaths[lockPath]; ok {
+				add(fmt.Sprintf("lock path %s is also used by repository %s", r.LockPath, other), "repos", repoId, "lock_path")
+			}
+			lockPaths[lockPath] = repoId
+		}
+
+		for _, name := range sortedKeys(r.Thumbnails) {
+			t := r.Thumbnails[name]
+			if !thumbnailNamePattern.MatchString(name) {
+				add("invalid preset name, expected lowercase letters, digits, - and _", "repos", repoId, "thumbnails", name)
+			}
+			if (t.Width == 0 && t.Height == 0) || t.Width < 0 || t.Height < 0 || t.Width > maxResizeSize || t.Height > maxResizeSize {
+				add(fmt.Sprintf("invalid size %dx%d, expected a width or a height from 1 to %d", t.Width, t.Height, maxResizeSize), "repos", repoId, "thumbnails", name)
+			}
+		}
+		if l := r.Limits; l != nil {
+			for _, t := range l.MIMETypes {
+				if mediaType, subtype, ok := strings.Cut(t, "/"); !ok || mediaType == "" || subtype == "" {
+					add(fmt.Sprintf("invalid mime type %s, expected i.e. image/png or image/*", t), "repos", repoId, "limits", "mime_types")
+				}
+			}
+			values := map[string]int{
+				"max_size":   l.MaxSize,
+				"max_width":  l.MaxWidth,
+				"max_height": l.MaxHeight,
+				"min_width":  l.MinWidth,
+				"min_height": l.MinHeight,
+			}
+			for _, key := range sortedKeys(values) {
+				if v := values[key]; v < 0 {
+					add(fmt.Sprintf("invalid limit %d, expected a positive number or 0 for no limit", v), "repos", repoId, "limits", key)
+				}
+			}
+			if l.MaxWidth > 0 && l.MinWidth > l.MaxWidth {
+				add(fmt.Sprintf("minimum width %d is larger than the maximum width %d", l.MinWidth, l.MaxWidth), "repos", repoId, "limits", "min_width")
+			}
+			if l.MaxHeight > 0 && l.MinHeight > l.MaxHeight {
+				add(fmt.Sprintf("minimum height %d is larger than the maximum height %d", l.MinHeight, l.MaxHeight), "repos", repoId, "limits", "min_height")
+			}
+		}
+
+		if validate != nil {
+			prefix := toml.Key{"repos", repoId}.String()
+			for _, p := range validate(r) {
+				p.Key = prefix + "." + p.Key
+				problems = append(problems, p)
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateHost checks whether a host string can be listened on.
+func validateHost(host string) error {
+	if path, ok := strings.CutPrefix(host, "unix:"); ok {
+		if path == "" {
+			return fmt.Errorf("invalid host %s: missing socket path", host)
+		}
+
+		return nil
+	}
+	if name, ok := strings.CutPrefix(host, "systemd:"); ok {
+		if name == "" {
+			return fmt.Errorf("invalid host %s: missing socket name", host)
+		}
+
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return fmt.Errorf("invalid host %s: %w", host, err)
+	}
+	if _, err := net.LookupPort("tcp", port); err != nil {
+		return fmt.Errorf("invalid host %s: %w", host, err)
+	}
+
+	return nil
+}
+
+// validateURL checks whether a string is an absolute HTTP(S) URL.
+func validateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("malformed url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("malformed url %s: expected an absolute http or https url", s)
+	}
+
+	return nil
+}
+
+// locate fills in the file and line of a problem from the files defining its key.
+func (p *parser) locate(problem *Problem) {
+	if problem.File != "" || problem.Key == "" {
+		return
+	}
+
+	file, ok := p.keys[problem.Key]
+	if !ok {
+		return
+	}
+
+	problem.File = file
+	problem.Line = locateKey(p.data[file], splitKey(problem.Key))
+}
+
+// locateKey finds the line defining a key or a table in a TOML document, returns 0 if not found.
+// This is a best-effort line scan, multi-line values may confuse it.
+func locateKey(data []byte, key []string) int {
+	var table []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' { // table header
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				continue
+			}
+
+			table = splitKey(strings.Trim(line[:end], "[] \t"))
+			if slices.Equal(table, key) {
+				return i + 1
+			}
+			continue
+		}
+
+		k, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		full := append(slices.Clone(table), splitKey(k)...)
+		if len(full) <= len(key) && slices.Equal(full, key[:len(full)]) {
+			return i + 1 // the key or a dotted key defining a table containing it
+		}
+	}
+
+	return 0
+}
+
+// splitKey splits a dotted TOML key into its parts, removing quotes.
+func splitKey(s string) []string {
+	var (
+		parts []string
+		sb    strings.Builder
+		quote rune
+	)
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				sb.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		case c != ' ' && c != '\t':
+			sb.WriteRune(c)
+		}
+	}
+
+	return append(parts, strings.TrimSpace(sb.String()))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
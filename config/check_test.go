@@ -0,0 +1,80 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"nero.toml": `include = ["extra.toml"]
+
+[log]
+level = "debug"
+colour = true
+
+[registry]
+path = "${CHECK_DIR}/managed.toml"
+
+[unknown]
+key = "value"
+
+[repos.pat]
+path = "/srv/pat"
+
+[repos.pat.meta]
+name = "pat"
+`,
+		"extra.toml": `[http.nero]
+host = "127.0.0.1:8080"
+hots = "127.0.0.1:8081"
+`,
+		"managed.toml": `[repos.kitsune]
+path = "/srv/pat"
+
+[repos.kitsune.meta]
+name = "kitsune"
+
+[repos.pat]
+path = "/srv/other"
+`,
+	})
+
+	// rejects all metadata
+	validate := func(r *Repo) []Problem {
+		var problems []Problem
+		for _, k := range sortedKeys(r.Meta) {
+			problems = append(problems, Problem{Key: "meta." + k, Message: "unknown metadata key"})
+		}
+
+		return problems
+	}
+
+	t.Setenv("CHECK_DIR", dir)
+	problems, err := Check(filepath.Join(dir, "nero.toml"), validate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		root    = filepath.Join(dir, "nero.toml")
+		extra   = filepath.Join(dir, "extra.toml")
+		managed = filepath.Join(dir, "managed.toml")
+	)
+	want := []Problem{
+		{File: root, Line: 5, Key: "log.colour", Message: "unknown key"},
+		{File: root, Line: 10, Key: "unknown", Message: "unknown key"},
+		{File: extra, Line: 3, Key: "http.nero.hots", Message: "unknown key"},
+		{File: root, Line: 17, Key: "repos.pat.meta.name", Message: "unknown metadata key"},
+		{File: managed, Line: 5, Key: "repos.kitsune.meta.name", Message: "unknown metadata key"},
+		{File: managed, Line: 7, Key: "repos.pat", Message: "repository is defined in both the configuration and the managed overlay"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %v", problems)
+	}
+	for i, p := range problems {
+		if p != want[i] {
+			t.Errorf("problem %d = %s, want %s", i, p, want[i])
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"github.com/BurntSushi/toml"
+	"github.com/zlataovce/nero/internal/errors"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
 )
 
 // Section is a section of the configuration file.
@@ -14,15 +22,42 @@ type Section[T any] interface {
 
 // Config is a struct representation of the TOML configuration file.
 type Config struct {
+	// Include are the glob patterns of additional configuration files merged into this one,
+	// relative to the directory of the including file. A key may only be defined in one of the files.
+	Include []string `toml:"include"`
+	// Log is the "log" configuration section.
+	Log *Log `toml:"log"`
 	// HTTP is the "http" configuration section.
 	HTTP *HTTP `toml:"http"`
+	// Audit is the "audit" configuration section.
+	Audit *Audit `toml:"audit"`
+	// Webhooks is the "webhooks" configuration section.
+	Webhooks *Webhooks `toml:"webhooks"`
+	// Tracing is the "tracing" configuration section.
+	Tracing *Tracing `toml:"tracing"`
+	// Registry is the "registry" configuration section.
+	Registry *Registry `toml:"registry"`
+	// Shutdown is the "shutdown" configuration section.
+	Shutdown *Shutdown `toml:"shutdown"`
+	// Resize is the "resize" configuration section.
+	Resize *Resize `toml:"resize"`
+	// Thumbnails is the "thumbnails" configuration section.
+	Thumbnails *Thumbnails `toml:"thumbnails"`
 	// Repos is the collection of repository configuration, keyed by their ID.
 	Repos map[string]*Repo `toml:"repos"`
 }
 
 // Defaults completes the configuration with default values.
 func (c *Config) Defaults() *Config {
+	c.Log = c.Log.Defaults()
 	c.HTTP = c.HTTP.Defaults()
+	c.Audit = c.Audit.Defaults()
+	c.Webhooks = c.Webhooks.Defaults()
+	c.Tracing = c.Tracing.Defaults()
+	c.Registry = c.Registry.Defaults()
+	c.Shutdown = c.Shutdown.Defaults()
+	c.Resize = c.Resize.Defaults()
+	c.Thumbnails = c.Thumbnails.Defaults()
 	for k, v := range c.Repos {
 		c.Repos[k] = v.Defaults()
 	}
@@ -30,32 +65,147 @@ func (c *Config) Defaults() *Config {
 	return c
 }
 
+// Log is a logging configuration section of the configuration file.
+type Log struct {
+	// Level is the minimum logged level, one of "debug", "info" (default), "warn" or "error".
+	Level string `toml:"level"`
+	// Format is the entry encoding, either "json" (default) or "console".
+	Format string `toml:"format"`
+	// Outputs are the entry outputs, "stdout", "stderr" (default) or file paths.
+	Outputs []string `toml:"outputs"`
+	// Rotation is the log file rotation configuration section, files are not rotated if nil.
+	Rotation *LogRotation `toml:"rotation"`
+}
+
+// Defaults completes the section with default values.
+func (l *Log) Defaults() *Log {
+	if l == nil {
+		l = &Log{}
+	}
+	if l.Level == "" {
+		l.Level = "info"
+	}
+	if l.Format == "" {
+		l.Format = "json"
+	}
+	if len(l.Outputs) == 0 {
+		l.Outputs = []string{"stderr"}
+	}
+
+	l.Rotation = l.Rotation.Defaults()
+
+	return l
+}
+
+// LogRotation is a log file rotation configuration section of the configuration file.
+type LogRotation struct {
+	// MaxSize is the size in megabytes after which a file is rotated, defaults to 100.
+	MaxSize int `toml:"max_size"`
+	// MaxAge is the number of days rotated files are kept for, they are not removed based on age if 0.
+	MaxAge int `toml:"max_age"`
+	// MaxBackups is the number of kept rotated files, all are kept if 0.
+	MaxBackups int `toml:"max_backups"`
+	// Compress is whether rotated files should be gzip-compressed.
+	Compress bool `toml:"compress"`
+}
+
+// Defaults completes the section with default values.
+func (lr *LogRotation) Defaults() *LogRotation {
+	if lr == nil {
+		return nil
+	}
+	if lr.MaxSize <= 0 {
+		lr.MaxSize = 100
+	}
+
+	return lr
+}
+
 // HTTP is an HTTP configuration section of the configuration file.
 type HTTP struct {
 	// Nero is the nero API configuration section.
 	Nero *HTTPServer `toml:"nero"`
 	// Nekos is the nekos API configuration section.
 	Nekos *HTTPServer `toml:"nekos"`
+	// Admin is the admin listener configuration section, exposing operational endpoints like metrics.
+	Admin *Admin `toml:"admin"`
 }
 
 // Defaults completes the section with default values.
 func (h *HTTP) Defaults() *HTTP {
+	if h == nil {
+		h = &HTTP{}
+	}
+
 	h.Nero = h.Nero.Defaults()
 	h.Nekos = h.Nekos.Defaults()
+	h.Admin = h.Admin.Defaults()
 
 	return h
 }
 
 // HTTPServer is a server-dependent HTTP API configuration section of the configuration file.
 type HTTPServer struct {
-	// Host is the host string, used for http.ListenAndServe.
+	// Host is the TCP address to listen on, "unix:" followed by the path of a Unix domain socket
+	// or "systemd:" followed by the name of a socket passed by systemd socket activation.
 	Host string `toml:"host"`
 	// BaseURL is the base URL of the server, guessed if empty.
 	BaseURL string `toml:"base_url"`
+	// TrustedProxies are the addresses or CIDR networks of reverse proxies trusted to report the client address
+	// in the X-Forwarded-For header.
+	TrustedProxies []string `toml:"trusted_proxies"`
+	// EventBuffer is the number of recent repository changes kept for resuming event streams, defaults to 1024.
+	// Only used by the nero API.
+	EventBuffer int `toml:"event_buffer"`
+	// MaxBodySize is the maximum size of request bodies in megabytes, defaults to 64. Only used by the nero API,
+	// uploaded files are base64-encoded, so they can be about 3/4 of the size at most.
+	MaxBodySize int `toml:"max_body_size"`
+	// JWT is the JWT bearer authentication configuration section, only used by the nero API.
+	JWT *JWT `toml:"jwt"`
+	// AdminKey is the key granting access to repository management in the X-Nero-Key header,
+	// only used by the nero API. Repository management is only available to bearer tokens with the admin scope if empty.
+	AdminKey string `toml:"admin_key"`
+	// SocketMode is the octal permission mode of a Unix domain socket (e.g. "0660"), it is left to the umask if empty.
+	SocketMode string `toml:"socket_mode"`
+	// SocketGroup is the name or ID of the group owning a Unix domain socket, it is left unchanged if empty.
+	SocketGroup string `toml:"socket_group"`
+	// TLSCert is the relative or absolute path of the PEM-encoded TLS certificate chain, TLS is disabled if empty.
+	// The certificate and key are reloaded when their files change.
+	TLSCert string `toml:"tls_cert"`
+	// TLSKey is the relative or absolute path of the PEM-encoded TLS private key.
+	TLSKey string `toml:"tls_key"`
+	// TLSClientCA is the relative or absolute path of PEM-encoded CA certificates verifying TLS client certificates,
+	// only used by the nero API. Clients with a verified certificate are authenticated as its common name,
+	// with the scopes in its organizational units (see JWT.ScopePrefix and JWT.AdminScope).
+	TLSClientCA string `toml:"tls_client_ca"`
+	// TLSClientAuth is whether client certificates are "optional" (default) or "required".
+	TLSClientAuth string `toml:"tls_client_auth"`
+	// H2C is whether unencrypted HTTP/2 (h2c) should be accepted, i.e. behind a load balancer. Only used without TLS.
+	H2C bool `toml:"h2c"`
+	// AccessLog is the access log configuration section, entries are written to the main log if nil.
+	AccessLog *AccessLog `toml:"access_log"`
+	// RateLimit is the rate limiting configuration section, rate limiting is disabled if nil.
+	RateLimit *RateLimit `toml:"rate_limit"`
 }
 
 // Defaults completes the section with default values.
 func (hs *HTTPServer) Defaults() *HTTPServer {
+	if hs == nil {
+		hs = &HTTPServer{} // disabled
+	}
+	if hs.EventBuffer <= 0 {
+		hs.EventBuffer = 1024
+	}
+	if hs.MaxBodySize <= 0 {
+		hs.MaxBodySize = 64
+	}
+	if hs.TLSClientAuth == "" {
+		hs.TLSClientAuth = "optional"
+	}
+
+	hs.JWT = hs.JWT.Defaults()
+	hs.RateLimit = hs.RateLimit.Defaults()
+
 	return hs
 }
 
@@ -64,6 +214,368 @@ func (hs *HTTPServer) Enabled() bool {
 	return hs.Host != ""
 }
 
+// SocketFileMode parses the permission mode of a Unix domain socket, returns 0 if not specified.
+func (hs *HTTPServer) SocketFileMode() (fs.FileMode, error) {
+	if hs.SocketMode == "" {
+		return 0, nil
+	}
+
+	mode, err := strconv.ParseUint(hs.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid socket mode %s, expected octal permissions", hs.SocketMode)
+	}
+
+	return fs.FileMode(mode), nil
+}
+
+// TLSEnabled returns whether a TLS certificate was specified.
+func (hs *HTTPServer) TLSEnabled() bool {
+	return hs.TLSCert != ""
+}
+
+// Admin is an admin listener configuration section of the configuration file.
+type Admin struct {
+	// Host is the TCP address to listen on, "unix:" followed by the path of a Unix domain socket
+	// or "systemd:" followed by the name of a socket passed by systemd socket activation.
+	Host string `toml:"host"`
+	// Pprof is whether the net/http/pprof profiling endpoints should be exposed under /debug/pprof.
+	Pprof bool `toml:"pprof"`
+}
+
+// Defaults completes the section with default values.
+func (a *Admin) Defaults() *Admin {
+	if a == nil {
+		a = &Admin{} // disabled
+	}
+
+	return a
+}
+
+// Enabled returns whether a host was specified.
+func (a *Admin) Enabled() bool {
+	return a.Host != ""
+}
+
+// JWT is a JWT bearer authentication configuration section of the configuration file.
+type JWT struct {
+	// Secret is the shared secret for HMAC-signed tokens.
+	Secret string `toml:"secret"`
+	// KeyPath is the path of a PEM-encoded public key for RSA, ECDSA or EdDSA-signed tokens.
+	KeyPath string `toml:"key_path"`
+	// JWKSPath is the path of a JSON Web Key Set file, keys are selected by the token's "kid" header.
+	JWKSPath string `toml:"jwks_path"`
+	// Issuer is the expected "iss" claim, not checked if empty.
+	Issuer string `toml:"issuer"`
+	// Audience is the expected "aud" claim, not checked if empty.
+	Audience string `toml:"audience"`
+	// ScopeClaim is the name of the claim holding the granted scopes, defaults to "scope".
+	ScopeClaim string `toml:"scope_claim"`
+	// ScopePrefix is the prefix of scopes granting access to a repository, defaults to "repo:".
+	// A scope of "<prefix><repository ID>" grants access to that repository, "<prefix>*" grants access to all.
+	ScopePrefix string `toml:"scope_prefix"`
+	// AdminScope is the scope granting access to repository management, defaults to "admin".
+	AdminScope string `toml:"admin_scope"`
+}
+
+// Defaults completes the section with default values.
+func (j *JWT) Defaults() *JWT {
+	if j == nil {
+		return nil
+	}
+	if j.ScopeClaim == "" {
+		j.ScopeClaim = "scope"
+	}
+	if j.AdminScope == "" {
+		j.AdminScope = "admin"
+	}
+	if j.ScopePrefix == "" {
+		j.ScopePrefix = "repo:"
+	}
+
+	return j
+}
+
+// Enabled returns whether a key source was specified.
+func (j *JWT) Enabled() bool {
+	return j != nil && (j.Secret != "" || j.KeyPath != "" || j.JWKSPath != "")
+}
+
+// AccessLog is an access log configuration section of the configuration file.
+type AccessLog struct {
+	// Path is the path of a separate access log file, entries are written to the main log if empty.
+	Path string `toml:"path"`
+	// SampleInitial is the number of entries logged every second before sampling kicks in, sampling is disabled if 0.
+	SampleInitial int `toml:"sample_initial"`
+	// SampleThereafter is the interval of entries logged after SampleInitial is exceeded within a second,
+	// i.e. 100 logs every 100th entry, none are logged if 0.
+	// Server errors are sampled separately from other entries.
+	SampleThereafter int `toml:"sample_thereafter"`
+}
+
+// RateLimit is a rate limiting configuration section of the configuration file.
+type RateLimit struct {
+	// By is the client identification method, either "ip" (default) or "key".
+	// Clients are identified by their verified bearer token, client certificate or repository key with "key",
+	// other clients fall back to "ip".
+	By string `toml:"by"`
+	// Limit is the number of requests a client can make in a burst, 0 means routes are not limited by default.
+	Limit int `toml:"limit"`
+	// Period is the time it takes to replenish the whole limit, defaults to 1 minute.
+	Period time.Duration `toml:"period"`
+	// Routes are the route-specific rules, keyed by the route pattern, i.e. "/api/v2/{category}".
+	// Each route with a specific rule has its own bucket, the rest of the routes share one.
+	Routes map[string]*RateLimitRule `toml:"routes"`
+}
+
+// Defaults completes the section with default values.
+func (rl *RateLimit) Defaults() *RateLimit {
+	if rl == nil {
+		return nil
+	}
+	if rl.By == "" {
+		rl.By = "ip"
+	}
+	if rl.Period == 0 {
+		rl.Period = time.Minute
+	}
+	for k, v := range rl.Routes {
+		rl.Routes[k] = v.Defaults()
+	}
+
+	return rl
+}
+
+// RateLimitRule is a route-specific rate limiting rule.
+type RateLimitRule struct {
+	// Limit is the number of requests a client can make in a burst, 0 means the route is not limited.
+	Limit int `toml:"limit"`
+	// Period is the time it takes to replenish the whole limit, defaults to 1 minute.
+	Period time.Duration `toml:"period"`
+}
+
+// Defaults completes the section with default values.
+func (rlr *RateLimitRule) Defaults() *RateLimitRule {
+	if rlr.Period == 0 {
+		rlr.Period = time.Minute
+	}
+
+	return rlr
+}
+
+// Audit is an audit log configuration section of the configuration file.
+type Audit struct {
+	// Path is the relative or absolute path of the audit log file.
+	Path string `toml:"path"`
+}
+
+// Defaults completes the section with default values.
+func (a *Audit) Defaults() *Audit {
+	return a
+}
+
+// Enabled returns whether an audit log path was specified.
+func (a *Audit) Enabled() bool {
+	return a != nil && a.Path != ""
+}
+
+// Tracing is an OpenTelemetry tracing configuration section of the configuration file.
+type Tracing struct {
+	// Endpoint is the URL of the OTLP/HTTP collector endpoint, i.e. http://localhost:4318.
+	Endpoint string `toml:"endpoint"`
+	// Headers are additional HTTP headers sent to the collector, i.e. for authentication.
+	Headers map[string]string `toml:"headers"`
+	// ServiceName is the reported service name, defaults to "nero".
+	ServiceName string `toml:"service_name"`
+	// SampleRatio is the ratio of sampled traces, between 0 and 1, defaults to 1.
+	SampleRatio float64 `toml:"sample_ratio"`
+}
+
+// Defaults completes the section with default values.
+func (t *Tracing) Defaults() *Tracing {
+	if t == nil {
+		return nil
+	}
+	if t.ServiceName == "" {
+		t.ServiceName = "nero"
+	}
+	if t.SampleRatio <= 0 {
+		t.SampleRatio = 1
+	}
+
+	return t
+}
+
+// Enabled returns whether a collector endpoint was specified.
+func (t *Tracing) Enabled() bool {
+	return t != nil && t.Endpoint != ""
+}
+
+// Registry is a runtime repository management configuration section of the configuration file.
+type Registry struct {
+	// Path is the relative or absolute path of the managed configuration overlay file,
+	// holding the repositories managed at runtime.
+	Path string `toml:"path"`
+	// Root is the relative or absolute path of the directory new repositories are created in, defaults to "repos".
+	Root string `toml:"root"`
+}
+
+// Defaults completes the section with default values.
+func (r *Registry) Defaults() *Registry {
+	if r == nil {
+		return nil
+	}
+	if r.Root == "" {
+		r.Root = "repos"
+	}
+
+	return r
+}
+
+// Enabled returns whether an overlay file path was specified.
+func (r *Registry) Enabled() bool {
+	return r != nil && r.Path != ""
+}
+
+// Shutdown is a graceful shutdown configuration section of the configuration file.
+type Shutdown struct {
+	// ReadinessDelay is how long the readiness check fails before the listeners are closed, so that load balancers
+	// stop routing new requests to the server first. The listeners are closed right away if 0.
+	ReadinessDelay time.Duration `toml:"readiness_delay"`
+	// DrainTimeout is how long in-flight requests are waited for before their connections are closed,
+	// defaults to 30 seconds.
+	DrainTimeout time.Duration `toml:"drain_timeout"`
+}
+
+// Defaults completes the section with default values.
+func (s *Shutdown) Defaults() *Shutdown {
+	if s == nil {
+		s = &Shutdown{}
+	}
+	if s.DrainTimeout <= 0 {
+		s.DrainTimeout = 30 * time.Second
+	}
+
+	return s
+}
+
+// Resize is an on-the-fly image resizing configuration section of the configuration file.
+type Resize struct {
+	// Sizes are the widths and heights in pixels images can be resized to, other sizes are rejected,
+	// so that clients can't fill the cache with arbitrary sizes. Resizing is disabled if empty.
+	Sizes []int `toml:"sizes"`
+	// CacheSize is the maximum size of the resized image cache of a repository in megabytes, defaults to 256.
+	CacheSize int `toml:"cache_size"`
+	// Quality is the quality of resized JPEG images from 1 to 100, defaults to 85.
+	Quality int `toml:"quality"`
+}
+
+// Defaults completes the section with default values.
+func (r *Resize) Defaults() *Resize {
+	if r == nil {
+		return nil
+	}
+	if r.CacheSize <= 0 {
+		r.CacheSize = 256
+	}
+	if r.Quality == 0 {
+		r.Quality = 85
+	}
+
+	return r
+}
+
+// Enabled returns whether any sizes were specified.
+func (r *Resize) Enabled() bool {
+	return r != nil && len(r.Sizes) > 0
+}
+
+// Thumbnails is a thumbnail generation configuration section of the configuration file,
+// the presets are configured per repository.
+type Thumbnails struct {
+	// Workers is the number of thumbnails generated concurrently, defaults to 2.
+	Workers int `toml:"workers"`
+	// QueueSize is the number of media waiting for thumbnails, thumbnails of further new media are skipped
+	// until the queue drains, defaults to 256.
+	QueueSize int `toml:"queue_size"`
+	// Quality is the quality of JPEG thumbnails from 1 to 100, defaults to 85.
+	Quality int `toml:"quality"`
+}
+
+// Defaults completes the section with default values.
+func (t *Thumbnails) Defaults() *Thumbnails {
+	if t == nil {
+		t = &Thumbnails{}
+	}
+	if t.Workers <= 0 {
+		t.Workers = 2
+	}
+	if t.QueueSize <= 0 {
+		t.QueueSize = 256
+	}
+	if t.Quality == 0 {
+		t.Quality = 85
+	}
+
+	return t
+}
+
+// Webhooks is a webhook configuration section of the configuration file.
+type Webhooks struct {
+	// QueuePath is the relative or absolute path of the persistent delivery queue directory.
+	QueuePath string `toml:"queue_path"`
+	// MaxAttempts is the number of attempts after which a delivery is given up, defaults to 10.
+	MaxAttempts int `toml:"max_attempts"`
+	// MinBackoff is the delay before the first retry, doubled with every following attempt, defaults to 10 seconds.
+	MinBackoff time.Duration `toml:"min_backoff"`
+	// MaxBackoff is the maximum delay between retries, defaults to 1 hour.
+	MaxBackoff time.Duration `toml:"max_backoff"`
+	// Timeout is the timeout of a single delivery request, defaults to 10 seconds.
+	Timeout time.Duration `toml:"timeout"`
+	// Hooks are the webhooks, keyed by their name.
+	Hooks map[string]*Webhook `toml:"hooks"`
+}
+
+// Defaults completes the section with default values.
+func (w *Webhooks) Defaults() *Webhooks {
+	if w == nil {
+		return nil
+	}
+	if w.QueuePath == "" {
+		w.QueuePath = "webhooks"
+	}
+	if w.MaxAttempts <= 0 {
+		w.MaxAttempts = 10
+	}
+	if w.MinBackoff == 0 {
+		w.MinBackoff = 10 * time.Second
+	}
+	if w.MaxBackoff == 0 {
+		w.MaxBackoff = time.Hour
+	}
+	if w.Timeout == 0 {
+		w.Timeout = 10 * time.Second
+	}
+
+	return w
+}
+
+// Enabled returns whether any webhooks were specified.
+func (w *Webhooks) Enabled() bool {
+	return w != nil && len(w.Hooks) > 0
+}
+
+// Webhook is a webhook configuration.
+type Webhook struct {
+	// URL is the URL of the receiver.
+	URL string `toml:"url"`
+	// Secret is the secret used for signing payloads, payloads are not signed if empty.
+	Secret string `toml:"secret"`
+	// Repos are the IDs of the repositories the hook is interested in, all repositories if empty.
+	Repos []string `toml:"repos"`
+	// Events are the types of events the hook is interested in ("create", "update" or "delete"), all types if empty.
+	Events []string `toml:"events"`
+}
+
 // Repo is a base repository configuration.
 type Repo struct {
 	// Path is the relative or absolute path of the repository's directory.
@@ -72,6 +584,12 @@ type Repo struct {
 	LockPath string `toml:"lock_path"`
 	// Meta is the repository metadata.
 	Meta map[string]string `toml:"meta"`
+	// Thumbnails are the thumbnail presets of the repository by name, thumbnails are generated for new media.
+	Thumbnails map[string]*Thumbnail `toml:"thumbnails"`
+	// Limits are the restrictions of uploaded media, uploads are not restricted if nil.
+	Limits *Limits `toml:"limits"`
+	// StripMetadata are the kinds of metadata stripped from uploaded images, "exif", "xmp" or "icc".
+	StripMetadata []string `toml:"strip_metadata"`
 }
 
 // Defaults completes the configuration with default values.
@@ -83,22 +601,271 @@ func (r *Repo) Defaults() *Repo {
 	return r
 }
 
-// Parse parses the configuration from a file.
+// Thumbnail is a thumbnail preset configuration section of a repository.
+type Thumbnail struct {
+	// Width is the width in pixels, it is derived from the aspect ratio if 0.
+	Width int `toml:"width"`
+	// Height is the height in pixels, it is derived from the aspect ratio if 0.
+	Height int `toml:"height"`
+	// Fit is the way the image is fitted into the size, "contain", "cover" or "fill", defaults to "contain".
+	Fit string `toml:"fit"`
+}
+
+// Limits is an upload restriction configuration section of a repository, zero values mean no restriction.
+type Limits struct {
+	// MIMETypes are the allowed MIME types, i.e. "image/png", or type wildcards, i.e. "image/*".
+	MIMETypes []string `toml:"mime_types"`
+	// Formats are the allowed media formats, "image", "animated_image" or "video".
+	Formats []string `toml:"formats"`
+	// MaxSize is the maximum file size in megabytes.
+	MaxSize int `toml:"max_size"`
+	// MaxWidth is the maximum width in pixels.
+	MaxWidth int `toml:"max_width"`
+	// MaxHeight is the maximum height in pixels.
+	MaxHeight int `toml:"max_height"`
+	// MinWidth is the minimum width in pixels.
+	MinWidth int `toml:"min_width"`
+	// MinHeight is the minimum height in pixels.
+	MinHeight int `toml:"min_height"`
+}
+
+// Parse parses the configuration from a file and the files it includes.
+// ${NAME} references to environment variables are replaced in all string values,
+// then values are overridden with NERO_-prefixed environment variables (see EnvPrefix).
+// Unknown keys are reported in a ProblemsError.
 func Parse(path string) (*Config, error) {
-	var cfg Config
-	if _, err := toml.DecodeFile(filepath.Clean(path), &cfg); err != nil {
+	p, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.problems) > 0 {
+		return nil, ProblemsError(p.problems)
+	}
+
+	return p.cfg, nil
+}
+
+// parse parses the configuration from a file, collecting unknown keys.
+func parse(path string) (*parser, error) {
+	p := &parser{
+		cfg:   &Config{},
+		files: make(map[string]bool),
+		keys:  make(map[string]string),
+		data:  make(map[string][]byte),
+	}
+	if err := p.parse(filepath.Clean(path)); err != nil {
+		return nil, err
+	}
+
+	if err := interpolate(p.cfg); err != nil {
+		return nil, err
+	}
+	if err := applyEnv(p.cfg, os.Environ()); err != nil {
 		return nil, err
 	}
 
-	return &cfg, nil
+	return p, nil
+}
+
+// parser merges configuration files into a configuration.
+type parser struct {
+	cfg *Config
+	// files are the absolute paths of the parsed files.
+	files map[string]bool
+	// keys maps the decoded keys to the files defining them.
+	keys map[string]string
+	// data are the contents of the parsed files, keyed by their paths.
+	data map[string][]byte
+	// problems are the unknown keys found.
+	problems []Problem
 }
 
-// ParseWithDefaults parses the configuration from a file and completes it with default values (Section.Defaults).
-func ParseWithDefaults(path string) (*Config, error) {
-	cfg, err := Parse(path)
+// parse decodes a configuration file into the configuration and merges the files it includes.
+func (p *parser) parse(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if p.files[path] {
+		return fmt.Errorf("%s is included more than once", path)
+	}
+	p.files[path] = true
+
+	data, err := os.ReadFile(path)
 	if err != nil {
+		return err
+	}
+	p.data[path] = data
+
+	var cfg Config
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode %s", path)
+	}
+
+	undecoded := md.Undecoded()
+	for _, key := range undecoded {
+		if slices.ContainsFunc(undecoded, func(k toml.Key) bool { // only report the outermost unknown table
+			return len(k) < len(key) && slices.Equal(k, key[:len(k)])
+		}) {
+			continue
+		}
+
+		p.problems = append(p.problems, Problem{
+			File:    path,
+			Line:    locateKey(data, key),
+			Key:     key.String(),
+			Message: "unknown key",
+		})
+	}
+
+	includes := cfg.Include
+	if len(p.files) > 1 { // keep the includes of the root file
+		cfg.Include = nil
+	}
+
+	var keys []string
+	for _, key := range md.Keys() {
+		if md.Type(key...) == "Hash" || key.String() == "include" {
+			continue // tables may be split across files
+		}
+
+		keys = append(keys, key.String())
+	}
+	for _, key := range keys {
+		if prev, ok := p.keys[key]; ok && prev != path {
+			return fmt.Errorf("%s is defined in both %s and %s", key, prev, path)
+		}
+	}
+	for _, key := range keys {
+		p.keys[key] = path
+	}
+	merge(reflect.ValueOf(p.cfg).Elem(), reflect.ValueOf(&cfg).Elem())
+
+	for _, pattern := range includes {
+		if pattern, err = expandEnv(pattern, "include"); err != nil {
+			return err
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid include pattern %s", pattern)
+		}
+		for _, match := range matches { // sorted
+			if err := p.parse(match); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// merge merges the set values of src into dst, values of keys defined in both are replaced.
+// Tables are merged recursively, so that they can be split across files.
+func merge(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+
+		merge(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				merge(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+
+		iter := src.MapRange()
+		for iter.Next() {
+			if cur := dst.MapIndex(iter.Key()); cur.IsValid() && cur.Kind() == reflect.Pointer {
+				merge(cur, iter.Value()) // merged in place
+				continue
+			}
+
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// ParseWithDefaults parses the configuration from a file, completes it with default values (Section.Defaults)
+// and validates it like Check. All problems found are reported in a ProblemsError.
+func ParseWithDefaults(path string, validate RepoValidator) (*Config, error) {
+	cfg, problems, err := check(path, validate)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, ProblemsError(problems)
+	}
+
+	return cfg, nil
+}
+
+// Overlay is a managed configuration overlay, holding the repositories managed at runtime.
+type Overlay struct {
+	// Repos is the collection of managed repository configuration, keyed by their ID.
+	Repos map[string]*Repo `toml:"repos"`
+}
+
+// ParseOverlay parses a managed configuration overlay from a file, an empty overlay is returned if it doesn't exist.
+func ParseOverlay(path string) (*Overlay, error) {
+	o := &Overlay{Repos: make(map[string]*Repo)}
+	if _, err := toml.DecodeFile(filepath.Clean(path), o); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return nil, err
 	}
 
-	return cfg.Defaults(), nil
+	for k, v := range o.Repos {
+		o.Repos[k] = v.Defaults()
+	}
+
+	return o, nil
+}
+
+// Write writes the overlay to a file, replacing it atomically.
+func (o *Overlay) Write(path string) (err error) {
+	path = filepath.Clean(path)
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.WriteString("# managed by nero, do not edit while the server is running\n\n"); err == nil {
+		err = toml.NewEncoder(f).Encode(o)
+	}
+	if err0 := f.Close(); err == nil {
+		err = err0
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
 }
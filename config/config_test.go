@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestParseInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"nero.toml": `include = ["conf.d/*.toml"]
+
+[log]
+level = "debug"
+
+[repos.pat]
+path = "/srv/pat"
+`,
+		"conf.d/a.toml": `[log]
+format = "console"
+`,
+		"conf.d/b.toml": `[repos.pat.meta]
+auth_key = "secret"
+
+[repos.kitsune]
+path = "/srv/kitsune"
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "nero.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Log.Level != "debug" || cfg.Log.Format != "console" {
+		t.Errorf("log = %+v", cfg.Log)
+	}
+	if r := cfg.Repos["pat"]; r == nil || r.Path != "/srv/pat" || r.Meta["auth_key"] != "secret" {
+		t.Errorf("repos.pat = %+v", r)
+	}
+	if r := cfg.Repos["kitsune"]; r == nil || r.Path != "/srv/kitsune" {
+		t.Errorf("repos.kitsune = %+v", r)
+	}
+}
+
+func TestParseIncludeConflict(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "key in two files",
+			files: map[string]string{
+				"nero.toml":     "include = [\"conf.d/*.toml\"]\n\n[log]\nlevel = \"debug\"\n",
+				"conf.d/a.toml": "[log]\nlevel = \"warn\"\n",
+			},
+			want: "log.level is defined in both",
+		},
+		{
+			name: "file included twice",
+			files: map[string]string{
+				"nero.toml": "include = [\"a.toml\", \"*.toml\"]\n",
+				"a.toml":    "[log]\nlevel = \"warn\"\n",
+			},
+			want: "is included more than once",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeFiles(t, test.files)
+
+			_, err := Parse(filepath.Join(dir, "nero.toml"))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("err = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
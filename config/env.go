@@ -0,0 +1,313 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of environment variables overriding configuration values.
+const EnvPrefix = "NERO_"
+
+var (
+	interpolationPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// interpolate replaces ${NAME} references to environment variables in all string values of the configuration.
+// References to undefined variables are an error, an empty variable has to be defined explicitly.
+func interpolate(cfg *Config) error {
+	return interpolateValue(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func interpolateValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return interpolateValue(v.Elem(), path)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if name, ok := fieldKey(v.Type().Field(i)); ok {
+				if err := interpolateValue(v.Field(i), joinKey(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i), path); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := joinKey(path, iter.Key().String())
+			if iter.Value().Kind() != reflect.String {
+				if err := interpolateValue(iter.Value(), key); err != nil {
+					return err
+				}
+				continue
+			}
+
+			s, err := expandEnv(iter.Value().String(), key)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(s).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		s, err := expandEnv(v.String(), path)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	}
+
+	return nil
+}
+
+// expandEnv replaces ${NAME} references to environment variables in a value of a key.
+func expandEnv(s, key string) (res string, err error) {
+	res = interpolationPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := interpolationPattern.FindStringSubmatch(ref)[1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%s references undefined environment variable %s", key, name)
+		}
+		return value
+	})
+
+	return res, err
+}
+
+// applyEnv overrides configuration values with NERO_-prefixed environment variables, e.g. NERO_HTTP_NERO_HOST
+// sets the "host" key of the "http.nero" section. Map keys are matched case-insensitively against existing entries,
+// new entries are created with the lowercase key, i.e. NERO_REPOS_PAT_META_AUTH_KEY sets the "auth_key" metadata
+// of the "pat" repository. Slices are comma-separated. Variables not matching any key are ignored.
+func applyEnv(cfg *Config, environ []string) error {
+	slices.Sort(environ) // deterministic order of overlapping overrides
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		parts := strings.Split(strings.ToUpper(strings.TrimPrefix(name, EnvPrefix)), "_")
+		if _, err := setEnv(reflect.ValueOf(cfg).Elem(), parts, value); err != nil {
+			return fmt.Errorf("failed to apply environment variable %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// setEnv sets the value at the key path of an environment variable name split at underscores.
+// Returns whether the path matched a key.
+func setEnv(v reflect.Value, parts []string, value string) (bool, error) {
+	if len(parts) == 0 {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Struct, reflect.Map:
+			if !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+				return false, nil // a whole section can't be set
+			}
+		}
+
+		return true, setValue(v, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return setEnv(v.Elem(), parts, value)
+		}
+
+		// only allocate a missing section if a key in it matched
+		n := reflect.New(v.Type().Elem())
+		ok, err := setEnv(n.Elem(), parts, value)
+		if ok && err == nil {
+			v.Set(n)
+		}
+		return ok, err
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := fieldKey(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+
+			if n, ok := matchKey(parts, name); ok {
+				if ok, err := setEnv(v.Field(i), parts[n:], value); ok || err != nil {
+					return ok, err
+				}
+			}
+		}
+	case reflect.Map:
+		return setMapEnv(v, parts, value)
+	}
+
+	return false, nil
+}
+
+// setMapEnv sets the value at the key path of an environment variable name in a map.
+func setMapEnv(v reflect.Value, parts []string, value string) (bool, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return false, nil
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.String { // leaf map, the rest of the name is the key
+		key := strings.ToLower(strings.Join(parts, "_"))
+		for _, k := range v.MapKeys() {
+			if strings.EqualFold(k.String(), key) {
+				key = k.String()
+				break
+			}
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if err := setValue(elem, value); err != nil {
+			return true, err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		return true, nil
+	}
+
+	// existing entries first, longest keys first, so that keys with underscores are preferred
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return len(b.String()) - len(a.String()) })
+	for _, k := range keys {
+		n, ok := matchKey(parts, k.String())
+		if !ok {
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		elem.Set(v.MapIndex(k))
+		if ok, err := setEnv(elem, parts[n:], value); ok || err != nil {
+			v.SetMapIndex(k, elem)
+			return ok, err
+		}
+	}
+
+	// new entry, the shortest key leaving a matching path
+	for n := 1; n < len(parts); n++ {
+		key := strings.ToLower(strings.Join(parts[:n], "_"))
+		if v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).IsValid() {
+			continue // already tried
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if ok, err := setEnv(elem, parts[n:], value); ok || err != nil {
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// setValue parses a value from a string into a leaf value.
+func setValue(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+
+		sl := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(sl.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Type())
+	}
+
+	return nil
+}
+
+// matchKey checks whether an environment variable name split at underscores starts with a key,
+// returns the number of parts the key spans.
+func matchKey(parts []string, key string) (int, bool) {
+	keyParts := strings.Split(strings.ToUpper(key), "_")
+	if len(keyParts) > len(parts) || !slices.Equal(parts[:len(keyParts)], keyParts) {
+		return 0, false
+	}
+
+	return len(keyParts), true
+}
+
+// fieldKey returns the TOML key of a struct field, returns false if the field is not decoded.
+func fieldKey(f reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+	if !f.IsExported() || name == "" || name == "-" {
+		return "", false
+	}
+
+	return name, true
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("NERO_TEST_KEY", "secret")
+	t.Setenv("NERO_TEST_EMPTY", "")
+
+	cfg := &Config{
+		Log: &Log{Outputs: []string{"${NERO_TEST_KEY}.log", "stderr"}},
+		Repos: map[string]*Repo{
+			"pat": {Path: "/srv/${NERO_TEST_EMPTY}pat", Meta: map[string]string{"auth_key": "${NERO_TEST_KEY}", "name": "$NERO_TEST_KEY"}},
+		},
+	}
+	if err := interpolate(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.Log.Outputs[0]; got != "secret.log" {
+		t.Errorf("log.outputs = %s", got)
+	}
+	if got := cfg.Repos["pat"].Path; got != "/srv/pat" {
+		t.Errorf("repos.pat.path = %s", got)
+	}
+	if got := cfg.Repos["pat"].Meta["auth_key"]; got != "secret" {
+		t.Errorf("repos.pat.meta.auth_key = %s", got)
+	}
+	if got := cfg.Repos["pat"].Meta["name"]; got != "$NERO_TEST_KEY" {
+		t.Errorf("unbraced reference replaced: %s", got)
+	}
+
+	cfg = &Config{Repos: map[string]*Repo{"pat": {Meta: map[string]string{"auth_key": "${NERO_TEST_UNDEFINED}"}}}}
+	if err := interpolate(cfg); err == nil || err.Error() != "repos.pat.meta.auth_key references undefined environment variable NERO_TEST_UNDEFINED" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := &Config{
+		HTTP: &HTTP{Nero: &HTTPServer{Host: "127.0.0.1:8080"}},
+		Repos: map[string]*Repo{
+			"my_repo": {Path: "/srv/my_repo", Meta: map[string]string{"Auth_Key": "old"}},
+		},
+	}
+	err := applyEnv(cfg, []string{
+		"NERO_HTTP_NERO_HOST=0.0.0.0:8080",
+		"NERO_HTTP_NERO_TRUSTED_PROXIES=10.0.0.0/8, 192.168.0.0/16",
+		"NERO_HTTP_NERO_H2C=true",
+		"NERO_SHUTDOWN_DRAIN_TIMEOUT=5s",
+		"NERO_REPOS_MY_REPO_META_AUTH_KEY=new",
+		"NERO_REPOS_PAT_PATH=/srv/pat",
+		"NERO_UNKNOWN_KEY=ignored",
+		"OTHER_HTTP_NERO_HOST=ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hs := cfg.HTTP.Nero; hs.Host != "0.0.0.0:8080" || !hs.H2C || len(hs.TrustedProxies) != 2 || hs.TrustedProxies[1] != "192.168.0.0/16" {
+		t.Errorf("http.nero = %+v", hs)
+	}
+	if cfg.Shutdown == nil || cfg.Shutdown.DrainTimeout != 5*time.Second {
+		t.Errorf("shutdown = %+v", cfg.Shutdown)
+	}
+	if meta := cfg.Repos["my_repo"].Meta; len(meta) != 1 || meta["Auth_Key"] != "new" {
+		t.Errorf("repos.my_repo.meta = %v", meta)
+	}
+	if r, ok := cfg.Repos["pat"]; !ok || r.Path != "/srv/pat" {
+		t.Errorf("repos.pat = %+v", r)
+	}
+	if cfg.Log != nil {
+		t.Error("section allocated without a matching key")
+	}
+
+	if err := applyEnv(cfg, []string{"NERO_HTTP_NERO_H2C=maybe"}); err == nil {
+		t.Error("invalid boolean applied")
+	}
+}
@@ -0,0 +1,221 @@
+package derivative
+
+import (
+	"container/list"
+	"github.com/zlataovce/nero/internal/errors"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tempPrefix is the name prefix of files being written.
+const tempPrefix = ".tmp-"
+
+// Cache is a size-limited on-disk cache, the least recently used entries are evicted first.
+// The recency of entries is kept in the modification time of their files, so that it survives restarts.
+type Cache struct {
+	dir     string
+	maxSize int64
+	logger  *zap.Logger
+
+	entries map[string]*list.Element // of *entry, by name
+	lru     *list.List               // most recently used first
+	size    int64
+	mu      sync.Mutex
+}
+
+type entry struct {
+	name string
+	size int64
+}
+
+// NewCache creates a Cache in a directory, loading the entries already in it.
+func NewCache(dir string, maxSize int64, logger *zap.Logger) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to make cache directory")
+	}
+
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cache directory")
+	}
+
+	type file struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	for _, de := range des {
+		if !de.Type().IsRegular() {
+			continue
+		}
+		if strings.HasPrefix(de.Name(), tempPrefix) { // left behind by a crash
+			_ = os.Remove(filepath.Join(dir, de.Name()))
+			continue
+		}
+
+		fi, err := de.Info()
+		if err != nil {
+			continue // removed in the meantime
+		}
+
+		files = append(files, file{name: fi.Name(), size: fi.Size(), modTime: fi.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b file) int { return b.modTime.Compare(a.modTime) })
+
+	c := &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		logger:  logger,
+		entries: make(map[string]*list.Element, len(files)),
+		lru:     list.New(),
+	}
+	for _, f := range files {
+		c.entries[f.name] = c.lru.PushBack(&entry{name: f.name, size: f.size})
+		c.size += f.size
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evict()
+	return c, nil
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Size returns the total size of the cached entries in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// Get reads a cached entry, returns false if it's not cached.
+func (c *Cache) Get(name string) ([]byte, bool) {
+	f, ok := c.open(name)
+	if !ok {
+		return nil, false
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		c.logger.Warn("failed to read cached file", zap.String("path", f.Name()), zap.Error(err))
+		return nil, false
+	}
+
+	return b, true
+}
+
+// open opens a cached entry and marks it as the most recently used one.
+// The file is opened under the lock, so that it can still be read if it's evicted right after.
+func (c *Cache) open(name string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+
+	path := filepath.Join(c.dir, name)
+	f, err := os.Open(path)
+	if err != nil { // removed behind our back
+		c.remove(e)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return f, true
+}
+
+// Put stores an entry, evicting the least recently used entries if the cache grows over its maximum size.
+// Entries larger than the maximum size are not stored.
+func (c *Cache) Put(name string, b []byte) error {
+	if int64(len(b)) > c.maxSize {
+		return nil
+	}
+
+	path := filepath.Join(c.dir, name)
+	err := writeFile(path, b)
+	if errors.Is(err, os.ErrNotExist) { // the directory was removed, i.e. with the storage of the repository
+		if err = os.MkdirAll(c.dir, 0o755); err == nil {
+			err = writeFile(path, b)
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write cache file")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[name]; ok { // replaced
+		c.size -= e.Value.(*entry).size
+		c.lru.Remove(e)
+	}
+	c.entries[name] = c.lru.PushFront(&entry{name: name, size: int64(len(b))})
+	c.size += int64(len(b))
+
+	c.evict()
+	return nil
+}
+
+// evict removes the least recently used entries until the cache fits its maximum size, the lock must be held.
+func (c *Cache) evict() {
+	for c.size > c.maxSize {
+		e := c.lru.Back()
+		if e == nil {
+			return
+		}
+
+		if err := os.Remove(filepath.Join(c.dir, e.Value.(*entry).name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.logger.Warn("failed to evict cached file", zap.String("dir", c.dir), zap.Error(err))
+		}
+		c.remove(e)
+	}
+}
+
+// remove forgets an entry, the lock must be held.
+func (c *Cache) remove(e *list.Element) {
+	ent := c.lru.Remove(e).(*entry)
+
+	delete(c.entries, ent.name)
+	c.size -= ent.size
+}
+
+// writeFile writes a file atomically, so that it's never served partially written.
+func writeFile(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if err0 := f.Close(); err == nil {
+		err = err0
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+package derivative
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, dir string, maxSize int64) *Cache {
+	t.Helper()
+
+	c, err := NewCache(dir, maxSize, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func cached(c *Cache, names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(c.Dir(), name)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := newTestCache(t, filepath.Join(t.TempDir(), "cache"), 10)
+
+	for _, name := range []string{"a", "b"} {
+		if err := c.Put(name, []byte("1234")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, ok := c.Get("a"); !ok || string(b) != "1234" {
+		t.Fatalf("a = %q, %t", b, ok)
+	}
+
+	// b is the least recently used entry
+	if err := c.Put("c", []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("b"); ok || cached(c, "b") {
+		t.Error("b not evicted")
+	}
+	if !cached(c, "a", "c") || c.Size() != 8 {
+		t.Errorf("size = %d", c.Size())
+	}
+
+	// replaced entries are only counted once
+	if err := c.Put("c", []byte("123456")); err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != 10 || !cached(c, "a", "c") {
+		t.Errorf("size = %d", c.Size())
+	}
+
+	if err := c.Put("large", make([]byte, 11)); err != nil {
+		t.Fatal(err)
+	}
+	if cached(c, "large") || c.Size() != 10 {
+		t.Error("entry larger than the cache stored")
+	}
+}
+
+func TestCacheReload(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"old", "new", tempPrefix + "crashed"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("1234"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		modTime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := newTestCache(t, dir, 4)
+	if !cached(c, "new") || cached(c, "old") || c.Size() != 4 {
+		t.Error("least recently used entry kept")
+	}
+	if cached(c, tempPrefix+"crashed") {
+		t.Error("temporary file kept")
+	}
+}
+
+func TestCacheRemovedDir(t *testing.T) {
+	c := newTestCache(t, filepath.Join(t.TempDir(), "cache"), 10)
+	if err := c.Put("a", []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.RemoveAll(c.Dir()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("removed entry read")
+	}
+
+	if err := c.Put("b", []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := c.Get("b"); !ok || string(b) != "1234" || c.Size() != 4 {
+		t.Errorf("b = %q, %t, size = %d", b, ok, c.Size())
+	}
+}
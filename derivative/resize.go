@@ -0,0 +1,155 @@
+// Package derivative produces images derived from media, i.e. resized versions, and caches them on disk.
+package derivative
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // still images only
+	"image"
+	_ "image/gif" // only the first frame of animations is decoded
+	"image/jpeg"
+	"image/png"
+	"math"
+)
+
+// maxPixels is the maximum number of pixels of a decoded source image, larger images are not resized
+// to bound the memory used by decoding.
+const maxPixels = 64 << 20
+
+// ErrUnsupported is returned when resizing media that can't be decoded, i.e. videos or animated WebP images.
+var ErrUnsupported = errors.New("media can't be resized")
+
+// Fit is the way an image is fitted into the requested size.
+type Fit string
+
+const (
+	// FitContain scales the image to fit within the requested size, keeping the aspect ratio.
+	FitContain Fit = "contain"
+	// FitCover scales the image to cover the requested size, keeping the aspect ratio and cropping the overflow.
+	FitCover Fit = "cover"
+	// FitFill stretches the image to the requested size.
+	FitFill Fit = "fill"
+)
+
+// ParseFit parses a fit mode, returns false if it's unknown.
+func ParseFit(s string) (Fit, bool) {
+	switch f := Fit(s); f {
+	case FitContain, FitCover, FitFill:
+		return f, true
+	}
+
+	return "", false
+}
+
+// Options are the options of a resized image.
+type Options struct {
+	// Width is the requested width in pixels, it is derived from the aspect ratio if 0.
+	Width int
+	// Height is the requested height in pixels, it is derived from the aspect ratio if 0.
+	Height int
+	// Fit is the way the image is fitted into the requested size, defaults to FitContain.
+	Fit Fit
+}
+
+// key returns the part of a cache key identifying the options.
+func (o Options) key() string {
+	return fmt.Sprintf("%dx%d_%s", o.Width, o.Height, o.Fit)
+}
+
+// Image is an encoded image.
+type Image struct {
+	// Data is the encoded image.
+	Data []byte
+	// MIMEType is the MIME type of the image, either image/jpeg or image/png.
+	MIMEType string
+	// Width is the width in pixels.
+	Width int
+	// Height is the height in pixels.
+	Height int
+}
+
+// Resize decodes an image, resizes it and encodes it again, images are never scaled up except with FitFill.
+// JPEG images stay JPEG images, other images are encoded as PNG, only the first frame of animated images is kept.
+// Returns ErrUnsupported if the image can't be decoded.
+func Resize(b []byte, opts Options, quality int) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: image too large (%dx%d)", ErrUnsupported, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	dst := resize(src, opts)
+
+	var (
+		buf bytes.Buffer
+		img = &Image{MIMEType: "image/png", Width: dst.Bounds().Dx(), Height: dst.Bounds().Dy()}
+	)
+	if format == "jpeg" {
+		img.MIMEType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode image")
+	}
+
+	img.Data = buf.Bytes()
+	return img, nil
+}
+
+// extension returns the file extension of an image MIME type produced by Resize.
+func extension(mimeType string) string {
+	if mimeType == "image/jpeg" {
+		return ".jpg"
+	}
+
+	return ".png"
+}
+
+func resize(src image.Image, opts Options) image.Image {
+	var (
+		sb     = src.Bounds()
+		sw, sh = float64(sb.Dx()), float64(sb.Dy())
+		w, h   = float64(opts.Width), float64(opts.Height)
+	)
+	switch { // a missing dimension is derived from the aspect ratio, making all fits equivalent
+	case w == 0 && h == 0:
+		w, h = sw, sh
+	case w == 0:
+		w = math.Max(1, math.Round(sw*h/sh))
+	case h == 0:
+		h = math.Max(1, math.Round(sh*w/sw))
+	}
+
+	var (
+		size = image.Rect(0, 0, int(w), int(h)) // of the destination image
+		crop = sb                               // of the source image
+	)
+	switch opts.Fit {
+	case FitFill:
+	case FitCover:
+		scale := math.Min(math.Max(w/sw, h/sh), 1)
+
+		// the part of the source covering the requested size, centered
+		cw, ch := math.Min(sw, math.Round(w/scale)), math.Min(sh, math.Round(h/scale))
+		crop = image.Rect(0, 0, int(cw), int(ch)).Add(sb.Min).Add(image.Pt(int(sw-cw)/2, int(sh-ch)/2))
+		size = image.Rect(0, 0, max(1, int(math.Round(cw*scale))), max(1, int(math.Round(ch*scale))))
+	default: // FitContain
+		scale := math.Min(math.Min(w/sw, h/sh), 1)
+		size = image.Rect(0, 0, max(1, int(math.Round(sw*scale))), max(1, int(math.Round(sh*scale))))
+	}
+
+	dst := image.NewRGBA(size)
+	draw.CatmullRom.Scale(dst, size, src, crop, draw.Src, nil)
+	return dst
+}
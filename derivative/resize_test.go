@@ -0,0 +1,57 @@
+package derivative
+
+import (
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	src := mediatest.Image(400, 200)
+	sources := []struct {
+		data     []byte
+		mimeType string
+	}{
+		{data: mediatest.JPEG(t, src), mimeType: "image/jpeg"},
+		{data: mediatest.PNG(t, src), mimeType: "image/png"},
+	}
+
+	tests := []struct {
+		name         string
+		opts         Options
+		wantW, wantH int
+	}{
+		{name: "contain", opts: Options{Width: 100, Height: 100, Fit: FitContain}, wantW: 100, wantH: 50},
+		{name: "cover", opts: Options{Width: 100, Height: 100, Fit: FitCover}, wantW: 100, wantH: 100},
+		{name: "fill", opts: Options{Width: 100, Height: 100, Fit: FitFill}, wantW: 100, wantH: 100},
+		{name: "width only", opts: Options{Width: 100, Fit: FitCover}, wantW: 100, wantH: 50},
+		{name: "height only", opts: Options{Height: 50, Fit: FitFill}, wantW: 100, wantH: 50},
+		{name: "original size", opts: Options{}, wantW: 400, wantH: 200},
+		{name: "contain no upscaling", opts: Options{Width: 800, Height: 800, Fit: FitContain}, wantW: 400, wantH: 200},
+		{name: "cover no upscaling", opts: Options{Width: 800, Height: 100, Fit: FitCover}, wantW: 400, wantH: 100},
+		{name: "fill upscaling", opts: Options{Width: 800, Height: 800, Fit: FitFill}, wantW: 800, wantH: 800},
+		{name: "contain minimum size", opts: Options{Width: 1, Height: 1, Fit: FitContain}, wantW: 1, wantH: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, s := range sources {
+				img, err := Resize(s.data, test.opts, 80)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if img.Width != test.wantW || img.Height != test.wantH {
+					t.Errorf("%s: size = %dx%d, want %dx%d", s.mimeType, img.Width, img.Height, test.wantW, test.wantH)
+				}
+				if img.MIMEType != s.mimeType {
+					t.Errorf("mime type = %s, want %s", img.MIMEType, s.mimeType)
+				}
+			}
+		})
+	}
+
+	if _, err := Resize([]byte("not an image"), Options{Width: 100}, 80); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,150 @@
+package derivative
+
+import (
+	"context"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// CacheDir is the name of the directory resized images are cached in, in the storage directory of a repository.
+const CacheDir = ".cache"
+
+// resizedTypes are the MIME types of resized images, see Resize.
+var resizedTypes = []string{"image/jpeg", "image/png"}
+
+// tracer is the tracer of derivative production, a no-op until a global tracer provider is set.
+var tracer = otel.Tracer("github.com/zlataovce/nero/derivative")
+
+// ResizerOptions are the options of a Resizer.
+type ResizerOptions struct {
+	// Sizes are the widths and heights images can be resized to.
+	Sizes []int
+	// CacheSize is the maximum size of the cache of a repository in bytes.
+	CacheSize int64
+	// Quality is the quality of resized JPEG images, from 1 to 100.
+	Quality int
+}
+
+// Resizer resizes the media of repositories on demand, caching the results in the storage directory
+// of each repository.
+type Resizer struct {
+	opts   ResizerOptions
+	logger *zap.Logger
+
+	caches   map[*repo.Repository]*Cache
+	cachesMu sync.Mutex
+	group    singleflight.Group // deduplicates concurrent requests for the same image
+}
+
+// NewResizer creates a Resizer.
+func NewResizer(opts ResizerOptions, logger *zap.Logger) *Resizer {
+	return &Resizer{
+		opts:   opts,
+		logger: logger,
+		caches: make(map[*repo.Repository]*Cache),
+	}
+}
+
+// Allowed checks whether images can be resized to a width or height.
+func (rs *Resizer) Allowed(size int) bool {
+	return slices.Contains(rs.opts.Sizes, size)
+}
+
+// Resize returns a resized version of media, from the cache of the repository if possible.
+// Returns the image and its MIME type, or ErrUnsupported if the media can't be resized.
+func (rs *Resizer) Resize(ctx context.Context, r *repo.Repository, m *media.Media, opts Options) (_ []byte, _ string, err error) {
+	if r.Memory() || !m.Format.Image() {
+		return nil, "", ErrUnsupported
+	}
+	if opts.Fit == "" {
+		opts.Fit = FitContain
+	}
+
+	_, span := tracer.Start(ctx, "derivative.resize", trace.WithAttributes(
+		attribute.String("nero.repo", r.ID()),
+		attribute.String("nero.id", m.ID.String()),
+		attribute.String("nero.size", opts.key()),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	c, err := rs.cache(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := fmt.Sprintf("%s_%s", m.ID, opts.key())
+	for _, mimeType := range resizedTypes {
+		if b, ok := c.Get(key + extension(mimeType)); ok {
+			span.SetAttributes(attribute.Bool("nero.cached", true))
+			return b, mimeType, nil
+		}
+	}
+
+	v, err, _ := rs.group.Do(filepath.Join(c.Dir(), key), func() (interface{}, error) {
+		src, err := os.ReadFile(m.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read media")
+		}
+
+		img, err := Resize(src, opts, rs.opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+
+		name := key + extension(img.MIMEType)
+		if err := c.Put(name, img.Data); err != nil {
+			rs.logger.Warn("failed to cache resized image", zap.String("repo", r.ID()), zap.String("name", name), zap.Error(err))
+		}
+		return img, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	img := v.(*Image)
+	return img.Data, img.MIMEType, nil
+}
+
+// Detach drops the cache of a closed repository, it is opened again if the repository is loaded again.
+func (rs *Resizer) Detach(r *repo.Repository) {
+	rs.cachesMu.Lock()
+	defer rs.cachesMu.Unlock()
+
+	delete(rs.caches, r)
+}
+
+// cache returns the cache of a repository, creating it if needed.
+func (rs *Resizer) cache(r *repo.Repository) (*Cache, error) {
+	rs.cachesMu.Lock()
+	defer rs.cachesMu.Unlock()
+
+	if c, ok := rs.caches[r]; ok {
+		return c, nil
+	}
+
+	c, err := NewCache(filepath.Join(r.Path(), CacheDir), rs.opts.CacheSize, rs.logger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open cache of repository %s", r.ID())
+	}
+
+	rs.caches[r] = c
+	return c, nil
+}
@@ -0,0 +1,52 @@
+package derivative
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"github.com/zlataovce/nero/repo/media"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResizer(t *testing.T) {
+	r := newTestRepo(t, nil)
+
+	// the type of the resized image is the type of the source, whatever its extension
+	m := &media.Media{ID: uuid.New(), Format: media.FormatImage, Path: filepath.Join(r.Path(), "image.jpg")}
+	if err := os.WriteFile(m.Path, mediatest.PNG(t, mediatest.Image(64, 32)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rs := NewResizer(ResizerOptions{Sizes: []int{16}, CacheSize: 1 << 20, Quality: 80}, zap.NewNop())
+	for i := 0; i < 2; i++ { // resized, then cached
+		_, mimeType, err := rs.Resize(context.Background(), r, m, Options{Width: 16})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mimeType != "image/png" {
+			t.Errorf("mime type = %s", mimeType)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(r.Path(), CacheDir, m.ID.String()+"_16x0_contain.png")); err != nil {
+		t.Errorf("resized image not cached: %v", err)
+	}
+
+	rs.Detach(r)
+	if len(rs.caches) != 0 {
+		t.Fatal("cache kept after detaching")
+	}
+
+	// the cache is opened again, i.e. after the storage was removed
+	if err := os.RemoveAll(filepath.Join(r.Path(), CacheDir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rs.Resize(context.Background(), r, m, Options{Width: 16}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.Path(), CacheDir)); err != nil {
+		t.Errorf("cache directory not recreated: %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package derivative
+
+import (
+	"context"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ThumbnailDir is the name of the directory thumbnails are stored in, in the storage directory of a repository.
+const ThumbnailDir = ".thumbnails"
+
+// ThumbnailerOptions are the options of a Thumbnailer.
+type ThumbnailerOptions struct {
+	// Workers is the number of thumbnails generated concurrently.
+	Workers int
+	// QueueSize is the number of media waiting for thumbnails, further media are skipped until the queue drains.
+	QueueSize int
+	// Quality is the quality of JPEG thumbnails, from 1 to 100.
+	Quality int
+}
+
+type thumbnailJob struct {
+	repo  *repo.Repository
+	media *media.Media
+}
+
+// Thumbnailer generates the thumbnails of new media in the background, with a bounded pool of workers.
+// The thumbnails are generated for the presets of the repository (see repo.Repository.Thumbnails)
+// and recorded as derivatives of the media.
+type Thumbnailer struct {
+	opts   ThumbnailerOptions
+	logger *zap.Logger
+
+	jobs   chan thumbnailJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+	mu     sync.RWMutex // guards sending jobs
+	wg     sync.WaitGroup
+
+	repos     map[*repo.Repository]*repoJobs
+	reposMu   sync.Mutex
+	reposCond *sync.Cond // signaled when jobs are done
+}
+
+// repoJobs are the jobs of a repository.
+type repoJobs struct {
+	pending  int // queued or running
+	detached bool
+}
+
+// NewThumbnailer creates a Thumbnailer and starts its workers.
+func NewThumbnailer(opts ThumbnailerOptions, logger *zap.Logger) *Thumbnailer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &Thumbnailer{
+		opts:   opts,
+		logger: logger,
+		jobs:   make(chan thumbnailJob, opts.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		repos:  make(map[*repo.Repository]*repoJobs),
+	}
+	t.reposCond = sync.NewCond(&t.reposMu)
+
+	t.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go t.run()
+	}
+
+	return t
+}
+
+// Listener returns a repository listener queueing the thumbnails of media created in a repository.
+func (t *Thumbnailer) Listener(r *repo.Repository) repo.Listener {
+	return func(e *repo.Event) {
+		if e.Type == repo.EventCreate {
+			t.Enqueue(r, e.Media)
+		}
+	}
+}
+
+// Enqueue queues generating the thumbnails of media, it doesn't block.
+// Returns false if the media were skipped, because the queue is full or the Thumbnailer is closed.
+func (t *Thumbnailer) Enqueue(r *repo.Repository, m *media.Media) bool {
+	if len(r.Thumbnails()) == 0 {
+		return true // nothing to do
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return false
+	}
+
+	if !t.acquire(r) {
+		return false
+	}
+	select {
+	case t.jobs <- thumbnailJob{repo: r, media: m}:
+		return true
+	default:
+		t.release(r)
+		t.logger.Warn("thumbnail queue full, skipping media", zap.String("repo", r.ID()), zap.String("id", m.ID.String()))
+		return false
+	}
+}
+
+// Detach skips the queued thumbnails of a closed repository and waits for the ones being generated,
+// so that its storage can be removed.
+func (t *Thumbnailer) Detach(r *repo.Repository) {
+	t.reposMu.Lock()
+	defer t.reposMu.Unlock()
+
+	jobs, ok := t.repos[r]
+	if !ok {
+		return
+	}
+
+	jobs.detached = true
+	for jobs.pending > 0 {
+		t.reposCond.Wait()
+	}
+	delete(t.repos, r)
+}
+
+// acquire records a job of a repository, returns false if the repository is being detached.
+func (t *Thumbnailer) acquire(r *repo.Repository) bool {
+	t.reposMu.Lock()
+	defer t.reposMu.Unlock()
+
+	jobs, ok := t.repos[r]
+	if !ok {
+		jobs = &repoJobs{}
+		t.repos[r] = jobs
+	} else if jobs.detached {
+		return false
+	}
+
+	jobs.pending++
+	return true
+}
+
+// release records a job of a repository as done.
+func (t *Thumbnailer) release(r *repo.Repository) {
+	t.reposMu.Lock()
+	defer t.reposMu.Unlock()
+
+	jobs := t.repos[r]
+	if jobs.pending--; jobs.pending == 0 {
+		if !jobs.detached {
+			delete(t.repos, r)
+		}
+		t.reposCond.Broadcast()
+	}
+}
+
+// skipped returns whether the jobs of a repository are skipped.
+func (t *Thumbnailer) skipped(r *repo.Repository) bool {
+	t.reposMu.Lock()
+	defer t.reposMu.Unlock()
+
+	return t.repos[r].detached
+}
+
+// Close stops the workers after the thumbnails being generated, queued media are skipped.
+func (t *Thumbnailer) Close() error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		t.cancel()
+		close(t.jobs)
+	}
+	t.mu.Unlock()
+
+	t.wg.Wait()
+	return nil
+}
+
+func (t *Thumbnailer) run() {
+	defer t.wg.Done()
+
+	for job := range t.jobs {
+		t.work(job)
+	}
+}
+
+func (t *Thumbnailer) work(job thumbnailJob) {
+	defer t.release(job.repo)
+
+	if t.ctx.Err() != nil || t.skipped(job.repo) {
+		return // closing or detached, drain the queue
+	}
+
+	err := t.Generate(t.ctx, job.repo, job.media)
+	if err != nil && !errors.Is(err, ErrUnsupported) && !errors.Is(err, repo.ErrNotFound) &&
+		!errors.Is(err, repo.ErrClosed) && !errors.Is(err, context.Canceled) {
+		t.logger.Error(
+			"failed to generate thumbnails",
+			zap.String("repo", job.repo.ID()),
+			zap.String("id", job.media.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// Generate generates the thumbnails of media for all presets of its repository and records them as its derivatives,
+// replacing the previous ones. Thumbnails of presets not configured anymore are deleted.
+// Returns ErrUnsupported if the media can't be resized or repo.ErrNotFound if the media was removed in the meantime.
+func (t *Thumbnailer) Generate(ctx context.Context, r *repo.Repository, m *media.Media) (err error) {
+	if r.Memory() || !m.Format.Image() {
+		return ErrUnsupported
+	}
+
+	presets := r.Thumbnails()
+
+	ctx, span := tracer.Start(ctx, "derivative.thumbnails", trace.WithAttributes(
+		attribute.String("nero.repo", r.ID()),
+		attribute.String("nero.id", m.ID.String()),
+		attribute.Int("nero.presets", len(presets)),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	var previous map[string]*media.Derivative
+	if m0 := r.Get(m.ID); m0 != nil {
+		previous = m0.Derivatives
+	}
+
+	var derivatives map[string]*media.Derivative
+	if len(presets) > 0 {
+		if derivatives, err = t.generate(ctx, r, m, presets, previous); err != nil {
+			return err
+		}
+	}
+	if len(derivatives) == 0 && len(previous) == 0 {
+		return nil
+	}
+
+	if err := r.SetDerivatives(ctx, m.ID, derivatives); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			previous = nil // removed with their files, the new ones are orphaned
+		}
+
+		removeDerivatives(derivatives, previous)
+		return err
+	}
+
+	removeDerivatives(previous, derivatives)
+	return nil
+}
+
+// generate generates the thumbnails of media for presets, the files of the previous derivatives are kept on failure.
+func (t *Thumbnailer) generate(
+	ctx context.Context,
+	r *repo.Repository,
+	m *media.Media,
+	presets map[string]repo.Thumbnail,
+	previous map[string]*media.Derivative,
+) (map[string]*media.Derivative, error) {
+	src, err := os.ReadFile(m.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read media")
+	}
+
+	dir := filepath.Join(r.Path(), ThumbnailDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to make thumbnail directory")
+	}
+
+	derivatives := make(map[string]*media.Derivative, len(presets))
+	for name, preset := range presets {
+		if err := ctx.Err(); err != nil {
+			removeDerivatives(derivatives, previous)
+			return nil, err
+		}
+
+		img, err := Resize(src, Options{Width: preset.Width, Height: preset.Height, Fit: Fit(preset.Fit)}, t.opts.Quality)
+		if err == nil {
+			path := filepath.Join(dir, fmt.Sprintf("%s_%s%s", m.ID, name, extension(img.MIMEType)))
+			if err = writeFile(path, img.Data); err == nil {
+				derivatives[name] = &media.Derivative{Path: path, Width: img.Width, Height: img.Height}
+				continue
+			}
+		}
+
+		removeDerivatives(derivatives, previous)
+		return nil, errors.Wrapf(err, "failed to generate thumbnail %s", name)
+	}
+
+	return derivatives, nil
+}
+
+// removeDerivatives deletes the files of derivatives, except for the ones kept with the same path.
+func removeDerivatives(derivatives, keep map[string]*media.Derivative) {
+	for name, d := range derivatives {
+		if k, ok := keep[name]; ok && k.Path == d.Path {
+			continue
+		}
+
+		_ = os.Remove(d.Path)
+	}
+}
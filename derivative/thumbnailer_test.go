@@ -0,0 +1,130 @@
+package derivative
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestRepo(t *testing.T, thumbnails map[string]repo.Thumbnail) *repo.Repository {
+	t.Helper()
+
+	dir := t.TempDir()
+	r, err := repo.NewFile("pat", filepath.Join(dir, "pat"), filepath.Join(dir, "pat.lock"), nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	r.SetThumbnails(thumbnails)
+
+	return r
+}
+
+func TestThumbnailerDetach(t *testing.T) {
+	th := NewThumbnailer(ThumbnailerOptions{QueueSize: 1}, zap.NewNop()) // no workers yet
+	defer th.Close()
+
+	r := newTestRepo(t, map[string]repo.Thumbnail{"small": {Width: 16}})
+	if !th.Enqueue(r, &media.Media{ID: uuid.New(), Format: media.FormatImage, Path: filepath.Join(r.Path(), "missing")}) {
+		t.Fatal("media skipped")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		th.Detach(r)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("detached with a queued job")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	th.wg.Add(1)
+	go th.run()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("detaching timed out")
+	}
+
+	if len(th.repos) != 0 {
+		t.Error("detached repository kept")
+	}
+}
+
+func TestThumbnailerGenerate(t *testing.T) {
+	th := NewThumbnailer(ThumbnailerOptions{Quality: 80}, zap.NewNop())
+	defer th.Close()
+
+	ctx := context.Background()
+	r := newTestRepo(t, map[string]repo.Thumbnail{"small": {Width: 16}, "big": {Width: 32}})
+
+	m, err := r.Create(ctx, mediatest.PNG(t, mediatest.Image(64, 32)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func() map[string]*media.Derivative {
+		t.Helper()
+
+		if err := th.Generate(ctx, r, m); err != nil {
+			t.Fatal(err)
+		}
+		return r.Get(m.ID).Derivatives
+	}
+
+	derivatives := generate()
+	if len(derivatives) != 2 || derivatives["small"].Width != 16 || derivatives["big"].Width != 32 {
+		t.Fatalf("derivatives = %v", derivatives)
+	}
+	big := derivatives["big"].Path
+
+	// replaced and removed presets
+	r.SetThumbnails(map[string]repo.Thumbnail{"small": {Width: 8}})
+	derivatives = generate()
+	if len(derivatives) != 1 || derivatives["small"].Width != 8 || derivatives["small"].Height != 4 {
+		t.Fatalf("derivatives = %v", derivatives)
+	}
+	if _, err := os.Stat(derivatives["small"].Path); err != nil {
+		t.Errorf("replaced thumbnail removed: %v", err)
+	}
+	if _, err := os.Stat(big); !os.IsNotExist(err) {
+		t.Errorf("thumbnail of removed preset kept: %v", err)
+	}
+	small := derivatives["small"].Path
+
+	r.SetThumbnails(nil)
+	if derivatives = generate(); len(derivatives) != 0 {
+		t.Fatalf("derivatives = %v", derivatives)
+	}
+	if _, err := os.Stat(small); !os.IsNotExist(err) {
+		t.Errorf("thumbnail kept without presets: %v", err)
+	}
+
+	// removed in the meantime
+	r.SetThumbnails(map[string]repo.Thumbnail{"small": {Width: 16}})
+	if err := r.Remove(ctx, m.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := th.Generate(ctx, r, m); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("err = %v, want repo.ErrNotFound", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(r.Path(), ThumbnailDir)); len(entries) != 0 {
+		t.Errorf("orphaned thumbnails kept: %v", entries)
+	}
+
+	if err := th.Generate(ctx, r, &media.Media{ID: uuid.New(), Format: media.FormatVideo}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package mediatest builds media files for tests.
+package mediatest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// Image creates an image of a size filled with a pattern, so that it doesn't encode to a single color.
+func Image(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+
+	return img
+}
+
+// JPEG encodes a JPEG image with segments inserted after the start of image marker.
+func JPEG(t testing.TB, img image.Image, segments ...[]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b := append([]byte(nil), buf.Bytes()[:2]...)
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+	return append(b, buf.Bytes()[2:]...)
+}
+
+// PNGSignature is the signature at the start of PNG images.
+const PNGSignature = "\x89PNG\r\n\x1a\n"
+
+// PNGChunk builds a PNG chunk with a type and the concatenated data.
+func PNGChunk(type_ string, data ...[]byte) []byte {
+	body := append([]byte(type_), bytes.Join(data, nil)...)
+
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(body)-4))
+	b = append(b, body...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(body))
+}
+
+// PNG encodes a PNG image with chunks inserted after the header chunk.
+func PNG(t testing.TB, img image.Image, chunks ...[]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	end := len(PNGSignature) + 12 + 13 // of the header chunk
+	b := append([]byte(nil), buf.Bytes()[:end]...)
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	return append(b, buf.Bytes()[end:]...)
+}
+
+// WebPChunk builds a RIFF chunk of a WebP image with a type and the concatenated data, padded to an even size.
+func WebPChunk(type_ string, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+
+	b := append([]byte(type_), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	b = append(b, body...)
+	if len(body)%2 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// WebP builds a WebP image from chunks.
+func WebP(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+
+	b := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	return append(b, body...)
+}
@@ -0,0 +1,311 @@
+package logging
+
+import (
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options are the options of a logger.
+type Options struct {
+	// Level is the minimum enabled level, one of "debug", "info", "warn", "error", "dpanic", "panic" or "fatal".
+	Level string
+	// Format is the entry encoding, either "json" or "console".
+	Format string
+	// Outputs are the entry outputs, "stdout", "stderr" or file paths.
+	Outputs []string
+	// Rotation is the rotation policy of output files, files are not rotated if nil.
+	Rotation *Rotation
+}
+
+// Rotation is a log file rotation policy.
+type Rotation struct {
+	// MaxSize is the size in megabytes after which a file is rotated.
+	MaxSize int
+	// MaxAge is the number of days rotated files are kept for, they are not removed based on age if 0.
+	MaxAge int
+	// MaxBackups is the number of kept rotated files, all are kept if 0.
+	MaxBackups int
+	// Compress is whether rotated files should be gzip-compressed.
+	Compress bool
+}
+
+// Logger is a logger, which can be reconfigured without replacing it.
+// Loggers derived from it, i.e. with With or Named, follow the reconfiguration.
+type Logger struct {
+	*zap.Logger
+
+	root *root
+}
+
+// New creates a logger.
+func New(opts Options) (*Logger, error) {
+	r := &root{}
+	if err := r.configure(opts); err != nil {
+		return nil, err
+	}
+
+	l := zap.New(
+		&swapCore{root: r},
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+	return &Logger{Logger: l, root: r}, nil
+}
+
+// Reconfigure replaces the level, encoding and outputs of the logger.
+// The previous outputs are closed once the writes in progress are done, entries written to them afterwards
+// are redirected to the new outputs. The logger is left unchanged on error.
+func (l *Logger) Reconfigure(opts Options) error {
+	return l.root.configure(opts)
+}
+
+// Close flushes and closes the outputs of the logger.
+func (l *Logger) Close() error {
+	return l.root.close()
+}
+
+// state is an immutable logger configuration.
+type state struct {
+	gen     uint64
+	core    zapcore.Core
+	ws      zapcore.WriteSyncer // the outputs
+	closers []io.Closer
+
+	closed bool
+	mu     sync.RWMutex // held for reading during writes, so that the outputs aren't closed under them
+}
+
+// errClosed is returned when writing entries to a closed logger.
+var errClosed = errors.New("logger is closed")
+
+// stateSyncer is the output of the core of a state. Entries checked before a reconfiguration may still be
+// written to the core of the previous state, they're redirected to the current outputs once it's closed.
+type stateSyncer struct {
+	root  *root
+	state *state
+}
+
+func (ss *stateSyncer) Write(p []byte) (int, error) {
+	s := ss.state
+	for {
+		s.mu.RLock()
+		if !s.closed {
+			n, err := s.ws.Write(p)
+			s.mu.RUnlock()
+			return n, err
+		}
+		s.mu.RUnlock()
+
+		cur := ss.root.state.Load()
+		if cur == s { // not replaced, the logger was closed
+			return 0, errClosed
+		}
+		s = cur
+	}
+}
+
+func (ss *stateSyncer) Sync() error {
+	s := ss.state
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil // synced before closing
+	}
+	return s.ws.Sync()
+}
+
+// root holds the current configuration of a logger.
+type root struct {
+	state atomic.Pointer[state]
+	mu    sync.Mutex // serializes reconfiguration
+}
+
+func (r *root) configure(opts Options) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.newState(opts)
+	if err != nil {
+		return err
+	}
+
+	old := r.state.Load()
+	if old != nil {
+		s.gen = old.gen + 1
+	}
+
+	r.state.Store(s)
+	if old != nil {
+		return old.close()
+	}
+
+	return nil
+}
+
+func (r *root) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.state.Load().close()
+}
+
+// newState creates a state from options, the same way as zap.NewProduction.
+func (r *root) newState(opts Options) (*state, error) {
+	enc, level, err := newEncoder(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, closers, err := openOutputs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &state{ws: ws, closers: closers}
+	core := zapcore.NewCore(enc, &stateSyncer{root: r, state: s}, level)
+	s.core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+
+	return s, nil
+}
+
+// close closes the outputs of a state, once the writes in progress are done.
+func (s *state) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	_ = s.ws.Sync() // fails for some standard streams, i.e. terminals
+	return closeAll(s.closers)
+}
+
+// swapCore is a zapcore.Core delegating to the current core of a root.
+type swapCore struct {
+	root   *root
+	fields []zapcore.Field
+
+	// cache is the current core with fields applied, valid for a state generation
+	cache atomic.Pointer[cachedCore]
+}
+
+type cachedCore struct {
+	gen  uint64
+	core zapcore.Core
+}
+
+func (sc *swapCore) current() zapcore.Core {
+	s := sc.root.state.Load()
+	if len(sc.fields) == 0 {
+		return s.core
+	}
+
+	if c := sc.cache.Load(); c != nil && c.gen == s.gen {
+		return c.core
+	}
+
+	c := &cachedCore{gen: s.gen, core: s.core.With(sc.fields)}
+	sc.cache.Store(c)
+	return c.core
+}
+
+func (sc *swapCore) Enabled(lvl zapcore.Level) bool {
+	return sc.current().Enabled(lvl)
+}
+
+func (sc *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	fields0 := make([]zapcore.Field, 0, len(sc.fields)+len(fields))
+	fields0 = append(fields0, sc.fields...)
+	fields0 = append(fields0, fields...)
+
+	return &swapCore{root: sc.root, fields: fields0}
+}
+
+func (sc *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return sc.current().Check(ent, ce)
+}
+
+func (sc *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return sc.current().Write(ent, fields)
+}
+
+func (sc *swapCore) Sync() error {
+	return sc.current().Sync()
+}
+
+// newEncoder creates the entry encoder and parses the level of options.
+func newEncoder(opts Options) (zapcore.Encoder, zapcore.Level, error) {
+	level, err := zapcore.ParseLevel(opts.Level)
+	if err != nil {
+		return nil, level, errors.Wrap(err, "failed to parse log level")
+	}
+
+	switch opts.Format {
+	case "json":
+		return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), level, nil
+	case "console":
+		encCfg := zap.NewDevelopmentEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewConsoleEncoder(encCfg), level, nil
+	}
+
+	return nil, level, fmt.Errorf("unknown log format %s", opts.Format)
+}
+
+// openOutputs opens the outputs of options, returns their closers too.
+func openOutputs(opts Options) (zapcore.WriteSyncer, []io.Closer, error) {
+	var (
+		syncers []zapcore.WriteSyncer
+		closers []io.Closer
+	)
+	for _, output := range opts.Outputs {
+		switch output {
+		case "stdout":
+			syncers = append(syncers, zapcore.Lock(os.Stdout))
+		case "stderr":
+			syncers = append(syncers, zapcore.Lock(os.Stderr))
+		default:
+			if opts.Rotation != nil {
+				lj := &lumberjack.Logger{
+					Filename:   output,
+					MaxSize:    opts.Rotation.MaxSize,
+					MaxAge:     opts.Rotation.MaxAge,
+					MaxBackups: opts.Rotation.MaxBackups,
+					Compress:   opts.Rotation.Compress,
+				}
+
+				syncers = append(syncers, zapcore.AddSync(lj))
+				closers = append(closers, lj)
+				continue
+			}
+
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+			if err != nil {
+				_ = closeAll(closers)
+				return nil, nil, errors.Wrapf(err, "failed to open log output %s", output)
+			}
+
+			syncers = append(syncers, zapcore.Lock(f))
+			closers = append(closers, f)
+		}
+	}
+
+	return zapcore.NewMultiWriteSyncer(syncers...), closers, nil
+}
+
+func closeAll(closers []io.Closer) (err error) {
+	for _, c := range closers {
+		err = multierr.Append(err, c.Close())
+	}
+
+	return err
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func countLines(t *testing.T, paths ...string) (n int) {
+	t.Helper()
+
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+
+		n += bytes.Count(b, []byte{'\n'})
+	}
+
+	return n
+}
+
+// TestReconfigureConcurrent reconfigures a logger while it's being logged to from multiple goroutines,
+// no entries may be lost or written to the previous outputs after they were closed.
+func TestReconfigureConcurrent(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		paths   = []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+		rotated = []string{filepath.Join(dir, "c.log"), filepath.Join(dir, "d.log")}
+	)
+	opts := func(path string, rotation *Rotation) Options {
+		return Options{Level: "info", Format: "json", Outputs: []string{path}, Rotation: rotation}
+	}
+
+	l, err := New(opts(paths[0], nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		writers = 8
+		entries = 200
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			logger := l.Named("writer").With() // derived loggers follow the reconfiguration too
+			for j := 0; j < entries; j++ {
+				logger.Info(fmt.Sprintf("entry %d-%d", i, j)) // unique messages, so that none are sampled
+			}
+		}(i)
+	}
+
+	all := append(append([]string(nil), paths...), rotated...)
+	for i := 0; i < 50; i++ {
+		o := opts(paths[i%2], nil)
+		if i%3 == 0 { // lumberjack reopens closed files on write
+			o = opts(rotated[i%2], &Rotation{MaxSize: 100})
+		}
+
+		if err := l.Reconfigure(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countLines(t, all...); n != writers*entries {
+		t.Errorf("got %d entries, want %d", n, writers*entries)
+	}
+}
+
+func TestReconfigureClosesOutputs(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		first = filepath.Join(dir, "first.log")
+		next  = filepath.Join(dir, "next.log")
+	)
+
+	l, err := New(Options{Level: "info", Format: "json", Outputs: []string{first}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before")
+
+	// entries checked before the reconfiguration are written to the new outputs
+	ce := l.Check(zapcore.InfoLevel, "checked")
+	if err := l.Reconfigure(Options{Level: "info", Format: "console", Outputs: []string{next}}); err != nil {
+		t.Fatal(err)
+	}
+	ce.Write()
+	l.Info("after")
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("closed") // reported to the error output, not written
+
+	b0, _ := os.ReadFile(first)
+	b1, _ := os.ReadFile(next)
+	if got := string(b0); !strings.Contains(got, "before") || strings.Contains(got, "checked") || strings.Contains(got, "after") {
+		t.Errorf("got first output %q", got)
+	}
+	if got := string(b1); !strings.Contains(got, "checked") || !strings.Contains(got, "after") || strings.Contains(got, "closed") {
+		t.Errorf("got next output %q", got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []Options{
+		{Level: "loud", Format: "json"},
+		{Level: "info", Format: "xml"},
+		{Level: "info", Format: "json", Outputs: []string{filepath.Join(t.TempDir(), "missing", "dir", "log")}},
+	}
+	for _, opts := range tests {
+		if _, err := New(opts); err == nil {
+			t.Errorf("logger created with options %+v", opts)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware creates a middleware recording the count and latency of requests to an API, by route and status.
+// It should be used on the top-level router, the route is the chi route pattern resolved after routing.
+func (m *Metrics) Middleware(api string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				start = time.Now()
+				ww    = middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			)
+			defer func() {
+				route := "unmatched"
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+					route = rctx.RoutePattern()
+				}
+
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK // nothing was written
+				}
+
+				labels := []string{api, r.Method, route, strconv.Itoa(status)}
+				m.requests.WithLabelValues(labels...).Inc()
+				m.requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
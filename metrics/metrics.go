@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"github.com/zlataovce/nero/repo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"time"
+)
+
+const namespace = "nero"
+
+// Metrics is a collection of Prometheus metrics of the server.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	uploadSize   *prometheus.HistogramVec
+	saveDuration *prometheus.HistogramVec
+	saveErrors   *prometheus.CounterVec
+	calls        *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them in a registry, along with the Go runtime, build info and process collectors.
+// The repository collector is called on every scrape, it should return the currently served repositories.
+func New(reg prometheus.Registerer, repos func() []*repo.Repository) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of handled HTTP requests.",
+		}, []string{"api", "method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"api", "method", "route", "status"}),
+		uploadSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repo",
+			Name:      "upload_size_bytes",
+			Help:      "Size of created media.",
+			Buckets:   prometheus.ExponentialBuckets(16*1024, 4, 8), // 16 KiB to 256 MiB
+		}, []string{"repo"}),
+		saveDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repo",
+			Name:      "index_save_duration_seconds",
+			Help:      "Duration of index file saves.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repo"}),
+		saveErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repo",
+			Name:      "index_save_errors_total",
+			Help:      "Number of failed index file saves.",
+		}, []string{"repo"}),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repo",
+			Name:      "calls_total",
+			Help:      "Number of repository query calls.",
+		}, []string{"repo", "method"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.requests,
+		m.requestDuration,
+		m.uploadSize,
+		m.saveDuration,
+		m.saveErrors,
+		m.calls,
+		&repoCollector{repos: repos},
+		collectors.NewGoCollector(),
+		collectors.NewBuildInfoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveCreate records the size of created media, it implements repo.Observer.
+func (m *Metrics) ObserveCreate(repo string, size int) {
+	m.uploadSize.WithLabelValues(repo).Observe(float64(size))
+}
+
+// ObserveSave records the duration of an index save, it implements repo.Observer.
+func (m *Metrics) ObserveSave(repo string, d time.Duration, err error) {
+	m.saveDuration.WithLabelValues(repo).Observe(d.Seconds())
+	if err != nil {
+		m.saveErrors.WithLabelValues(repo).Inc()
+	}
+}
+
+// ObserveCall records a repository query call, it implements repo.Observer.
+func (m *Metrics) ObserveCall(repo, method string) {
+	m.calls.WithLabelValues(repo, method).Inc()
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	repoItemsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "repo", "items"),
+		"Number of media in a repository.",
+		[]string{"repo", "format"},
+		nil,
+	)
+	repoBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "repo", "stored_bytes"),
+		"Total size of media files in a repository.",
+		[]string{"repo"},
+		nil,
+	)
+)
+
+// formats are the media formats reported by repoCollector.
+var formats = []media.Format{media.FormatUnknown, media.FormatImage, media.FormatAnimatedImage, media.FormatVideo}
+
+// repoCollector collects the state of repositories on scrape.
+type repoCollector struct {
+	repos func() []*repo.Repository
+}
+
+// Describe sends the descriptors of the collected metrics.
+func (rc *repoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- repoItemsDesc
+	ch <- repoBytesDesc
+}
+
+// Collect sends the current state of the repositories.
+func (rc *repoCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, r := range rc.repos() {
+		var (
+			counts = make(map[media.Format]int, len(formats))
+			size   int64
+		)
+		for _, m := range r.Items() {
+			counts[m.Format]++
+			size += m.Size
+		}
+
+		for _, f := range formats {
+			ch <- prometheus.MustNewConstMetric(repoItemsDesc, prometheus.GaugeValue, float64(counts[f]), r.ID(), f.String())
+		}
+		ch <- prometheus.MustNewConstMetric(repoBytesDesc, prometheus.GaugeValue, float64(size), r.ID())
+	}
+}
@@ -0,0 +1,690 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/zlataovce/nero/config"
+	"github.com/zlataovce/nero/derivative"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/zlataovce/nero/repo/media/sanitize"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrDisabled is an error about managing repositories without a managed configuration overlay.
+	ErrDisabled = errors.New("repository management is disabled")
+	// ErrNotManaged is an error about modifying a repository defined in the configuration file.
+	ErrNotManaged = errors.New("repository is not managed")
+	// ErrExists is an error about creating or renaming to an already existing repository ID.
+	ErrExists = errors.New("repository already exists")
+	// ErrNotFound is an error about an unknown repository ID.
+	ErrNotFound = errors.New("unknown repository")
+	// ErrInvalidID is an error about a malformed repository ID.
+	ErrInvalidID = errors.New("invalid repository id, only alphanumeric characters, dashes and underscores are allowed")
+	// ErrInvalidMeta is an error about unknown keys or malformed values in repository metadata.
+	ErrInvalidMeta = errors.New("invalid repository metadata")
+)
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Options are the options of a Registry.
+type Options struct {
+	// OverlayPath is the path of the managed configuration overlay file, runtime management is disabled if empty.
+	OverlayPath string
+	// Root is the directory new repositories are created in.
+	Root string
+	// Attach registers the listeners and observers of a newly loaded repository, may be nil.
+	Attach func(r *repo.Repository)
+	// Detach stops the background work on a closed repository, may be nil.
+	// It is called before the storage of a deleted repository is removed, possibly more than once for a repository.
+	Detach func(r *repo.Repository)
+}
+
+// Registry loads the configured repositories into a set, reconciling the set with the configuration on reloads,
+// and manages repositories at runtime, persisting them to a managed configuration overlay.
+// Repositories defined in the configuration file are never modified at runtime.
+type Registry struct {
+	set    *repo.Set
+	opts   Options
+	logger *zap.Logger
+
+	managed map[string]*config.Repo
+	mu      sync.Mutex // serializes modifications
+}
+
+// New creates an empty registry populating a repository set.
+func New(set *repo.Set, opts Options, logger *zap.Logger) *Registry {
+	return &Registry{
+		set:     set,
+		opts:    opts,
+		logger:  logger,
+		managed: make(map[string]*config.Repo),
+	}
+}
+
+// Enabled returns whether runtime management is enabled.
+func (reg *Registry) Enabled() bool {
+	return reg.opts.OverlayPath != ""
+}
+
+// Managed returns whether a repository is managed at runtime.
+func (reg *Registry) Managed(id string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	_, ok := reg.managed[id]
+	return ok
+}
+
+// Load reconciles the repository set with the repository configuration and the managed configuration overlay.
+// New repositories are loaded, removed ones are closed and the metadata of kept ones is updated,
+// repositories whose paths changed are reloaded. The set is left unchanged on error,
+// except that repositories being reloaded are replaced by instances loaded again from their previous paths.
+func (reg *Registry) Load(static map[string]*config.Repo) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	managed := make(map[string]*config.Repo)
+	if reg.Enabled() {
+		o, err := config.ParseOverlay(reg.opts.OverlayPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to load managed configuration overlay")
+		}
+
+		for repoId := range o.Repos {
+			if _, ok := static[repoId]; ok {
+				return fmt.Errorf("repository %s is defined in both the configuration and the managed overlay", repoId)
+			}
+		}
+
+		managed = o.Repos
+	}
+
+	repos := make(map[string]*config.Repo, len(static)+len(managed))
+	maps.Copy(repos, static)
+	maps.Copy(repos, managed)
+
+	if err := reg.reconcile(repos); err != nil {
+		return err
+	}
+
+	reg.managed = managed
+	return nil
+}
+
+// Create creates a new managed repository in the root directory.
+func (reg *Registry) Create(id string, meta repo.Metadata) (*repo.Repository, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrInvalidID
+	}
+	if err := validateMeta(meta); err != nil {
+		return nil, err
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if !reg.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	if _, ok := reg.set.Get(id); ok {
+		return nil, ErrExists
+	}
+
+	repoConfig := (&config.Repo{Path: filepath.Join(reg.opts.Root, id), Meta: meta}).Defaults()
+	r, err := reg.load(id, repoConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := reg.persist(id, repoConfig, ""); err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+
+	next := append(reg.set.Values(), r)
+	if _, err := reg.set.Swap(next...); err != nil {
+		_ = r.Close() // unreachable, the ID was checked
+		return nil, err
+	}
+	reg.managed[id] = repoConfig
+	reg.attach(r)
+
+	return r, nil
+}
+
+// Patch is a change of a managed repository, nil fields are left unchanged.
+type Patch struct {
+	// ID is the new ID of the repository, its storage is kept in place.
+	ID *string
+	// Meta is the new metadata of the repository.
+	Meta *repo.Metadata
+}
+
+// Rename changes the ID of a managed repository, its storage is kept in place.
+func (reg *Registry) Rename(id, newId string) (*repo.Repository, error) {
+	return reg.Update(id, Patch{ID: &newId})
+}
+
+// Configure replaces the metadata of a managed repository.
+func (reg *Registry) Configure(id string, meta repo.Metadata) (*repo.Repository, error) {
+	return reg.Update(id, Patch{Meta: &meta})
+}
+
+// Update changes the ID and the metadata of a managed repository at once, nothing is changed on error.
+func (reg *Registry) Update(id string, p Patch) (*repo.Repository, error) {
+	if p.ID != nil && !idPattern.MatchString(*p.ID) {
+		return nil, ErrInvalidID
+	}
+	if p.Meta != nil {
+		if err := validateMeta(*p.Meta); err != nil {
+			return nil, err
+		}
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if !reg.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	old, err := reg.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	newId := id
+	if p.ID != nil {
+		newId = *p.ID
+	}
+	if newId != id {
+		if _, ok := reg.set.Get(newId); ok {
+			return nil, ErrExists
+		}
+	}
+
+	prevConfig := reg.managed[id]
+	repoConfig := *prevConfig
+	if p.Meta != nil {
+		repoConfig.Meta = *p.Meta
+	}
+
+	removeId := id
+	if newId == id {
+		removeId = "" // replaced
+	}
+	if err := reg.persist(newId, &repoConfig, removeId); err != nil {
+		return nil, err // the repository keeps serving unchanged
+	}
+	if newId == id {
+		reg.managed[id] = &repoConfig
+		reg.logMetaChange(id, old.Meta(), p.Meta)
+		old.SetMeta(repoConfig.Meta)
+		return old, nil
+	}
+
+	// the storage is loaded again only after the old instance has flushed its index and stopped accepting writes,
+	// so that no two instances own it at once
+	if err := old.Close(); err != nil {
+		reg.logger.Error("failed to close repository", zap.String("repo", id), zap.Error(err))
+	}
+
+	r, err := reg.load(newId, &repoConfig)
+	if err != nil {
+		// bring the repository back under its old ID
+		if err0 := reg.persist(id, prevConfig, newId); err0 != nil {
+			return nil, multierr.Append(err, err0)
+		}
+
+		r0, err0 := reg.load(id, prevConfig)
+		if err0 != nil {
+			return nil, multierr.Append(err, err0)
+		}
+		if err0 := reg.replace(old, r0); err0 != nil {
+			_ = r0.Close()
+			return nil, multierr.Append(err, err0)
+		}
+		reg.attach(r0)
+
+		return nil, err
+	}
+
+	if err := reg.replace(old, r); err != nil {
+		_ = r.Close() // unreachable, the ID was checked
+		return nil, err
+	}
+	delete(reg.managed, id)
+	reg.managed[newId] = &repoConfig
+	reg.attach(r)
+
+	reg.logger.Info("renamed repository", zap.String("repo", id), zap.String("new_repo", newId))
+	reg.logMetaChange(newId, old.Meta(), p.Meta)
+	return r, nil
+}
+
+// logMetaChange logs the keys of repository metadata changed by a patch, if any.
+func (reg *Registry) logMetaChange(id string, old repo.Metadata, new *repo.Metadata) {
+	if new == nil {
+		return
+	}
+
+	reg.logger.Info(
+		"updated repository metadata",
+		zap.String("repo", id),
+		zap.Strings("keys", changedKeys(old, *new)), // values may be secret
+	)
+}
+
+// Delete removes a managed repository, its storage is removed too if purge is true.
+func (reg *Registry) Delete(id string, purge bool) (*repo.Repository, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if !reg.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	r, err := reg.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := reg.persist("", nil, id); err != nil {
+		return nil, err
+	}
+	if err := reg.replace(r, nil); err != nil {
+		return nil, err
+	}
+	delete(reg.managed, id)
+
+	if purge {
+		if err := os.RemoveAll(r.Path()); err != nil {
+			return r, errors.Wrap(err, "failed to remove repository storage")
+		}
+		if err := os.Remove(r.LockPath()); err != nil && !errors.Is(err, os.ErrNotExist) { // may be in the storage
+			return r, errors.Wrap(err, "failed to remove repository lock file")
+		}
+
+		reg.logger.Info("removed repository storage", zap.String("repo", id), zap.String("path", r.Path()))
+	}
+
+	return r, nil
+}
+
+// Close closes all repositories in the set and empties it, flushing their index files.
+func (reg *Registry) Close() (err error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	old, _ := reg.set.Swap()
+	for repoId, r := range old {
+		if err0 := r.Close(); err0 != nil {
+			err = multierr.Append(err, errors.Wrapf(err0, "failed to close repository %s", repoId))
+		}
+		reg.detach(r)
+	}
+
+	return err
+}
+
+// lookup looks up a managed repository, the lock must be held.
+func (reg *Registry) lookup(id string) (*repo.Repository, error) {
+	r, ok := reg.set.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if _, ok := reg.managed[id]; !ok {
+		return nil, ErrNotManaged
+	}
+
+	return r, nil
+}
+
+// persist writes the managed configuration overlay with a repository added and another removed, the lock must be held.
+// Either ID may be empty.
+func (reg *Registry) persist(addId string, add *config.Repo, removeId string) error {
+	o := &config.Overlay{Repos: maps.Clone(reg.managed)}
+	if removeId != "" {
+		delete(o.Repos, removeId)
+	}
+	if addId != "" {
+		o.Repos[addId] = add
+	}
+
+	if err := o.Write(reg.opts.OverlayPath); err != nil {
+		return errors.Wrap(err, "failed to write managed configuration overlay")
+	}
+
+	return nil
+}
+
+// load loads a repository without attaching to it.
+func (reg *Registry) load(id string, repoConfig *config.Repo) (*repo.Repository, error) {
+	r, err := repo.NewFile(id, repoConfig.Path, repoConfig.LockPath, repoConfig.Meta, reg.logger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create repository %s", id)
+	}
+	configure(r, repoConfig)
+
+	return r, nil
+}
+
+// attach attaches to a loaded repository, it should only be done once it's sure to be added to the set,
+// listeners can't be detached.
+func (reg *Registry) attach(r *repo.Repository) {
+	if reg.opts.Attach != nil {
+		reg.opts.Attach(r)
+	}
+	reg.logger.Info("registered repository", zap.String("repo", r.ID()), zap.String("path", r.Path()))
+}
+
+// detach detaches from a closed repository.
+func (reg *Registry) detach(r *repo.Repository) {
+	if reg.opts.Detach != nil {
+		reg.opts.Detach(r)
+	}
+}
+
+// replace swaps a repository in the set for another one and closes it, the replacement may be nil.
+func (reg *Registry) replace(old, r *repo.Repository) error {
+	next := slices.DeleteFunc(reg.set.Values(), func(r0 *repo.Repository) bool { return r0 == old })
+	if r != nil {
+		next = append(next, r)
+	}
+
+	if _, err := reg.set.Swap(next...); err != nil {
+		return err
+	}
+
+	reg.logger.Info("unregistered repository", zap.String("repo", old.ID()), zap.String("path", old.Path()))
+	if err := old.Close(); err != nil {
+		reg.logger.Error("failed to close repository", zap.String("repo", old.ID()), zap.Error(err))
+	}
+	reg.detach(old)
+
+	return nil
+}
+
+// reconcile updates the repository set to match a repository configuration, the lock must be held.
+func (reg *Registry) reconcile(repos map[string]*config.Repo) error {
+	var (
+		current  = reg.set.Map()
+		next     = make([]*repo.Repository, 0, len(repos))
+		added    []*repo.Repository
+		reloaded = make(map[string]*config.Repo)
+		updates  = make(map[*repo.Repository]repo.Metadata)
+		settings = make(map[*repo.Repository]*config.Repo)
+	)
+	for repoId, repoConfig := range repos {
+		r, ok := current[repoId]
+		if ok && samePaths(r, repoConfig) {
+			if !maps.Equal(r.Meta(), repo.Metadata(repoConfig.Meta)) {
+				updates[r] = repoConfig.Meta
+			}
+			settings[r] = repoConfig
+
+			next = append(next, r)
+			continue
+		}
+		if ok {
+			reloaded[repoId] = repoConfig
+			continue
+		}
+
+		r, err := reg.load(repoId, repoConfig)
+		if err != nil {
+			for _, r0 := range added {
+				_ = r0.Close()
+			}
+			return err
+		}
+
+		added = append(added, r)
+		next = append(next, r)
+	}
+
+	// the storage of a reloaded repository is loaded again only after the old instance has flushed its index
+	// and stopped accepting writes, the lock file path may be unchanged
+	var closed []*repo.Repository
+	for repoId, repoConfig := range reloaded {
+		old := current[repoId]
+		if err := old.Close(); err != nil {
+			reg.logger.Error("failed to close repository", zap.String("repo", repoId), zap.Error(err))
+		}
+		reg.detach(old)
+		closed = append(closed, old)
+
+		r, err := reg.load(repoId, repoConfig)
+		if err != nil {
+			for _, r0 := range added {
+				_ = r0.Close()
+			}
+			if err0 := reg.restore(closed); err0 != nil {
+				return multierr.Append(err, err0)
+			}
+			return err
+		}
+
+		added = append(added, r)
+		next = append(next, r)
+	}
+
+	for r, meta := range updates {
+		reg.logger.Info(
+			"updated repository metadata",
+			zap.String("repo", r.ID()),
+			zap.Strings("keys", changedKeys(r.Meta(), meta)), // values may be secret
+		)
+		r.SetMeta(meta)
+	}
+	for r, repoConfig := range settings {
+		if t := Thumbnails(repoConfig); !maps.Equal(r.Thumbnails(), t) {
+			r.SetThumbnails(t)
+			reg.logger.Info("updated repository thumbnail presets", zap.String("repo", r.ID()), zap.Int("presets", len(t)))
+		}
+		if l := Limits(repoConfig); !l.Equal(r.Limits()) {
+			r.SetLimits(l)
+			reg.logger.Info("updated repository upload limits", zap.String("repo", r.ID()))
+		}
+		if k := StripMetadata(repoConfig); !slices.Equal(r.StripMetadata(), k) {
+			r.SetStripMetadata(k)
+			reg.logger.Info("updated repository stripped metadata", zap.String("repo", r.ID()), zap.Any("kinds", k))
+		}
+	}
+	for _, r := range added {
+		reg.attach(r)
+	}
+
+	old, err := reg.set.Swap(next...)
+	if err != nil {
+		return err // unreachable, IDs are configuration keys
+	}
+	for repoId, r := range old {
+		if slices.Contains(next, r) {
+			continue
+		}
+
+		reg.logger.Info("unregistered repository", zap.String("repo", repoId), zap.String("path", r.Path()))
+		if err := r.Close(); err != nil {
+			reg.logger.Error("failed to close repository", zap.String("repo", repoId), zap.Error(err))
+		}
+		reg.detach(r)
+	}
+
+	return nil
+}
+
+// restore loads closed repositories again from their own paths and puts them back in the set, the lock must be held.
+func (reg *Registry) restore(closed []*repo.Repository) (err error) {
+	for _, old := range closed {
+		r, err0 := repo.NewFile(old.ID(), old.Path(), old.LockPath(), old.Meta(), reg.logger)
+		if err0 != nil {
+			err = multierr.Append(err, errors.Wrapf(err0, "failed to restore repository %s", old.ID()))
+			continue
+		}
+		r.SetThumbnails(old.Thumbnails())
+		r.SetLimits(old.Limits())
+		r.SetStripMetadata(old.StripMetadata())
+
+		if err0 := reg.replace(old, r); err0 != nil {
+			_ = r.Close() // unreachable, the ID is unchanged
+			err = multierr.Append(err, err0)
+			continue
+		}
+		reg.attach(r)
+	}
+
+	return err
+}
+
+// configure applies the settings of a repository configuration to a newly loaded repository.
+func configure(r *repo.Repository, repoConfig *config.Repo) {
+	r.SetThumbnails(Thumbnails(repoConfig))
+	r.SetLimits(Limits(repoConfig))
+	r.SetStripMetadata(StripMetadata(repoConfig))
+}
+
+// Thumbnails converts the thumbnail presets of a repository configuration, returns nil if there are none.
+func Thumbnails(repoConfig *config.Repo) map[string]repo.Thumbnail {
+	if len(repoConfig.Thumbnails) == 0 {
+		return nil
+	}
+
+	res := make(map[string]repo.Thumbnail, len(repoConfig.Thumbnails))
+	for name, t := range repoConfig.Thumbnails {
+		res[name] = repo.Thumbnail{Width: t.Width, Height: t.Height, Fit: t.Fit}
+	}
+
+	return res
+}
+
+// Limits converts the upload restrictions of a repository configuration, unknown formats are skipped.
+func Limits(repoConfig *config.Repo) repo.Limits {
+	l := repoConfig.Limits
+	if l == nil {
+		return repo.Limits{}
+	}
+
+	res := repo.Limits{
+		MIMETypes: l.MIMETypes,
+		MaxSize:   int64(l.MaxSize) << 20,
+		MaxWidth:  l.MaxWidth,
+		MaxHeight: l.MaxHeight,
+		MinWidth:  l.MinWidth,
+		MinHeight: l.MinHeight,
+	}
+	for _, name := range l.Formats {
+		if f, ok := media.ParseFormat(name); ok {
+			res.Formats = append(res.Formats, f)
+		}
+	}
+
+	return res
+}
+
+// StripMetadata converts the kinds of metadata stripped by a repository configuration, unknown kinds are skipped.
+func StripMetadata(repoConfig *config.Repo) []sanitize.Kind {
+	var res []sanitize.Kind
+	for _, name := range repoConfig.StripMetadata {
+		if k, ok := sanitize.ParseKind(name); ok && !slices.Contains(res, k) {
+			res = append(res, k)
+		}
+	}
+
+	return res
+}
+
+// ValidateRepo checks the settings of a repository configuration interpreted by nero, see config.RepoValidator.
+func ValidateRepo(repoConfig *config.Repo) []config.Problem {
+	var problems []config.Problem
+	add := func(message string, key ...string) {
+		problems = append(problems, config.Problem{Key: toml.Key(key).String(), Message: message})
+	}
+
+	metaProblems := repo.Metadata(repoConfig.Meta).Validate()
+	for _, k := range sortedKeys(metaProblems) {
+		add(metaProblems[k].Error(), "meta", k)
+	}
+	for _, name := range sortedKeys(repoConfig.Thumbnails) {
+		if fit := repoConfig.Thumbnails[name].Fit; fit != "" {
+			if _, ok := derivative.ParseFit(fit); !ok {
+				add(fmt.Sprintf("unknown fit %s, expected contain, cover or fill", fit), "thumbnails", name, "fit")
+			}
+		}
+	}
+	for _, k := range repoConfig.StripMetadata {
+		if _, ok := sanitize.ParseKind(k); !ok {
+			add(fmt.Sprintf("unknown metadata kind %s, expected exif, xmp or icc", k), "strip_metadata")
+		}
+	}
+	if l := repoConfig.Limits; l != nil {
+		for _, f := range l.Formats {
+			if _, ok := media.ParseFormat(f); !ok {
+				add(fmt.Sprintf("unknown format %s, expected image, animated_image or video", f), "limits", "formats")
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateMeta checks repository metadata, returns an ErrInvalidMeta error describing all problems.
+func validateMeta(meta repo.Metadata) error {
+	metaProblems := meta.Validate()
+	if len(metaProblems) == 0 {
+		return nil
+	}
+
+	res := make([]string, 0, len(metaProblems))
+	for _, k := range sortedKeys(metaProblems) {
+		res = append(res, fmt.Sprintf("%s: %v", k, metaProblems[k]))
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidMeta, strings.Join(res, "; "))
+}
+
+// samePaths checks whether a repository was loaded from the paths of a repository configuration.
+func samePaths(r *repo.Repository, repoConfig *config.Repo) bool {
+	path, err := filepath.Abs(repoConfig.Path)
+	if err != nil {
+		return false
+	}
+
+	return r.Path() == path && r.LockPath() == repoConfig.LockPath
+}
+
+// changedKeys returns the sorted keys added, removed or changed between two versions of metadata.
+func changedKeys(old, new repo.Metadata) []string {
+	var keys []string
+	for k, v := range old {
+		if v0, ok := new[k]; !ok || v0 != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+
+	slices.Sort(keys)
+	return keys
+}
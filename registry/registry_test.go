@@ -0,0 +1,272 @@
+package registry
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/zlataovce/nero/config"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T, managed bool) (*Registry, string) {
+	t.Helper()
+
+	set, err := repo.NewSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	opts := Options{Root: filepath.Join(dir, "repos")}
+	if managed {
+		opts.OverlayPath = filepath.Join(dir, "managed.toml")
+	}
+
+	reg := New(set, opts, zap.NewNop())
+	t.Cleanup(func() { _ = reg.Close() })
+
+	return reg, dir
+}
+
+func isClosed(r *repo.Repository) bool {
+	return errors.Is(r.Remove(context.Background(), uuid.New()), repo.ErrClosed)
+}
+
+func TestRegistryLoadReload(t *testing.T) {
+	reg, dir := newTestRegistry(t, false)
+
+	lockPath := filepath.Join(dir, "pat.lock")
+	if err := reg.Load(map[string]*config.Repo{
+		"pat": {Path: filepath.Join(dir, "a"), LockPath: lockPath},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := reg.set.Get("pat")
+
+	// same lock file, different storage
+	if err := reg.Load(map[string]*config.Repo{
+		"pat": {Path: filepath.Join(dir, "b"), LockPath: lockPath},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := reg.set.Get("pat")
+	if !ok || r == old {
+		t.Fatal("repository not reloaded")
+	}
+	if r.Path() != filepath.Join(dir, "b") {
+		t.Errorf("path = %s", r.Path())
+	}
+	if !isClosed(old) {
+		t.Error("old repository not closed")
+	}
+	if isClosed(r) {
+		t.Error("new repository closed")
+	}
+}
+
+func TestRegistryLoadRestore(t *testing.T) {
+	reg, dir := newTestRegistry(t, false)
+
+	meta := map[string]string{repo.AuthKey: "value"}
+	if err := reg.Load(map[string]*config.Repo{
+		"pat":  {Path: filepath.Join(dir, "a"), LockPath: filepath.Join(dir, "a.lock"), Meta: meta},
+		"kept": {Path: filepath.Join(dir, "kept")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := reg.set.Get("pat")
+
+	badLockPath := filepath.Join(dir, "b.lock")
+	if err := os.WriteFile(badLockPath, []byte("not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Load(map[string]*config.Repo{
+		"pat":  {Path: filepath.Join(dir, "b"), LockPath: badLockPath, Meta: meta},
+		"new":  {Path: filepath.Join(dir, "new"), LockPath: filepath.Join(dir, "new.lock")},
+		"kept": {Path: filepath.Join(dir, "kept"), Thumbnails: map[string]*config.Thumbnail{"small": {Width: 16}}},
+	}); err == nil {
+		t.Fatal("loaded invalid index file")
+	}
+
+	if _, ok := reg.set.Get("new"); ok {
+		t.Error("new repository added")
+	}
+	r, ok := reg.set.Get("pat")
+	if !ok {
+		t.Fatal("repository removed")
+	}
+	if r.Path() != old.Path() || r.LockPath() != old.LockPath() || r.Meta()[repo.AuthKey] != "value" {
+		t.Errorf("repository not restored from its previous paths: %s, %s", r.Path(), r.LockPath())
+	}
+	if isClosed(r) {
+		t.Error("restored repository closed")
+	}
+	if kept, _ := reg.set.Get("kept"); len(kept.Thumbnails()) != 0 {
+		t.Error("settings of a kept repository changed by a failed load")
+	}
+}
+
+func TestRegistryCreate(t *testing.T) {
+	reg, _ := newTestRegistry(t, false)
+	if _, err := reg.Create("pat", nil); !errors.Is(err, ErrDisabled) {
+		t.Errorf("err = %v, want ErrDisabled", err)
+	}
+
+	reg, dir := newTestRegistry(t, true)
+	if err := reg.Load(map[string]*config.Repo{"static": {Path: filepath.Join(dir, "static")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]error{"bad id": ErrInvalidID, "static": ErrExists} {
+		if _, err := reg.Create(id, nil); !errors.Is(err, want) {
+			t.Errorf("%s: err = %v, want %v", id, err, want)
+		}
+	}
+
+	if _, err := reg.Create("pat", repo.Metadata{repo.FormatKey: "gif"}); !errors.Is(err, ErrInvalidMeta) {
+		t.Errorf("err = %v, want ErrInvalidMeta", err)
+	}
+
+	r, err := reg.Create("pat", repo.Metadata{repo.AuthKey: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Path() != filepath.Join(dir, "repos", "pat") {
+		t.Errorf("path = %s", r.Path())
+	}
+	if !reg.Managed("pat") || reg.Managed("static") {
+		t.Error("wrong managed repositories")
+	}
+
+	o, err := config.ParseOverlay(reg.opts.OverlayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := o.Repos["pat"]; !ok || c.Meta[repo.AuthKey] != "value" || len(o.Repos) != 1 {
+		t.Errorf("overlay = %v", o.Repos)
+	}
+}
+
+func TestRegistryUpdate(t *testing.T) {
+	reg, dir := newTestRegistry(t, true)
+	if err := reg.Load(map[string]*config.Repo{"static": {Path: filepath.Join(dir, "static")}}); err != nil {
+		t.Fatal(err)
+	}
+	old, err := reg.Create("pat", repo.Metadata{repo.AuthKey: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := repo.Metadata{repo.AuthKey: "other"}
+	for _, newId := range []string{"static", "bad id"} {
+		if _, err := reg.Update("pat", Patch{ID: &newId, Meta: &meta}); err == nil {
+			t.Errorf("renamed to %s", newId)
+		}
+	}
+	if _, err := reg.Configure("pat", repo.Metadata{"name": "pat"}); !errors.Is(err, ErrInvalidMeta) {
+		t.Errorf("err = %v, want ErrInvalidMeta", err)
+	}
+	if _, err := reg.Configure("static", meta); !errors.Is(err, ErrNotManaged) {
+		t.Errorf("err = %v, want ErrNotManaged", err)
+	}
+	if old.Meta()[repo.AuthKey] != "value" {
+		t.Error("metadata changed by a failed update")
+	}
+
+	newId := "kitsune"
+	r, err := reg.Update("pat", Patch{ID: &newId, Meta: &meta})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID() != newId || r.Path() != old.Path() || r.Meta()[repo.AuthKey] != "other" {
+		t.Errorf("repository = %s, %s, %v", r.ID(), r.Path(), r.Meta())
+	}
+	if _, ok := reg.set.Get("pat"); ok || reg.Managed("pat") {
+		t.Error("old ID kept")
+	}
+	if !isClosed(old) {
+		t.Error("old repository not closed")
+	}
+
+	o, err := config.ParseOverlay(reg.opts.OverlayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := o.Repos[newId]; !ok || c.Meta[repo.AuthKey] != "other" || len(o.Repos) != 1 {
+		t.Errorf("overlay = %v", o.Repos)
+	}
+}
+
+func TestRegistryDelete(t *testing.T) {
+	var detached []*repo.Repository
+
+	reg, _ := newTestRegistry(t, true)
+	reg.opts.Detach = func(r *repo.Repository) {
+		if _, err := os.Stat(r.Path()); err != nil {
+			t.Error("storage removed before detaching")
+		}
+		detached = append(detached, r)
+	}
+
+	kept, err := reg.Create("kept", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	purged, err := reg.Create("purged", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.Delete("kept", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Delete("purged", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Delete("purged", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+
+	if _, err := os.Stat(kept.Path()); err != nil {
+		t.Errorf("storage removed without purging: %v", err)
+	}
+	if _, err := os.Stat(purged.Path()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("storage kept when purging: %v", err)
+	}
+	if len(detached) != 2 || !isClosed(kept) || !isClosed(purged) {
+		t.Error("deleted repositories not closed and detached")
+	}
+	if len(reg.set.Values()) != 0 {
+		t.Error("deleted repositories kept in the set")
+	}
+}
+
+func TestValidateRepo(t *testing.T) {
+	problems := ValidateRepo(&config.Repo{
+		Meta:          map[string]string{repo.FormatKey: "gif", repo.AuthKey: "secret", "name": "pat"},
+		Thumbnails:    map[string]*config.Thumbnail{"small": {Width: 16, Fit: "stretch"}, "big": {Width: 512, Fit: "cover"}},
+		StripMetadata: []string{"exif", "iptc"},
+		Limits:        &config.Limits{Formats: []string{"image", "audio"}},
+	})
+
+	want := []string{
+		"meta.format: unknown format gif, expected image, animated_image or video",
+		"meta.name: unknown metadata key",
+		"thumbnails.small.fit: unknown fit stretch, expected contain, cover or fill",
+		"strip_metadata: unknown metadata kind iptc, expected exif, xmp or icc",
+		"limits.formats: unknown format audio, expected image, animated_image or video",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %v", problems)
+	}
+	for i, p := range problems {
+		if p.String() != want[i] {
+			t.Errorf("problem %d = %s, want %s", i, p, want[i])
+		}
+	}
+}
@@ -1,6 +1,22 @@
 package repo
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+)
+
+var (
+	// ErrClosed is an error about modifying a closed repository.
+	ErrClosed = errors.New("repository is closed")
+	// ErrNotFound is an error about modifying media not in a repository.
+	ErrNotFound = errors.New("media not found")
+	// ErrTooLarge is an error about creating media larger than allowed by the limits of a repository.
+	ErrTooLarge = errors.New("media too large")
+	// ErrTypeNotAllowed is an error about creating media of a type not allowed by the limits of a repository.
+	ErrTypeNotAllowed = errors.New("media type not allowed")
+	// ErrDimensionsNotAllowed is an error about creating media with dimensions outside the limits of a repository.
+	ErrDimensionsNotAllowed = errors.New("media dimensions not allowed")
+)
 
 // ErrDuplicateID is an error about a duplicate media ID in a repository.
 type ErrDuplicateID struct {
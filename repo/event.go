@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/google/uuid"
+	"time"
+)
+
+// EventType is a type of repository change.
+type EventType uint
+
+const (
+	// EventCreate is a media creation.
+	EventCreate EventType = iota
+	// EventUpdate is a media update.
+	EventUpdate
+	// EventDelete is a media deletion.
+	EventDelete
+)
+
+// String returns the string representation of the event type.
+func (et EventType) String() string {
+	switch et {
+	case EventCreate:
+		return "create"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	}
+
+	return "unknown"
+}
+
+// Event is a repository change.
+type Event struct {
+	// ID is the unique event ID.
+	ID uuid.UUID
+	// Type is the type of the change.
+	Type EventType
+	// Repo is the repository ID.
+	Repo string
+	// Time is the time of the change.
+	Time time.Time
+	// Media is the changed media, the state before removal for EventDelete.
+	Media *media.Media
+}
+
+// Listener is a callback receiving repository changes.
+// Listeners are called synchronously after the change has been persisted, so they should not block for long.
+type Listener func(e *Event)
+
+// Listen registers a listener of repository changes.
+func (r *Repository) Listen(l Listener) {
+	r.listenersMu.Lock()
+	defer r.listenersMu.Unlock()
+
+	r.listeners = append(r.listeners, l)
+}
+
+func (r *Repository) emit(type_ EventType, m *media.Media) {
+	r.listenersMu.RLock()
+	defer r.listenersMu.RUnlock()
+
+	if len(r.listeners) == 0 {
+		return
+	}
+
+	e := &Event{
+		ID:    uuid.New(),
+		Type:  type_,
+		Repo:  r.id,
+		Time:  time.Now().UTC(),
+		Media: m,
+	}
+	for _, l := range r.listeners {
+		l(e)
+	}
+}
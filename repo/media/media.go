@@ -2,9 +2,9 @@ package media
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/zlataovce/nero/repo/media/meta"
 	"github.com/google/uuid"
+	"time"
 )
 
 // Format is a media format.
@@ -17,8 +17,75 @@ const (
 	FormatImage
 	// FormatAnimatedImage is an animated image media format, i.e. GIF, APNG, WEBP.
 	FormatAnimatedImage
+	// FormatVideo is a video media format, i.e. MP4, WebM.
+	FormatVideo
 )
 
+// String returns the string representation of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatImage:
+		return "image"
+	case FormatAnimatedImage:
+		return "animated_image"
+	case FormatVideo:
+		return "video"
+	}
+
+	return "unknown"
+}
+
+// Image checks whether the format is a still or an animated image format.
+func (f Format) Image() bool {
+	return f == FormatImage || f == FormatAnimatedImage
+}
+
+// ParseFormat parses a media format from its string representation, returns false if it's unknown.
+func ParseFormat(s string) (Format, bool) {
+	switch s {
+	case "image":
+		return FormatImage, true
+	case "animated_image":
+		return FormatAnimatedImage, true
+	case "video":
+		return FormatVideo, true
+	}
+
+	return FormatUnknown, false
+}
+
+// Dimensions are the dimensions of a piece of media.
+type Dimensions struct {
+	// Width is the width in pixels.
+	Width int `json:"width"`
+	// Height is the height in pixels.
+	Height int `json:"height"`
+	// Frames is the number of frames of animated images, 0 for still images and videos.
+	Frames int `json:"frames,omitempty"`
+	// Duration is the total duration of all frames of animated images or the duration of videos, if known.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Placeholder is a preview of a piece of media, shown while it loads.
+type Placeholder struct {
+	// BlurHash is the BlurHash of the media, see https://blurha.sh.
+	BlurHash string `json:"blurhash"`
+	// AverageColor is the average color in hexadecimal notation, i.e. #ff8000.
+	AverageColor string `json:"average_color"`
+	// DominantColor is the most common color in hexadecimal notation, i.e. #ff8000.
+	DominantColor string `json:"dominant_color"`
+}
+
+// Derivative is an image derived from a piece of media, i.e. a thumbnail.
+type Derivative struct {
+	// Path is the derivative path.
+	Path string `json:"path"`
+	// Width is the width in pixels.
+	Width int `json:"width"`
+	// Height is the height in pixels.
+	Height int `json:"height"`
+}
+
 // Media is a piece of media.
 type Media struct {
 	// ID is the media ID.
@@ -27,17 +94,31 @@ type Media struct {
 	Format Format `json:"format"`
 	// Path is the media path.
 	Path string `json:"path"`
+	// Size is the size of the media file in bytes, not persisted.
+	Size int64 `json:"-"`
 	// Meta is the media metadata, may be nil.
 	Meta meta.Metadata `json:"meta"`
+	// Dimensions are the media dimensions, nil if not known.
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+	// Placeholder is the media placeholder, nil if not known.
+	Placeholder *Placeholder `json:"placeholder,omitempty"`
+	// Derivatives are the images derived from the media by their preset name, may be nil.
+	Derivatives map[string]*Derivative `json:"derivatives,omitempty"`
+	// StrippedMetadata are the kinds of metadata removed from the media file on upload, i.e. "exif", may be nil.
+	StrippedMetadata []string `json:"stripped_metadata,omitempty"`
 }
 
 // UnmarshalJSON reads data from a JSON representation.
 func (m *Media) UnmarshalJSON(bytes []byte) error {
 	var raw struct {
-		ID     uuid.UUID       `json:"id"`
-		Format Format          `json:"format"`
-		Path   string          `json:"path"`
-		Meta   json.RawMessage `json:"meta"`
+		ID               uuid.UUID              `json:"id"`
+		Format           Format                 `json:"format"`
+		Path             string                 `json:"path"`
+		Meta             json.RawMessage        `json:"meta"`
+		Dimensions       *Dimensions            `json:"dimensions"`
+		Placeholder      *Placeholder           `json:"placeholder"`
+		Derivatives      map[string]*Derivative `json:"derivatives"`
+		StrippedMetadata []string               `json:"stripped_metadata"`
 	}
 	if err := json.Unmarshal(bytes, &raw); err != nil {
 		return err
@@ -46,32 +127,16 @@ func (m *Media) UnmarshalJSON(bytes []byte) error {
 	m.ID = raw.ID
 	m.Format = raw.Format
 	m.Path = raw.Path
+	m.Dimensions = raw.Dimensions
+	m.Placeholder = raw.Placeholder
+	m.Derivatives = raw.Derivatives
+	m.StrippedMetadata = raw.StrippedMetadata
 
-	var partialMeta struct {
-		Type meta.Type `json:"type"`
-	}
-	if err := json.Unmarshal(raw.Meta, &partialMeta); err != nil {
+	meta0, err := meta.Unmarshal(raw.Meta)
+	if err != nil {
 		return err
 	}
 
-	switch partialMeta.Type {
-	case meta.TypeGeneric:
-		var meta0 meta.GenericMetadata
-		if err := json.Unmarshal(raw.Meta, &meta0); err != nil {
-			return err
-		}
-
-		m.Meta = &meta0
-	case meta.TypeAnime:
-		var meta0 meta.AnimeMetadata
-		if err := json.Unmarshal(raw.Meta, &meta0); err != nil {
-			return err
-		}
-
-		m.Meta = &meta0
-	default:
-		return fmt.Errorf("unexpected metadata type %d", partialMeta.Type)
-	}
-
+	m.Meta = meta0
 	return nil
 }
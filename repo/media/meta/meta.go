@@ -1,5 +1,10 @@
 package meta
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Type is a type of metadata.
 type Type uint
 
@@ -21,3 +26,36 @@ type Matchable interface {
 	// Matches tries to match against a string query.
 	Matches(query string) bool
 }
+
+// Unmarshal reads metadata from its JSON representation, returns nil for a JSON null or empty input.
+func Unmarshal(b []byte) (Metadata, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+
+	var partialMeta struct {
+		Type Type `json:"type"`
+	}
+	if err := json.Unmarshal(b, &partialMeta); err != nil {
+		return nil, err
+	}
+
+	switch partialMeta.Type {
+	case TypeGeneric:
+		var m GenericMetadata
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+
+		return &m, nil
+	case TypeAnime:
+		var m AnimeMetadata
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+
+		return &m, nil
+	}
+
+	return nil, fmt.Errorf("unexpected metadata type %d", partialMeta.Type)
+}
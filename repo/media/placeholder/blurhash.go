@@ -0,0 +1,118 @@
+package placeholder
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// base83 is the alphabet of BlurHash strings.
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// encode computes the BlurHash of an image (see https://blurha.sh), with 4 components along the longer side
+// and 3 along the shorter one.
+func encode(img *image.RGBA) string {
+	var (
+		w, h   = img.Bounds().Dx(), img.Bounds().Dy()
+		cx, cy = 4, 3
+	)
+	if h > w {
+		cx, cy = 3, 4
+	}
+
+	// the image in linear RGB, decoded once
+	linear := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			linear[y*w+x] = [3]float64{toLinear(img.Pix[i]), toLinear(img.Pix[i+1]), toLinear(img.Pix[i+2])}
+		}
+	}
+
+	factors := make([][3]float64, 0, cx*cy)
+	for j := 0; j < cy; j++ {
+		for i := 0; i < cx; i++ {
+			var f [3]float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					basis := math.Cos(math.Pi*float64(i*x)/float64(w)) * math.Cos(math.Pi*float64(j*y)/float64(h))
+					for c := range f {
+						f[c] += basis * linear[y*w+x][c]
+					}
+				}
+			}
+
+			scale := 2 / float64(w*h)
+			if i == 0 && j == 0 {
+				scale = 1 / float64(w*h)
+			}
+			for c := range f {
+				f[c] *= scale
+			}
+
+			factors = append(factors, f)
+		}
+	}
+
+	var sb strings.Builder
+	writeBase83(&sb, (cx-1)+(cy-1)*9, 1)
+
+	dc, ac := factors[0], factors[1:]
+
+	maxValue := 1.0
+	if len(ac) > 0 {
+		var actualMax float64
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		writeBase83(&sb, quantisedMax, 1)
+	} else {
+		writeBase83(&sb, 0, 1)
+	}
+
+	writeBase83(&sb, int(toSRGB(dc[0]))<<16|int(toSRGB(dc[1]))<<8|int(toSRGB(dc[2])), 4)
+	for _, f := range ac {
+		var v int
+		for _, c := range f {
+			v = v*19 + int(math.Max(0, math.Min(18, math.Floor(signPow(c/maxValue, 0.5)*9+9.5))))
+		}
+
+		writeBase83(&sb, v, 2)
+	}
+
+	return sb.String()
+}
+
+// writeBase83 writes a number as a fixed number of base 83 digits.
+func writeBase83(sb *strings.Builder, v, digits int) {
+	for i := digits - 1; i >= 0; i-- {
+		sb.WriteByte(base83[v/int(math.Pow(83, float64(i)))%83])
+	}
+}
+
+// toLinear converts an sRGB channel value to linear RGB.
+func toLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// toSRGB converts a linear RGB channel value to sRGB.
+func toSRGB(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return uint8(v*12.92*255 + 0.5)
+	}
+
+	return uint8((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
@@ -0,0 +1,90 @@
+package placeholder
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Colors are the names of the colors media can be searched by, see ColorName.
+var Colors = []string{"red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink", "brown", "black", "gray", "white"}
+
+// ValidColor checks whether a color name is one of Colors.
+func ValidColor(name string) bool {
+	return slices.Contains(Colors, name)
+}
+
+// ColorName returns the name of the color closest to a color in hexadecimal notation (i.e. #ff8000),
+// one of Colors, or an empty string if it's malformed.
+func ColorName(hex string) string {
+	if len(hex) != 7 || hex[0] != '#' {
+		return ""
+	}
+
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return ""
+	}
+
+	h, s, l := hsl(float64(v>>16&0xff)/255, float64(v>>8&0xff)/255, float64(v&0xff)/255)
+	switch {
+	case l < 0.12:
+		return "black"
+	case l > 0.92:
+		return "white"
+	case s < 0.15:
+		if l < 0.3 {
+			return "black"
+		} else if l > 0.85 {
+			return "white"
+		}
+		return "gray"
+	case h < 15 || h >= 345:
+		if l < 0.3 {
+			return "brown"
+		}
+		return "red"
+	case h < 45:
+		if l < 0.4 {
+			return "brown"
+		}
+		return "orange"
+	case h < 70:
+		return "yellow"
+	case h < 165:
+		return "green"
+	case h < 195:
+		return "cyan"
+	case h < 255:
+		return "blue"
+	case h < 290:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
+
+// hsl converts an RGB color to its hue in degrees, saturation and lightness.
+func hsl(r, g, b float64) (h, s, l float64) {
+	var (
+		hi = math.Max(r, math.Max(g, b))
+		lo = math.Min(r, math.Min(g, b))
+		d  = hi - lo
+	)
+	l = (hi + lo) / 2
+	if d == 0 {
+		return 0, 0, l
+	}
+
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	return h * 60, s, l
+}
@@ -0,0 +1,136 @@
+// Package placeholder computes the placeholders of images shown while they load, a BlurHash and their colors.
+package placeholder
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // still images only
+	"image"
+	"image/color"
+	_ "image/gif" // only the first frame of animations is decoded
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// maxPixels is the maximum number of pixels of a decoded image, larger images get no placeholder
+	// to bound the memory used by decoding.
+	maxPixels = 64 << 20
+	// sampleSize is the maximum width and height of the downscaled image the placeholder is computed from.
+	sampleSize = 32
+)
+
+// ErrUnsupported is returned when computing the placeholder of media that can't be decoded,
+// i.e. videos or animated WebP images.
+var ErrUnsupported = errors.New("unsupported media type")
+
+// Compute decodes an image and computes its placeholder, only the first frame of animated images is used.
+// Returns ErrUnsupported if the image can't be decoded.
+func Compute(b []byte) (*media.Placeholder, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: image too large (%dx%d)", ErrUnsupported, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	img := sample(src)
+
+	average, dominant := colors(img)
+	return &media.Placeholder{
+		BlurHash:      encode(img),
+		AverageColor:  hex(average),
+		DominantColor: hex(dominant),
+	}, nil
+}
+
+// sample downscales an image to fit within sampleSize, keeping the aspect ratio.
+func sample(src image.Image) *image.RGBA {
+	var (
+		sb   = src.Bounds()
+		w, h = sb.Dx(), sb.Dy()
+	)
+	if w > sampleSize || h > sampleSize {
+		if w > h {
+			w, h = sampleSize, max(1, h*sampleSize/w)
+		} else {
+			w, h = max(1, w*sampleSize/h), sampleSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, sb, draw.Src, nil)
+	return dst
+}
+
+// colors returns the average color and the dominant color of an image, the dominant color is the average
+// of the most common color bucket. Mostly transparent pixels are skipped, unless all of them are.
+func colors(img *image.RGBA) (average, dominant color.RGBA) {
+	type bucket struct {
+		key        int
+		r, g, b, n int
+	}
+
+	var (
+		all     bucket
+		buckets = make(map[int]*bucket)
+	)
+	for _, opaque := range []bool{true, false} {
+		for i := 0; i < len(img.Pix); i += 4 {
+			r, g, b, a := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2]), int(img.Pix[i+3])
+			if opaque && a < 0x80 {
+				continue
+			}
+			if a > 0 && a < 0xff { // unpremultiply
+				r, g, b = r*0xff/a, g*0xff/a, b*0xff/a
+			}
+
+			key := r>>5<<6 | g>>5<<3 | b>>5 // 3 bits per channel
+			bu, ok := buckets[key]
+			if !ok {
+				bu = &bucket{key: key}
+				buckets[key] = bu
+			}
+			for _, bu0 := range []*bucket{bu, &all} {
+				bu0.r += r
+				bu0.g += g
+				bu0.b += b
+				bu0.n++
+			}
+		}
+		if all.n > 0 {
+			break
+		}
+	}
+	if all.n == 0 { // empty image
+		return color.RGBA{A: 0xff}, color.RGBA{A: 0xff}
+	}
+
+	var top *bucket
+	for _, bu := range buckets {
+		// ties are broken by the bucket key, so that the result is stable
+		if top == nil || bu.n > top.n || (bu.n == top.n && bu.key < top.key) {
+			top = bu
+		}
+	}
+
+	return mean(all.r, all.g, all.b, all.n), mean(top.r, top.g, top.b, top.n)
+}
+
+func mean(r, g, b, n int) color.RGBA {
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xff}
+}
+
+// hex returns the hexadecimal representation of a color, i.e. #ff8000.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
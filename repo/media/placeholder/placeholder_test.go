@@ -0,0 +1,94 @@
+package placeholder
+
+import (
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testColors are the pixels of the test images, in rows of the wide image.
+var testColors = []color.RGBA{
+	{255, 0, 0, 255}, {255, 128, 0, 255}, {255, 255, 0, 255}, {0, 255, 0, 255},
+	{0, 255, 255, 255}, {0, 0, 255, 255}, {128, 0, 255, 255}, {255, 0, 255, 255},
+	{0, 0, 0, 255}, {128, 128, 128, 255}, {255, 255, 255, 255}, {139, 69, 19, 255},
+}
+
+func testImage(w, h int, colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < w*h; i++ {
+		img.SetRGBA(i%w, i/w, colors[i%len(colors)])
+	}
+
+	return img
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want string
+	}{
+		{name: "wide", img: testImage(4, 3, testColors...), want: "L_L456%4E1%f~U+~sYyB?2@vvpbW"},
+		{name: "tall", img: testImage(3, 4, testColors...), want: "T~L456^,NE?YxAs7?4rorX}R+QQ_"},
+		{name: "solid", img: testImage(4, 3, color.RGBA{255, 255, 255, 255}), want: "L~TSUA~qfQ~q~q%MfQ%MfQfQfQfQ"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := encode(test.img); got != test.want {
+				t.Errorf("blurhash = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestColorName(t *testing.T) {
+	tests := map[string]string{
+		"#ff0000": "red",
+		"#800000": "brown",
+		"#ff8000": "orange",
+		"#8b4513": "brown",
+		"#ffff00": "yellow",
+		"#00ff00": "green",
+		"#00ffff": "cyan",
+		"#0000ff": "blue",
+		"#8000ff": "purple",
+		"#ff00ff": "pink",
+		"#000000": "black",
+		"#202020": "black",
+		"#808080": "gray",
+		"#f0f0f0": "white",
+		"#ffffff": "white",
+		"#FF0000": "red",
+		"ff0000":  "",
+		"#ff000":  "",
+		"#gg0000": "",
+		"":        "",
+	}
+
+	for hex, want := range tests {
+		if got := ColorName(hex); got != want {
+			t.Errorf("%q: color = %q, want %q", hex, got, want)
+		}
+		if want != "" && !ValidColor(want) {
+			t.Errorf("%s is not a valid color", want)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	p, err := Compute(mediatest.PNG(t, testImage(4, 3, color.RGBA{255, 255, 255, 255})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.BlurHash != "L~TSUA~qfQ~q~q%MfQ%MfQfQfQfQ" || p.AverageColor != "#ffffff" || p.DominantColor != "#ffffff" {
+		t.Errorf("placeholder = %+v", p)
+	}
+
+	if _, err := Compute([]byte("not an image")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
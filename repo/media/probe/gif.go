@@ -0,0 +1,80 @@
+package probe
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"time"
+)
+
+// probeGIF reads the dimensions and frames of a GIF image by walking its blocks, without decompressing them.
+func probeGIF(b []byte) (*media.Dimensions, error) {
+	if !bytes.HasPrefix(b, []byte("GIF87a")) && !bytes.HasPrefix(b, []byte("GIF89a")) {
+		return nil, errors.New("not a gif image")
+	}
+
+	r := &reader{b: b, off: 6}
+	d := media.Dimensions{Width: r.uint16(), Height: r.uint16()}
+
+	flags := r.byte()
+	r.next(2) // background color index, pixel aspect ratio
+	if flags&0x80 != 0 {
+		r.next(3 << ((flags & 0x07) + 1)) // global color table
+	}
+
+	var delay int // of the next frame, in hundredths of a second
+loop:
+	for !r.done() {
+		switch introducer := r.byte(); introducer {
+		case 0x21: // extension
+			label := r.byte()
+			if label == 0xf9 { // graphic control extension
+				if size := r.byte(); size >= 4 {
+					block := r.next(int(size))
+					if block != nil {
+						delay = int(block[1]) | int(block[2])<<8
+					}
+				} else {
+					r.next(int(size))
+				}
+			}
+			skipSubBlocks(r)
+		case 0x2c: // image descriptor
+			r.next(8) // left, top, width, height
+			if flags := r.byte(); flags&0x80 != 0 {
+				r.next(3 << ((flags & 0x07) + 1)) // local color table
+			}
+			r.next(1) // lzw minimum code size
+			skipSubBlocks(r)
+
+			d.Frames++
+			d.Duration += time.Duration(delay) * 10 * time.Millisecond
+			delay = 0
+		case 0x3b: // trailer
+			break loop
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("unknown gif block 0x%02x", introducer)
+			}
+		}
+	}
+
+	// truncated trailing data is common and harmless if at least a frame was read
+	if r.err != nil && d.Frames == 0 {
+		return nil, r.err
+	}
+	return checkDimensions(&d)
+}
+
+// skipSubBlocks skips a sequence of data sub-blocks up to and including the block terminator.
+func skipSubBlocks(r *reader) {
+	for !r.done() {
+		size := r.byte()
+		if size == 0 {
+			return
+		}
+
+		r.next(int(size))
+	}
+}
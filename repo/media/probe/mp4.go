@@ -0,0 +1,151 @@
+package probe
+
+import (
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"math"
+	"time"
+)
+
+// probeMP4 reads the dimensions of an MP4 video from its ISO BMFF boxes, the size of the first video track
+// and the duration of the presentation.
+func probeMP4(b []byte) (*media.Dimensions, error) {
+	moov, err := findBox(b, "moov")
+	if err != nil {
+		return nil, err
+	}
+	if moov == nil {
+		return nil, errors.New("missing moov box")
+	}
+
+	var d media.Dimensions
+	err = walkBoxes(moov, func(type_ string, data []byte) error {
+		switch type_ {
+		case "mvhd": // movie header
+			var timescale, duration uint64
+			if len(data) > 0 && data[0] == 1 { // 64-bit times
+				if len(data) < 32 {
+					return errTruncated
+				}
+				timescale, duration = uint64(binary.BigEndian.Uint32(data[20:])), binary.BigEndian.Uint64(data[24:])
+			} else {
+				if len(data) < 20 {
+					return errTruncated
+				}
+				timescale, duration = uint64(binary.BigEndian.Uint32(data[12:])), uint64(binary.BigEndian.Uint32(data[16:]))
+			}
+
+			if timescale > 0 && duration != 0xffffffff && duration != 0xffffffffffffffff { // unknown duration
+				d.Duration = time.Duration(math.Round(float64(duration) / float64(timescale) * float64(time.Second)))
+			}
+		case "trak":
+			if d.Width > 0 { // the first video track wins
+				return nil
+			}
+
+			w, h, err := probeMP4Track(data)
+			if err != nil {
+				return err
+			}
+			d.Width, d.Height = w, h
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return checkDimensions(&d)
+}
+
+// probeMP4Track reads the display size of a track box, returns zeroes if it's not a video track.
+func probeMP4Track(trak []byte) (w, h int, err error) {
+	mdia, err := findBox(trak, "mdia")
+	if err != nil || mdia == nil {
+		return 0, 0, err
+	}
+	hdlr, err := findBox(mdia, "hdlr")
+	if err != nil || len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+		return 0, 0, err
+	}
+
+	tkhd, err := findBox(trak, "tkhd")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	off := 24 // of the fields following the times and the duration
+	if len(tkhd) > 0 && tkhd[0] == 1 {
+		off = 36
+	}
+	if len(tkhd) < off+60 {
+		return 0, 0, errTruncated
+	}
+
+	// 16.16 fixed-point numbers after the layer, alternate group, volume and transformation matrix
+	w, h = int(binary.BigEndian.Uint32(tkhd[off+52:])>>16), int(binary.BigEndian.Uint32(tkhd[off+56:])>>16)
+
+	// the size is before the transformation, swap it for videos rotated by 90 degrees
+	if a, b := int32(binary.BigEndian.Uint32(tkhd[off+16:])), int32(binary.BigEndian.Uint32(tkhd[off+20:])); a == 0 && (b == 0x10000 || b == -0x10000) {
+		w, h = h, w
+	}
+
+	return w, h, nil
+}
+
+// errFound stops walking boxes once the searched one is found.
+var errFound = errors.New("box found")
+
+// findBox returns the contents of the first box of a type, nil if there is none.
+func findBox(b []byte, type_ string) (res []byte, err error) {
+	err = walkBoxes(b, func(type0 string, data []byte) error {
+		if type0 == type_ {
+			res = data
+			return errFound
+		}
+
+		return nil
+	})
+	if errors.Is(err, errFound) {
+		err = nil
+	}
+
+	return res, err
+}
+
+// walkBoxes calls fn with the type and contents of each box, it doesn't descend into nested boxes.
+func walkBoxes(b []byte, fn func(type_ string, data []byte) error) error {
+	for off := 0; off < len(b); {
+		if len(b)-off < 8 {
+			return errTruncated
+		}
+
+		var (
+			size   = uint64(binary.BigEndian.Uint32(b[off:]))
+			type_  = string(b[off+4 : off+8])
+			header = uint64(8)
+		)
+		switch size {
+		case 0: // extends to the end
+			size = uint64(len(b) - off)
+		case 1: // 64-bit size
+			if len(b)-off < 16 {
+				return errTruncated
+			}
+
+			size, header = binary.BigEndian.Uint64(b[off+8:]), 16
+		}
+		if size < header || size > uint64(len(b)-off) {
+			return errTruncated
+		}
+
+		if err := fn(type_, b[off+int(header):off+int(size)]); err != nil {
+			return err
+		}
+		off += int(size)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package probe
+
+import (
+	"encoding/binary"
+	"github.com/zlataovce/nero/repo/media"
+	"testing"
+	"time"
+)
+
+func mp4Box(type_ string, children ...[]byte) []byte {
+	var data []byte
+	for _, c := range children {
+		data = append(data, c...)
+	}
+
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, type_...), data...)
+}
+
+// mp4Box64 builds a box with a 64-bit size.
+func mp4Box64(type_ string, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, 1)
+	b = append(b, type_...)
+	b = binary.BigEndian.AppendUint64(b, uint64(16+len(data)))
+	return append(b, data...)
+}
+
+// mp4Box0 builds a box with a zero size, extending to the end of its parent.
+func mp4Box0(type_ string, data []byte) []byte {
+	return append(append(make([]byte, 4), type_...), data...)
+}
+
+func mvhd(timescale, duration uint32) []byte {
+	data := make([]byte, 100)
+	binary.BigEndian.PutUint32(data[12:], timescale)
+	binary.BigEndian.PutUint32(data[16:], duration)
+	return mp4Box("mvhd", data)
+}
+
+func mvhd64(timescale uint32, duration uint64) []byte {
+	data := make([]byte, 112)
+	data[0] = 1 // version
+	binary.BigEndian.PutUint32(data[20:], timescale)
+	binary.BigEndian.PutUint64(data[24:], duration)
+	return mp4Box("mvhd", data)
+}
+
+// mp4Trak builds a track of a handler type, the matrix is the identity unless rotated by 90 degrees.
+func mp4Trak(handler string, w, h int, version byte, rotated bool) []byte {
+	off := 24
+	if version == 1 {
+		off = 36
+	}
+
+	tkhd := make([]byte, off+60)
+	tkhd[0] = version
+	if rotated {
+		binary.BigEndian.PutUint32(tkhd[off+20:], 0x10000)
+		binary.BigEndian.PutUint32(tkhd[off+28:], 0xffff0000)
+	} else {
+		binary.BigEndian.PutUint32(tkhd[off+16:], 0x10000)
+		binary.BigEndian.PutUint32(tkhd[off+32:], 0x10000)
+	}
+	binary.BigEndian.PutUint32(tkhd[off+52:], uint32(w)<<16)
+	binary.BigEndian.PutUint32(tkhd[off+56:], uint32(h)<<16)
+
+	hdlr := append(make([]byte, 8), handler...)
+	hdlr = append(hdlr, make([]byte, 13)...)
+
+	return mp4Box("trak", mp4Box("tkhd", tkhd), mp4Box("mdia", mp4Box("mdhd", make([]byte, 24)), mp4Box("hdlr", hdlr)))
+}
+
+func testMP4(moov ...[]byte) []byte {
+	b := mp4Box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	b = append(b, mp4Box("moov", moov...)...)
+	return append(b, mp4Box("mdat", make([]byte, 16))...)
+}
+
+func TestProbeMP4(t *testing.T) {
+	video := mp4Trak("vide", 1920, 1080, 0, false)
+
+	runProbeTests(t, []probeTest{
+		{
+			name:     "mp4",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(1000, 5568), video),
+			want:     &media.Dimensions{Width: 1920, Height: 1080, Duration: 5568 * time.Millisecond},
+		},
+		{
+			name:     "64-bit times",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd64(90000, 90000*3), mp4Trak("vide", 640, 360, 1, false)),
+			want:     &media.Dimensions{Width: 640, Height: 360, Duration: 3 * time.Second},
+		},
+		{
+			name:     "rotated",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(1000, 1000), mp4Trak("vide", 1920, 1080, 0, true)),
+			want:     &media.Dimensions{Width: 1080, Height: 1920, Duration: time.Second},
+		},
+		{
+			name:     "audio track first",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(1000, 1000), mp4Trak("soun", 0, 0, 0, false), video, mp4Trak("vide", 10, 10, 0, false)),
+			want:     &media.Dimensions{Width: 1920, Height: 1080, Duration: time.Second},
+		},
+		{
+			name:     "unknown duration",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(1000, 0xffffffff), video),
+			want:     &media.Dimensions{Width: 1920, Height: 1080},
+		},
+		{
+			name:     "zero timescale",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(0, 1000), video),
+			want:     &media.Dimensions{Width: 1920, Height: 1080},
+		},
+		{
+			name:     "64-bit box size",
+			mimeType: "video/mp4",
+			data:     append(mp4Box("ftyp", nil), mp4Box64("moov", append(mvhd(1000, 2000), video...))...),
+			want:     &media.Dimensions{Width: 1920, Height: 1080, Duration: 2 * time.Second},
+		},
+		{
+			name:     "zero box size",
+			mimeType: "video/mp4",
+			data:     append(mp4Box("ftyp", nil), mp4Box0("moov", append(mvhd(1000, 2000), video...))...),
+			want:     &media.Dimensions{Width: 1920, Height: 1080, Duration: 2 * time.Second},
+		},
+		{name: "missing moov", mimeType: "video/mp4", data: mp4Box("ftyp", nil)},
+		{name: "no video track", mimeType: "video/mp4", data: testMP4(mvhd(1000, 1000), mp4Trak("soun", 0, 0, 0, false))},
+		{name: "box smaller than its header", mimeType: "video/mp4", data: append(mp4Box("ftyp", nil), 0, 0, 0, 4, 'm', 'o', 'o', 'v')},
+		{name: "64-bit box smaller than its header", mimeType: "video/mp4", data: append(mp4Box("ftyp", nil), 0, 0, 0, 1, 'm', 'o', 'o', 'v', 0, 0, 0, 0, 0, 0, 0, 8)},
+		{name: "oversized box", mimeType: "video/mp4", data: append(mp4Box("ftyp", nil), 0xff, 0xff, 0xff, 0xff, 'm', 'o', 'o', 'v')},
+		{name: "oversized 64-bit box", mimeType: "video/mp4", data: append(mp4Box("ftyp", nil), 0, 0, 0, 1, 'm', 'o', 'o', 'v', 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		{name: "truncated box header", mimeType: "video/mp4", data: append(mp4Box("ftyp", nil), 0, 0, 0)},
+		{name: "truncated mvhd", mimeType: "video/mp4", data: testMP4(mp4Box("mvhd", make([]byte, 12)), video)},
+		{name: "truncated 64-bit mvhd", mimeType: "video/mp4", data: testMP4(mp4Box("mvhd", []byte{1, 0, 0, 0}), video)},
+		{
+			name:     "truncated tkhd",
+			mimeType: "video/mp4",
+			data:     testMP4(mvhd(1000, 1000), mp4Box("trak", mp4Box("tkhd", make([]byte, 40)), mp4Box("mdia", mp4Box("hdlr", []byte("\x00\x00\x00\x00\x00\x00\x00\x00vide"))))),
+		},
+		{name: "truncated track", mimeType: "video/mp4", data: testMP4(mvhd(1000, 1000), video[:60])},
+	})
+}
+
+func TestProbeMP4Truncated(t *testing.T) {
+	b := testMP4(mvhd(1000, 5568), mp4Trak("vide", 1920, 1080, 0, false))
+	for n := 0; n < len(b); n++ {
+		if d, err := Probe(b[:n], "video/mp4"); err == nil && (d.Width != 1920 || d.Height != 1080) {
+			t.Errorf("prefix of %d bytes probed as %+v", n, d)
+		}
+	}
+}
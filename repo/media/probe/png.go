@@ -0,0 +1,67 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"time"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// probePNG reads the dimensions of a PNG image, including the frames of APNG animations.
+func probePNG(b []byte) (*media.Dimensions, error) {
+	if !bytes.HasPrefix(b, pngSignature) {
+		return nil, errors.New("not a png image")
+	}
+
+	var (
+		d        media.Dimensions
+		animated bool
+	)
+	for off := len(pngSignature); off+8 <= len(b); {
+		var (
+			length = int(binary.BigEndian.Uint32(b[off:]))
+			type_  = string(b[off+4 : off+8])
+		)
+		if length < 0 || off+8+length > len(b) {
+			return nil, errTruncated
+		}
+
+		data := b[off+8 : off+8+length]
+		switch type_ {
+		case "IHDR":
+			if len(data) < 8 {
+				return nil, errTruncated
+			}
+
+			d.Width = int(binary.BigEndian.Uint32(data))
+			d.Height = int(binary.BigEndian.Uint32(data[4:]))
+		case "acTL":
+			animated = true
+		case "fcTL":
+			if len(data) < 26 {
+				return nil, errTruncated
+			}
+
+			d.Frames++
+
+			num, den := binary.BigEndian.Uint16(data[20:]), binary.BigEndian.Uint16(data[22:])
+			if den == 0 {
+				den = 100 // per the specification
+			}
+			d.Duration += time.Duration(num) * time.Second / time.Duration(den)
+		case "IEND":
+			off = len(b)
+			continue
+		}
+
+		off += 12 + length // length, type, data and crc
+	}
+
+	if !animated { // fcTL chunks don't mean anything without acTL
+		d.Frames, d.Duration = 0, 0
+	}
+	return checkDimensions(&d)
+}
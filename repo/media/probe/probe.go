@@ -0,0 +1,122 @@
+// Package probe reads the dimensions of media files without decoding them fully.
+package probe
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"image/jpeg"
+)
+
+// ErrUnsupported is returned when probing media of an unsupported type.
+var ErrUnsupported = errors.New("unsupported media type")
+
+// Probe reads the dimensions of media by its MIME type, the frame count is only read for animated images
+// and the duration for animated images and videos. Returns ErrUnsupported if the type can't be probed.
+func Probe(b []byte, mimeType string) (*media.Dimensions, error) {
+	var (
+		d   *media.Dimensions
+		err error
+	)
+	switch mimeType {
+	case "image/jpeg":
+		d, err = probeJPEG(b)
+	case "image/png", "image/vnd.mozilla.apng":
+		d, err = probePNG(b)
+	case "image/gif":
+		d, err = probeGIF(b)
+	case "image/webp":
+		d, err = probeWebP(b)
+	case "video/mp4":
+		d, err = probeMP4(b)
+	case "video/webm":
+		d, err = probeWebM(b)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to probe %s", mimeType)
+	}
+
+	return d, nil
+}
+
+func probeJPEG(b []byte) (*media.Dimensions, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	return &media.Dimensions{Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// errTruncated is returned when the data ends in the middle of a structure.
+var errTruncated = errors.New("truncated data")
+
+// reader is a bounds-checked little-endian reader of binary structures.
+type reader struct {
+	b   []byte
+	off int
+	err error
+}
+
+// next returns the next n bytes, it returns nil and sets errTruncated if there are not enough bytes left.
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b)-r.off < n {
+		r.err = errTruncated
+		return nil
+	}
+
+	b := r.b[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+
+	return 0
+}
+
+func (r *reader) uint16() int {
+	if b := r.next(2); b != nil {
+		return int(b[0]) | int(b[1])<<8
+	}
+
+	return 0
+}
+
+func (r *reader) uint24() int {
+	if b := r.next(3); b != nil {
+		return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+	}
+
+	return 0
+}
+
+func (r *reader) uint32() int {
+	if b := r.next(4); b != nil {
+		return int(b[0]) | int(b[1])<<8 | int(b[2])<<16 | int(b[3])<<24
+	}
+
+	return 0
+}
+
+func (r *reader) done() bool {
+	return r.err != nil || r.off >= len(r.b)
+}
+
+// checkDimensions checks whether probed dimensions are plausible.
+func checkDimensions(d *media.Dimensions) (*media.Dimensions, error) {
+	if d.Width <= 0 || d.Height <= 0 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", d.Width, d.Height)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,185 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"github.com/zlataovce/nero/repo/media"
+	"testing"
+	"time"
+)
+
+// testPNG encodes a PNG image of a size with additional chunks after the header.
+func testPNG(t *testing.T, w, h int, chunks ...[]byte) []byte {
+	t.Helper()
+
+	return mediatest.PNG(t, mediatest.Image(w, h), chunks...)
+}
+
+func fcTL(num, den uint16) []byte {
+	data := make([]byte, 26)
+	binary.BigEndian.PutUint16(data[20:], num)
+	binary.BigEndian.PutUint16(data[22:], den)
+	return mediatest.PNGChunk("fcTL", data)
+}
+
+// testGIF builds a GIF image of a size with a frame for each delay in hundredths of a second.
+func testGIF(w, h int, delays ...int) []byte {
+	b := []byte("GIF89a")
+	b = binary.LittleEndian.AppendUint16(b, uint16(w))
+	b = binary.LittleEndian.AppendUint16(b, uint16(h))
+	b = append(b, 0x80, 0, 0)                // a global color table of 2 colors
+	b = append(b, 0, 0, 0, 0xff, 0xff, 0xff) // the global color table
+	for _, delay := range delays {
+		b = append(b, 0x21, 0xf9, 4, 0, byte(delay), byte(delay>>8), 0, 0) // graphic control extension
+		b = append(b, 0x2c, 0, 0, 0, 0, byte(w), byte(w>>8), byte(h), byte(h>>8), 0)
+		b = append(b, 2, 2, 0x4c, 0x01, 0) // lzw minimum code size, a sub-block and the terminator
+	}
+	return append(b, 0x3b)
+}
+
+func uint24(v int) []byte {
+	return []byte{byte(v), byte(v >> 8), byte(v >> 16)}
+}
+
+func vp8x(flags byte, w, h int) []byte {
+	data := []byte{flags, 0, 0, 0}
+	data = append(data, uint24(w-1)...)
+	return mediatest.WebPChunk("VP8X", append(data, uint24(h-1)...))
+}
+
+func anmf(duration int) []byte {
+	data := make([]byte, 12)
+	data = append(data, uint24(duration)...)
+	return mediatest.WebPChunk("ANMF", append(data, 0))
+}
+
+func vp8l(w, h int) []byte {
+	bits := uint32(w-1) | uint32(h-1)<<14
+	return mediatest.WebPChunk("VP8L", append([]byte{0x2f}, binary.LittleEndian.AppendUint32(nil, bits)...))
+}
+
+func vp8(w, h int) []byte {
+	data := []byte{0, 0, 0, 0x9d, 0x01, 0x2a}
+	data = binary.LittleEndian.AppendUint16(data, uint16(w))
+	return mediatest.WebPChunk("VP8 ", binary.LittleEndian.AppendUint16(data, uint16(h)))
+}
+
+// probeTest is a test case of Probe, the data is expected to be rejected if want is nil.
+type probeTest struct {
+	name     string
+	mimeType string
+	data     []byte
+	want     *media.Dimensions
+}
+
+func runProbeTests(t *testing.T, tests []probeTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Probe(tt.data, tt.mimeType)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("probed malformed media as %+v", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *d != *tt.want {
+				t.Errorf("got %+v, want %+v", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	runProbeTests(t, []probeTest{
+		{name: "jpeg", mimeType: "image/jpeg", data: mediatest.JPEG(t, mediatest.Image(33, 17)), want: &media.Dimensions{Width: 33, Height: 17}},
+		{name: "jpeg garbage", mimeType: "image/jpeg", data: []byte("\xff\xd8garbage")},
+		{name: "png", mimeType: "image/png", data: testPNG(t, 640, 480), want: &media.Dimensions{Width: 640, Height: 480}},
+		{
+			name:     "apng",
+			mimeType: "image/vnd.mozilla.apng",
+			data:     testPNG(t, 64, 32, mediatest.PNGChunk("acTL", make([]byte, 8)), fcTL(1, 10), fcTL(25, 100), fcTL(1, 0)),
+			want:     &media.Dimensions{Width: 64, Height: 32, Frames: 3, Duration: 360 * time.Millisecond},
+		},
+		{
+			name:     "png frames without actl",
+			mimeType: "image/png",
+			data:     testPNG(t, 64, 32, fcTL(1, 10)),
+			want:     &media.Dimensions{Width: 64, Height: 32},
+		},
+		{name: "png zero size", mimeType: "image/png", data: append([]byte(mediatest.PNGSignature), mediatest.PNGChunk("IHDR", make([]byte, 13))...)},
+		{name: "png short header", mimeType: "image/png", data: append(append([]byte(nil), pngSignature...), mediatest.PNGChunk("IHDR", make([]byte, 4))...)},
+		{name: "png short fctl", mimeType: "image/png", data: testPNG(t, 1, 1, mediatest.PNGChunk("acTL", make([]byte, 8)), mediatest.PNGChunk("fcTL", make([]byte, 10)))},
+		{name: "png oversized chunk", mimeType: "image/png", data: append(append([]byte(nil), pngSignature...), 0xff, 0xff, 0xff, 0xff, 'I', 'H', 'D', 'R')},
+		{name: "not a png", mimeType: "image/png", data: []byte("GIF89a")},
+		{name: "gif", mimeType: "image/gif", data: testGIF(10, 20, 0), want: &media.Dimensions{Width: 10, Height: 20, Frames: 1}},
+		{
+			name:     "animated gif",
+			mimeType: "image/gif",
+			data:     testGIF(10, 20, 10, 5, 100),
+			want:     &media.Dimensions{Width: 10, Height: 20, Frames: 3, Duration: 1150 * time.Millisecond},
+		},
+		{
+			name:     "gif without trailer",
+			mimeType: "image/gif",
+			data:     bytes.TrimSuffix(testGIF(10, 20, 10, 10), []byte{0x3b}),
+			want:     &media.Dimensions{Width: 10, Height: 20, Frames: 2, Duration: 200 * time.Millisecond},
+		},
+		{name: "gif truncated color table", mimeType: "image/gif", data: testGIF(10, 20)[:16]},
+		{name: "gif unknown block", mimeType: "image/gif", data: append(testGIF(10, 20)[:19], 0x99)},
+		{name: "gif zero size", mimeType: "image/gif", data: testGIF(0, 0, 0)},
+		{name: "webp lossy", mimeType: "image/webp", data: mediatest.WebP(vp8(300, 200)), want: &media.Dimensions{Width: 300, Height: 200}},
+		{name: "webp lossless", mimeType: "image/webp", data: mediatest.WebP(vp8l(300, 200)), want: &media.Dimensions{Width: 300, Height: 200}},
+		{name: "webp extended", mimeType: "image/webp", data: mediatest.WebP(vp8x(0, 1000, 2000), vp8l(1, 1)), want: &media.Dimensions{Width: 1000, Height: 2000}},
+		{
+			name:     "animated webp",
+			mimeType: "image/webp",
+			data:     mediatest.WebP(vp8x(0x02, 50, 60), mediatest.WebPChunk("ANIM", make([]byte, 6)), anmf(100), anmf(250)),
+			want:     &media.Dimensions{Width: 50, Height: 60, Frames: 2, Duration: 350 * time.Millisecond},
+		},
+		{name: "webp bad vp8 start code", mimeType: "image/webp", data: mediatest.WebP(mediatest.WebPChunk("VP8 ", make([]byte, 10)))},
+		{name: "webp bad vp8l signature", mimeType: "image/webp", data: mediatest.WebP(mediatest.WebPChunk("VP8L", make([]byte, 5)))},
+		{name: "webp short vp8x", mimeType: "image/webp", data: mediatest.WebP(mediatest.WebPChunk("VP8X", make([]byte, 4)))},
+		{name: "webp oversized chunk", mimeType: "image/webp", data: mediatest.WebP([]byte("VP8L\xff\xff\xff\x7f"))},
+		{name: "webp without image", mimeType: "image/webp", data: mediatest.WebP()},
+		{name: "not a webp", mimeType: "image/webp", data: []byte("RIFF\x00\x00\x00\x00WAVE")},
+	})
+}
+
+func TestProbeUnsupported(t *testing.T) {
+	if _, err := Probe([]byte("hello"), "text/plain"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("got error %v, want ErrUnsupported", err)
+	}
+}
+
+// TestProbeTruncated probes every prefix of valid media, they must not panic or be probed with other dimensions.
+func TestProbeTruncated(t *testing.T) {
+	tests := []probeTest{
+		{name: "jpeg", mimeType: "image/jpeg", data: mediatest.JPEG(t, mediatest.Image(8, 8))},
+		{name: "apng", mimeType: "image/vnd.mozilla.apng", data: testPNG(t, 64, 32, mediatest.PNGChunk("acTL", make([]byte, 8)), fcTL(1, 10))},
+		{name: "gif", mimeType: "image/gif", data: testGIF(10, 20, 10, 10)},
+		{name: "webp", mimeType: "image/webp", data: mediatest.WebP(vp8x(0x02, 50, 60), anmf(100), anmf(250))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Probe(tt.data, tt.mimeType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for n := 0; n < len(tt.data); n++ {
+				d, err := Probe(tt.data[:n], tt.mimeType)
+				if err == nil && (d.Width != want.Width || d.Height != want.Height) {
+					t.Errorf("prefix of %d bytes probed as %+v, want %+v", n, d, want)
+				}
+			}
+		})
+	}
+}
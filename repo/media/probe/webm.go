@@ -0,0 +1,228 @@
+package probe
+
+import (
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"math"
+	"time"
+)
+
+// IDs of the EBML elements read from WebM videos, including their length marker bits.
+const (
+	ebmlHeader        = 0x1a45dfa3
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549a966
+	ebmlTimecodeScale = 0x2ad7b1
+	ebmlDuration      = 0x4489
+	ebmlTracks        = 0x1654ae6b
+	ebmlTrackEntry    = 0xae
+	ebmlTrackType     = 0x83
+	ebmlVideo         = 0xe0
+	ebmlPixelWidth    = 0xb0
+	ebmlPixelHeight   = 0xba
+	ebmlCluster       = 0x1f43b675
+)
+
+// probeWebM reads the dimensions of a WebM video from its EBML elements, the size of the first video track
+// and the duration of the segment.
+func probeWebM(b []byte) (*media.Dimensions, error) {
+	top, err := readElements(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(top) == 0 || top[0].id != ebmlHeader {
+		return nil, errors.New("not a webm video")
+	}
+
+	var segment []byte
+	for _, e := range top {
+		if e.id == ebmlSegment {
+			segment = e.data
+			break
+		}
+	}
+	if segment == nil {
+		return nil, errors.New("missing segment element")
+	}
+
+	// the metadata precede the clusters, which are not read
+	children, err := readElements(segment)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		d             media.Dimensions
+		timecodeScale = uint64(time.Millisecond) // in nanoseconds, per the specification
+		duration      float64                    // in timecode scale units
+	)
+	for _, e := range children {
+		switch e.id {
+		case ebmlInfo:
+			info, err := readElements(e.data)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, e0 := range info {
+				switch e0.id {
+				case ebmlTimecodeScale:
+					timecodeScale = readUint(e0.data)
+				case ebmlDuration:
+					duration = readFloat(e0.data)
+				}
+			}
+		case ebmlTracks:
+			if d.Width > 0 {
+				continue
+			}
+
+			if d.Width, d.Height, err = probeWebMTracks(e.data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	d.Duration = time.Duration(math.Round(duration * float64(timecodeScale)))
+	return checkDimensions(&d)
+}
+
+// probeWebMTracks reads the size of the first video track of a tracks element.
+func probeWebMTracks(b []byte) (w, h int, err error) {
+	tracks, err := readElements(b)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, t := range tracks {
+		if t.id != ebmlTrackEntry {
+			continue
+		}
+
+		entry, err := readElements(t.data)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		var (
+			video bool
+			size  []byte
+		)
+		for _, e := range entry {
+			switch e.id {
+			case ebmlTrackType:
+				video = readUint(e.data) == 1
+			case ebmlVideo:
+				size = e.data
+			}
+		}
+		if !video || size == nil {
+			continue
+		}
+
+		settings, err := readElements(size)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, e := range settings {
+			switch e.id {
+			case ebmlPixelWidth:
+				w = int(readUint(e.data))
+			case ebmlPixelHeight:
+				h = int(readUint(e.data))
+			}
+		}
+
+		return w, h, nil
+	}
+
+	return 0, 0, nil
+}
+
+// element is an EBML element.
+type element struct {
+	id   uint32
+	data []byte
+}
+
+// readElements reads the EBML elements of a level, up to the first cluster. Elements of unknown size,
+// i.e. segments of live recordings, extend to the end of the data.
+func readElements(b []byte) ([]element, error) {
+	var res []element
+	for off := 0; off < len(b); {
+		id, n := readVint(b[off:], true)
+		if n == 0 || n > 4 {
+			return nil, errors.New("invalid element id")
+		}
+		off += n
+
+		size, n := readVint(b[off:], false)
+		if n == 0 {
+			return nil, errors.New("invalid element size")
+		}
+		off += n
+
+		if size == 1<<(7*n)-1 { // all value bits set, unknown size
+			size = uint64(len(b) - off)
+		}
+		if id == ebmlCluster {
+			break
+		}
+		if size > uint64(len(b)-off) {
+			return nil, errTruncated
+		}
+
+		res = append(res, element{id: uint32(id), data: b[off : off+int(size)]})
+		off += int(size)
+	}
+
+	return res, nil
+}
+
+// readVint reads a variable-length EBML integer, optionally keeping its length marker (for element IDs).
+// Returns the number of bytes read, 0 if the integer is malformed or truncated.
+func readVint(b []byte, marker bool) (uint64, int) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0
+	}
+
+	n := 1
+	for b[0]&(0x80>>(n-1)) == 0 {
+		n++
+	}
+	if len(b) < n {
+		return 0, 0
+	}
+
+	v := uint64(b[0])
+	if !marker {
+		v &= 0xff >> n
+	}
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+
+	return v, n
+}
+
+// readUint reads an EBML unsigned integer.
+func readUint(b []byte) (v uint64) {
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v
+}
+
+// readFloat reads an EBML float, either 4 or 8 bytes long.
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+
+	return 0
+}
@@ -0,0 +1,151 @@
+package probe
+
+import (
+	"encoding/binary"
+	"github.com/zlataovce/nero/repo/media"
+	"math"
+	"testing"
+	"time"
+)
+
+// unknownSize is the 8-byte EBML size with all value bits set, marking an element of unknown size.
+var unknownSize = []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// ebmlID encodes an element ID, including its length marker bits.
+func ebmlID(id uint32) []byte {
+	switch {
+	case id > 0xffffff:
+		return binary.BigEndian.AppendUint32(nil, id)
+	case id > 0xffff:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xff:
+		return []byte{byte(id >> 8), byte(id)}
+	}
+
+	return []byte{byte(id)}
+}
+
+// ebml builds an element with children or data, sizes are encoded in 8 bytes above 126 bytes.
+func ebml(id uint32, children ...[]byte) []byte {
+	var data []byte
+	for _, c := range children {
+		data = append(data, c...)
+	}
+
+	b := ebmlID(id)
+	if len(data) < 0x7f {
+		b = append(b, 0x80|byte(len(data)))
+	} else {
+		b = append(b, 0x01)
+		b = append(b, binary.BigEndian.AppendUint64(nil, uint64(len(data)))[1:]...)
+	}
+	return append(b, data...)
+}
+
+// ebmlUnknown builds an element of unknown size.
+func ebmlUnknown(id uint32, children ...[]byte) []byte {
+	b := append(ebmlID(id), unknownSize...)
+	for _, c := range children {
+		b = append(b, c...)
+	}
+	return b
+}
+
+func ebmlUint(id uint32, v uint64) []byte {
+	return ebml(id, binary.BigEndian.AppendUint64(nil, v))
+}
+
+func ebmlFloat(id uint32, v float64) []byte {
+	return ebml(id, binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func ebmlFloat32(id uint32, v float32) []byte {
+	return ebml(id, binary.BigEndian.AppendUint32(nil, math.Float32bits(v)))
+}
+
+func webmTrack(type_ uint64, w, h uint64) []byte {
+	return ebml(ebmlTrackEntry, ebmlUint(ebmlTrackType, type_), ebml(ebmlVideo, ebmlUint(ebmlPixelWidth, w), ebmlUint(ebmlPixelHeight, h)))
+}
+
+var webmHeader = ebml(ebmlHeader, ebml(0x4282, []byte("webm")))
+
+func testWebM(segment ...[]byte) []byte {
+	return append(append([]byte(nil), webmHeader...), ebml(ebmlSegment, segment...)...)
+}
+
+func TestProbeWebM(t *testing.T) {
+	var (
+		info   = ebml(ebmlInfo, ebmlUint(ebmlTimecodeScale, 1_000_000), ebmlFloat(ebmlDuration, 5568))
+		tracks = ebml(ebmlTracks, webmTrack(1, 560, 320))
+		want   = &media.Dimensions{Width: 560, Height: 320, Duration: 5568 * time.Millisecond}
+	)
+
+	runProbeTests(t, []probeTest{
+		{name: "webm", mimeType: "video/webm", data: testWebM(info, tracks), want: want},
+		{
+			name:     "default timecode scale",
+			mimeType: "video/webm",
+			data:     testWebM(ebml(ebmlInfo, ebmlFloat32(ebmlDuration, 1500)), tracks),
+			want:     &media.Dimensions{Width: 560, Height: 320, Duration: 1500 * time.Millisecond},
+		},
+		{
+			name:     "microsecond timecode scale",
+			mimeType: "video/webm",
+			data:     testWebM(ebml(ebmlInfo, ebmlUint(ebmlTimecodeScale, 1000), ebmlFloat(ebmlDuration, 2_000_000)), tracks),
+			want:     &media.Dimensions{Width: 560, Height: 320, Duration: 2 * time.Second},
+		},
+		{
+			name:     "audio track first",
+			mimeType: "video/webm",
+			data:     testWebM(info, ebml(ebmlTracks, ebml(ebmlTrackEntry, ebmlUint(ebmlTrackType, 2)), webmTrack(1, 560, 320), webmTrack(1, 10, 10))),
+			want:     want,
+		},
+		{
+			name:     "unknown-size segment",
+			mimeType: "video/webm",
+			data:     append(append([]byte(nil), webmHeader...), ebmlUnknown(ebmlSegment, info, tracks, ebmlUnknown(ebmlCluster, make([]byte, 32)))...),
+			want:     want,
+		},
+		{
+			name:     "unknown-size cluster",
+			mimeType: "video/webm",
+			data:     testWebM(info, tracks, ebmlUnknown(ebmlCluster, []byte{0x00, 0x00})), // the cluster is not read
+			want:     want,
+		},
+		{
+			name:     "large tracks",
+			mimeType: "video/webm",
+			data:     testWebM(info, ebml(ebmlTracks, ebml(0xec, make([]byte, 200)), webmTrack(1, 560, 320))), // void element
+			want:     want,
+		},
+		{
+			name:     "no duration",
+			mimeType: "video/webm",
+			data:     testWebM(tracks),
+			want:     &media.Dimensions{Width: 560, Height: 320},
+		},
+		{name: "not a webm", mimeType: "video/webm", data: ebml(ebmlSegment, info, tracks)},
+		{name: "empty", mimeType: "video/webm", data: nil},
+		{name: "missing segment", mimeType: "video/webm", data: webmHeader},
+		{name: "no video track", mimeType: "video/webm", data: testWebM(info, ebml(ebmlTracks, ebml(ebmlTrackEntry, ebmlUint(ebmlTrackType, 2))))},
+		{name: "video track without size", mimeType: "video/webm", data: testWebM(info, ebml(ebmlTracks, ebml(ebmlTrackEntry, ebmlUint(ebmlTrackType, 1))))},
+		{name: "unknown-size info", mimeType: "video/webm", data: testWebM(ebmlUnknown(ebmlInfo, ebmlFloat(ebmlDuration, 1)), tracks)},
+		{name: "zero id", mimeType: "video/webm", data: append(append([]byte(nil), webmHeader...), 0x00, 0x80)},
+		{name: "id too long", mimeType: "video/webm", data: append(append([]byte(nil), webmHeader...), 0x08, 0, 0, 0, 0, 0x80)},
+		{name: "zero size marker", mimeType: "video/webm", data: append(append([]byte(nil), webmHeader...), 0xec, 0x00)},
+		{name: "oversized element", mimeType: "video/webm", data: append(append([]byte(nil), webmHeader...), 0xec, 0x88)},
+		{name: "truncated size", mimeType: "video/webm", data: append(append([]byte(nil), webmHeader...), 0xec, 0x40)},
+	})
+}
+
+func TestProbeWebMTruncated(t *testing.T) {
+	b := testWebM(
+		ebml(ebmlInfo, ebmlUint(ebmlTimecodeScale, 1_000_000), ebmlFloat(ebmlDuration, 5568)),
+		ebml(ebmlTracks, webmTrack(1, 560, 320)),
+	)
+	for n := 0; n < len(b); n++ {
+		if d, err := Probe(b[:n], "video/webm"); err == nil && (d.Width != 560 || d.Height != 320) {
+			t.Errorf("prefix of %d bytes probed as %+v", n, d)
+		}
+	}
+}
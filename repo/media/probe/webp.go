@@ -0,0 +1,72 @@
+package probe
+
+import (
+	"bytes"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo/media"
+	"time"
+)
+
+// probeWebP reads the dimensions of a WebP image from its RIFF chunks, including the frames of animations.
+func probeWebP(b []byte) (*media.Dimensions, error) {
+	if len(b) < 12 || !bytes.Equal(b[:4], []byte("RIFF")) || !bytes.Equal(b[8:12], []byte("WEBP")) {
+		return nil, errors.New("not a webp image")
+	}
+
+	var (
+		d        media.Dimensions
+		animated bool
+		r        = &reader{b: b, off: 12}
+	)
+	for !r.done() {
+		type_ := string(r.next(4))
+		size := r.uint32()
+
+		chunk := &reader{b: r.next(size)}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if size%2 != 0 && !r.done() {
+			r.next(1) // padding
+		}
+
+		switch type_ {
+		case "VP8X": // extended format, the canvas size applies to all frames
+			flags := chunk.byte()
+			chunk.next(3) // reserved
+			d.Width, d.Height = chunk.uint24()+1, chunk.uint24()+1
+			animated = flags&0x02 != 0
+		case "VP8 ": // lossy, only present without VP8X in still images
+			if d.Width == 0 {
+				chunk.next(3) // frame tag
+				if !bytes.Equal(chunk.next(3), []byte{0x9d, 0x01, 0x2a}) {
+					return nil, errors.New("invalid vp8 start code")
+				}
+				d.Width, d.Height = chunk.uint16()&0x3fff, chunk.uint16()&0x3fff
+			}
+		case "VP8L": // lossless
+			if d.Width == 0 {
+				if chunk.byte() != 0x2f {
+					return nil, errors.New("invalid vp8l signature")
+				}
+
+				bits := chunk.uint32()
+				d.Width, d.Height = bits&0x3fff+1, (bits>>14)&0x3fff+1
+			}
+		case "ANMF": // animation frame
+			chunk.next(12) // offset and size
+			duration := chunk.uint24()
+
+			d.Frames++
+			d.Duration += time.Duration(duration) * time.Millisecond
+		}
+		if chunk.err != nil {
+			return nil, chunk.err
+		}
+	}
+
+	if !animated {
+		d.Frames, d.Duration = 0, 0
+	}
+	return checkDimensions(&d)
+}
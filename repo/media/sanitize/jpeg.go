@@ -0,0 +1,93 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+)
+
+// Identifiers of the JPEG application segments containing metadata.
+var (
+	exifPrefix         = []byte("Exif\x00\x00")
+	xmpPrefix          = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtensionPrefix = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	iccPrefix          = []byte("ICC_PROFILE\x00")
+)
+
+// stripJPEG removes the metadata segments of a JPEG image, the scan data following them is copied as-is.
+func (s *stripper) stripJPEG(b []byte) ([]byte, error) {
+	if len(b) < 2 || b[0] != 0xff || b[1] != 0xd8 {
+		return nil, errors.New("not a jpeg image")
+	}
+
+	res := make([]byte, 0, len(b))
+	res = append(res, b[:2]...)
+	for off := 2; ; {
+		if off+2 > len(b) {
+			return nil, errTruncated
+		}
+		if b[off] != 0xff {
+			return nil, errors.New("invalid marker")
+		}
+
+		marker := b[off+1]
+		switch {
+		case marker == 0xff: // fill byte
+			off++
+			continue
+		case marker == 0xda || marker == 0xd9: // start of scan or end of image, no metadata follow
+			return append(res, b[off:]...), nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7): // no length
+			res = append(res, b[off:off+2]...)
+			off += 2
+			continue
+		}
+
+		if off+4 > len(b) {
+			return nil, errTruncated
+		}
+		length := int(binary.BigEndian.Uint16(b[off+2:])) // including the length itself
+		if length < 2 || off+2+length > len(b) {
+			return nil, errTruncated
+		}
+
+		var (
+			segment = b[off : off+2+length]
+			data    = segment[4:]
+			drop    bool
+		)
+		off += len(segment)
+
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(data, exifPrefix):
+			if drop = s.strip(KindEXIF); drop {
+				if tiff := minimalEXIF(orientation(data[len(exifPrefix):])); tiff != nil {
+					res = appendJPEGSegment(res, 0xe1, exifPrefix, tiff)
+				}
+			}
+		case marker == 0xe1 && (bytes.HasPrefix(data, xmpPrefix) || bytes.HasPrefix(data, xmpExtensionPrefix)):
+			drop = s.strip(KindXMP)
+		case marker == 0xe2 && bytes.HasPrefix(data, iccPrefix):
+			drop = s.strip(KindICC)
+		}
+		if !drop {
+			res = append(res, segment...)
+		}
+	}
+}
+
+// appendJPEGSegment appends a segment with a marker and data split into parts.
+func appendJPEGSegment(b []byte, marker byte, parts ...[]byte) []byte {
+	length := 2
+	for _, p := range parts {
+		length += len(p)
+	}
+
+	b = append(b, 0xff, marker)
+	b = binary.BigEndian.AppendUint16(b, uint16(length))
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+
+	return b
+}
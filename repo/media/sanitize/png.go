@@ -0,0 +1,83 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"hash/crc32"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngTextKinds are the kinds of metadata stored in text chunks by their keyword, the raw profiles
+// are written by ImageMagick.
+var pngTextKinds = map[string]Kind{
+	"XML:com.adobe.xmp":     KindXMP,
+	"Raw profile type exif": KindEXIF,
+	"Raw profile type APP1": KindEXIF,
+	"Raw profile type xmp":  KindXMP,
+	"Raw profile type icc":  KindICC,
+	"Raw profile type icm":  KindICC,
+}
+
+// stripPNG removes the metadata chunks of a PNG image, including APNG animations.
+func (s *stripper) stripPNG(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, pngSignature) {
+		return nil, errors.New("not a png image")
+	}
+
+	res := make([]byte, 0, len(b))
+	res = append(res, pngSignature...)
+	for off := len(pngSignature); off < len(b); {
+		if off+12 > len(b) {
+			return nil, errTruncated
+		}
+
+		var (
+			length = int(binary.BigEndian.Uint32(b[off:]))
+			type_  = string(b[off+4 : off+8])
+		)
+		if length < 0 || off+12+length > len(b) {
+			return nil, errTruncated
+		}
+
+		var (
+			chunk = b[off : off+12+length] // including the length, the type and the CRC
+			data  = chunk[8 : 8+length]
+			drop  bool
+		)
+		off += len(chunk)
+
+		switch type_ {
+		case "eXIf":
+			if drop = s.strip(KindEXIF); drop {
+				if tiff := minimalEXIF(orientation(bytes.TrimPrefix(data, exifPrefix))); tiff != nil {
+					res = appendPNGChunk(res, "eXIf", tiff)
+				}
+			}
+		case "iCCP":
+			drop = s.strip(KindICC)
+		case "tEXt", "zTXt", "iTXt":
+			keyword, _, _ := bytes.Cut(data, []byte{0})
+			if k, ok := pngTextKinds[string(keyword)]; ok {
+				drop = s.strip(k)
+			}
+		}
+		if !drop {
+			res = append(res, chunk...)
+		}
+	}
+
+	return res, nil
+}
+
+// appendPNGChunk appends a chunk with a type and data.
+func appendPNGChunk(b []byte, type_ string, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+
+	start := len(b)
+	b = append(b, type_...)
+	b = append(b, data...)
+
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[start:]))
+}
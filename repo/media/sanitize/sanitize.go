@@ -0,0 +1,153 @@
+// Package sanitize strips privacy-sensitive metadata from images without re-encoding them.
+package sanitize
+
+import (
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"slices"
+)
+
+// Kind is a kind of image metadata.
+type Kind string
+
+const (
+	// KindEXIF is EXIF metadata, i.e. GPS coordinates, camera models and serial numbers.
+	KindEXIF Kind = "exif"
+	// KindXMP is XMP metadata, i.e. editing history and authorship.
+	KindXMP Kind = "xmp"
+	// KindICC is an embedded ICC color profile.
+	KindICC Kind = "icc"
+)
+
+// Kinds are all kinds of metadata that can be stripped, in the order they are reported.
+var Kinds = []Kind{KindEXIF, KindXMP, KindICC}
+
+// ParseKind parses a kind of metadata from its name, returns false if it's unknown.
+func ParseKind(s string) (Kind, bool) {
+	k := Kind(s)
+	return k, slices.Contains(Kinds, k)
+}
+
+// ErrUnsupported is returned when stripping the metadata of media of an unsupported type.
+var ErrUnsupported = errors.New("unsupported media type")
+
+// errTruncated is returned when the data ends in the middle of a structure.
+var errTruncated = errors.New("truncated data")
+
+// Strip removes metadata of the given kinds from an image by its MIME type, the image data is copied as-is.
+// EXIF orientation is kept in a minimal EXIF block, so that the image is still displayed upright.
+// Returns the stripped image and the kinds of metadata that were found and removed, the image is returned
+// unchanged if there were none. Returns ErrUnsupported if the type can't be stripped.
+func Strip(b []byte, mimeType string, kinds []Kind) ([]byte, []Kind, error) {
+	s := &stripper{kinds: kinds}
+
+	var (
+		res []byte
+		err error
+	)
+	switch mimeType {
+	case "image/jpeg":
+		res, err = s.stripJPEG(b)
+	case "image/png", "image/vnd.mozilla.apng":
+		res, err = s.stripPNG(b)
+	case "image/webp":
+		res, err = s.stripWebP(b)
+	default:
+		return nil, nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to strip %s", mimeType)
+	}
+	if len(s.removed) == 0 {
+		return b, nil, nil
+	}
+
+	var removed []Kind
+	for _, k := range Kinds {
+		if slices.Contains(s.removed, k) {
+			removed = append(removed, k)
+		}
+	}
+
+	return res, removed, nil
+}
+
+// stripper holds the state of stripping a single image.
+type stripper struct {
+	kinds   []Kind
+	removed []Kind
+}
+
+// strip checks whether metadata of a kind should be removed and records it if so.
+func (s *stripper) strip(k Kind) bool {
+	if !slices.Contains(s.kinds, k) {
+		return false
+	}
+
+	s.removed = append(s.removed, k)
+	return true
+}
+
+// orientationTag is the EXIF tag of the image orientation.
+const orientationTag = 0x0112
+
+// orientation reads the orientation from a TIFF structure of EXIF metadata, returns 0 if there is none.
+func orientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+
+	off := int(order.Uint32(tiff[4:])) // of the first IFD
+	if off < 8 || off+2 > len(tiff) {
+		return 0
+	}
+
+	n := int(order.Uint16(tiff[off:]))
+	for i := 0; i < n; i++ {
+		e := off + 2 + i*12
+		if e+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[e:]) == orientationTag && order.Uint16(tiff[e+2:]) == 3 { // SHORT
+			if v := int(order.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 0
+		}
+	}
+
+	return 0
+}
+
+// minimalEXIF returns a TIFF structure of EXIF metadata that only contains an orientation,
+// nil if the orientation is the default one and there's no need to keep it.
+func minimalEXIF(orientation int) []byte {
+	if orientation <= 1 {
+		return nil
+	}
+
+	b := make([]byte, 26)
+	copy(b, "MM\x00\x2a")
+	binary.BigEndian.PutUint32(b[4:], 8) // offset of the first IFD
+	binary.BigEndian.PutUint16(b[8:], 1) // entry count
+	binary.BigEndian.PutUint16(b[10:], orientationTag)
+	binary.BigEndian.PutUint16(b[12:], 3) // SHORT
+	binary.BigEndian.PutUint32(b[14:], 1) // value count
+	binary.BigEndian.PutUint16(b[18:], uint16(orientation))
+	// the padding of the value and the offset of the next IFD are zeroes
+
+	return b
+}
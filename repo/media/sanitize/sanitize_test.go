@@ -0,0 +1,404 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"hash/crc32"
+	"image/jpeg"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+// secret is contained in all stripped metadata of the tests.
+const secret = "SecretCam SN12345"
+
+// testTIFF builds the TIFF structure of EXIF metadata with a camera make and an orientation, if not 0.
+func testTIFF(order binary.AppendByteOrder, orientation int) []byte {
+	var (
+		make_   = []byte(secret + "\x00")
+		entries = 1
+	)
+	if orientation != 0 {
+		entries++
+	}
+
+	b := []byte("II")
+	if order == binary.BigEndian {
+		b = []byte("MM")
+	}
+	b = order.AppendUint16(b, 42)
+	b = order.AppendUint32(b, 8)
+	b = order.AppendUint16(b, uint16(entries))
+	b = order.AppendUint16(b, 0x010f) // make
+	b = order.AppendUint16(b, 2)      // ASCII
+	b = order.AppendUint32(b, uint32(len(make_)))
+	b = order.AppendUint32(b, uint32(8+2+entries*12+4))
+	if orientation != 0 {
+		b = order.AppendUint16(b, orientationTag)
+		b = order.AppendUint16(b, 3) // SHORT
+		b = order.AppendUint32(b, 1)
+		b = order.AppendUint16(b, uint16(orientation))
+		b = order.AppendUint16(b, 0)
+	}
+	b = order.AppendUint32(b, 0) // next IFD
+	return append(b, make_...)
+}
+
+var (
+	testXMP = []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + secret + `</x:xmpmeta>`)
+	testICC = append([]byte(secret), make([]byte, 128)...)
+)
+
+func jpegSegment(marker byte, parts ...[]byte) []byte {
+	return appendJPEGSegment(nil, marker, parts...)
+}
+
+// testJPEG encodes a JPEG image with segments inserted after the start of image marker.
+func testJPEG(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+
+	return mediatest.JPEG(t, mediatest.Image(8, 4), segments...)
+}
+
+// testPNG encodes a PNG image with chunks inserted after the header.
+func testPNG(t *testing.T, chunks ...[]byte) []byte {
+	t.Helper()
+
+	return mediatest.PNG(t, mediatest.Image(8, 4), chunks...)
+}
+
+// testWebP builds an extended format WebP image with flags and chunks, the image data is not valid.
+func testWebP(flags byte, chunks ...[]byte) []byte {
+	chunks = append([][]byte{mediatest.WebPChunk("VP8X", []byte{flags, 0, 0, 0, 7, 0, 0, 3, 0, 0})}, chunks...)
+	return mediatest.WebP(append(chunks, mediatest.WebPChunk("VP8L", []byte{0x2f, 0, 0, 0, 0, 0}))...)
+}
+
+// webpChunks returns the types of the chunks of a WebP image and its VP8X flags, it checks the RIFF size.
+func webpChunks(t *testing.T, b []byte) ([]string, byte) {
+	t.Helper()
+
+	if size := int(binary.LittleEndian.Uint32(b[4:])); size != len(b)-8 {
+		t.Fatalf("got riff size %d, want %d", size, len(b)-8)
+	}
+
+	var (
+		types []string
+		flags byte
+	)
+	for off := 12; off < len(b); {
+		type_, size := string(b[off:off+4]), int(binary.LittleEndian.Uint32(b[off+4:]))
+		if type_ == "VP8X" {
+			flags = b[off+8]
+		}
+
+		types = append(types, type_)
+		off += 8 + size + size%2
+	}
+
+	return types, flags
+}
+
+func TestStripJPEG(t *testing.T) {
+	var (
+		exif    = jpegSegment(0xe1, exifPrefix, testTIFF(binary.LittleEndian, 6))
+		xmp     = jpegSegment(0xe1, xmpPrefix, testXMP)
+		xmpExt  = jpegSegment(0xe1, xmpExtensionPrefix, testXMP)
+		icc     = jpegSegment(0xe2, iccPrefix, []byte{1, 1}, testICC)
+		comment = jpegSegment(0xfe, []byte("a comment"))
+		all     = []Kind{KindEXIF, KindXMP, KindICC}
+	)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		kinds       []Kind
+		want        []Kind
+		orientation int  // of the kept EXIF metadata, 0 if there should be none
+		keepSecret  bool // whether metadata with the secret is kept
+	}{
+		{name: "all", data: testJPEG(t, exif, xmp, xmpExt, icc, comment), kinds: all, want: all, orientation: 6},
+		{name: "exif only", data: testJPEG(t, exif, xmp, icc), kinds: []Kind{KindEXIF}, want: []Kind{KindEXIF}, orientation: 6, keepSecret: true},
+		{name: "icc only", data: testJPEG(t, icc), kinds: all, want: []Kind{KindICC}},
+		{
+			name:        "big-endian exif",
+			data:        testJPEG(t, jpegSegment(0xe1, exifPrefix, testTIFF(binary.BigEndian, 8))),
+			kinds:       all,
+			want:        []Kind{KindEXIF},
+			orientation: 8,
+		},
+		{name: "default orientation", data: testJPEG(t, jpegSegment(0xe1, exifPrefix, testTIFF(binary.LittleEndian, 1))), kinds: all, want: []Kind{KindEXIF}},
+		{name: "no orientation", data: testJPEG(t, jpegSegment(0xe1, exifPrefix, testTIFF(binary.LittleEndian, 0))), kinds: all, want: []Kind{KindEXIF}},
+		{name: "fill bytes", data: testJPEG(t, []byte{0xff, 0xff}, exif), kinds: all, want: []Kind{KindEXIF}, orientation: 6},
+		{name: "nothing to strip", data: testJPEG(t, comment), kinds: all},
+		{name: "kinds not stripped", data: testJPEG(t, xmp), kinds: []Kind{KindEXIF}, keepSecret: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, removed, err := Strip(tt.data, "image/jpeg", tt.kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(removed, tt.want) {
+				t.Errorf("got removed %v, want %v", removed, tt.want)
+			}
+			if len(tt.want) == 0 && !bytes.Equal(b, tt.data) {
+				t.Error("image changed without removing anything")
+			}
+
+			if _, err := jpeg.Decode(bytes.NewReader(b)); err != nil {
+				t.Errorf("stripped image can't be decoded: %v", err)
+			}
+			if bytes.Contains(b, []byte(secret)) != tt.keepSecret {
+				t.Errorf("secret kept %t, want %t", !tt.keepSecret, tt.keepSecret)
+			}
+			if bytes.Contains(tt.data, []byte("a comment")) && !bytes.Contains(b, []byte("a comment")) {
+				t.Error("comment removed")
+			}
+
+			if tt.orientation != 0 {
+				want := jpegSegment(0xe1, exifPrefix, minimalEXIF(tt.orientation))
+				if !bytes.Contains(b, want) {
+					t.Errorf("minimal exif with orientation %d missing", tt.orientation)
+				}
+			} else if len(tt.want) > 0 && bytes.Contains(b, exifPrefix) {
+				t.Error("exif kept")
+			}
+		})
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	var (
+		exif    = mediatest.PNGChunk("eXIf", testTIFF(binary.BigEndian, 3))
+		icc     = mediatest.PNGChunk("iCCP", []byte("profile\x00\x00"), testICC)
+		xmp     = mediatest.PNGChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), testXMP)
+		raw     = mediatest.PNGChunk("zTXt", []byte("Raw profile type exif\x00\x00"), []byte(secret))
+		text    = mediatest.PNGChunk("tEXt", []byte("Comment\x00a comment"))
+		all     = []Kind{KindEXIF, KindXMP, KindICC}
+		minimal = mediatest.PNGChunk("eXIf", minimalEXIF(3))
+	)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		kinds      []Kind
+		want       []Kind
+		keep       []byte // a chunk expected in the stripped image
+		keepSecret bool
+	}{
+		{name: "all", data: testPNG(t, exif, icc, xmp, raw, text), kinds: all, want: all, keep: minimal},
+		{name: "raw profile", data: testPNG(t, raw), kinds: all, want: []Kind{KindEXIF}},
+		{name: "prefixed exif", data: testPNG(t, mediatest.PNGChunk("eXIf", exifPrefix, testTIFF(binary.LittleEndian, 3))), kinds: all, want: []Kind{KindEXIF}, keep: minimal},
+		{name: "xmp only", data: testPNG(t, exif, xmp), kinds: []Kind{KindXMP}, want: []Kind{KindXMP}, keep: exif, keepSecret: true},
+		{name: "text kept", data: testPNG(t, text), kinds: all, keep: text},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, removed, err := Strip(tt.data, "image/png", tt.kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(removed, tt.want) {
+				t.Errorf("got removed %v, want %v", removed, tt.want)
+			}
+
+			// the decoder verifies the checksums of all chunks
+			if _, err := png.Decode(bytes.NewReader(b)); err != nil {
+				t.Errorf("stripped image can't be decoded: %v", err)
+			}
+			if bytes.Contains(b, []byte(secret)) != tt.keepSecret {
+				t.Errorf("secret kept %t, want %t", !tt.keepSecret, tt.keepSecret)
+			}
+			if tt.keep != nil && !bytes.Contains(b, tt.keep) {
+				t.Errorf("chunk %q missing", tt.keep[4:8])
+			}
+		})
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	var (
+		exif = mediatest.WebPChunk("EXIF", testTIFF(binary.LittleEndian, 0))
+		xmp  = mediatest.WebPChunk("XMP ", testXMP)
+		icc  = mediatest.WebPChunk("ICCP", testICC)
+		anim = mediatest.WebPChunk("ANIM", make([]byte, 6))
+		all  = []Kind{KindEXIF, KindXMP, KindICC}
+	)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		kinds     []Kind
+		want      []Kind
+		wantTypes []string
+		wantFlags byte
+	}{
+		{
+			name:      "all",
+			data:      testWebP(webpFlagICC|webpFlagEXIF|webpFlagXMP|0x02, icc, anim, exif, xmp),
+			kinds:     all,
+			want:      all,
+			wantTypes: []string{"VP8X", "ANIM", "VP8L"},
+			wantFlags: 0x02,
+		},
+		{
+			name:      "orientation",
+			data:      testWebP(webpFlagEXIF|webpFlagXMP, mediatest.WebPChunk("EXIF", append(append([]byte(nil), exifPrefix...), testTIFF(binary.BigEndian, 5)...)), xmp),
+			kinds:     all,
+			want:      []Kind{KindEXIF, KindXMP},
+			wantTypes: []string{"VP8X", "EXIF", "VP8L"},
+			wantFlags: webpFlagEXIF,
+		},
+		{
+			name:      "icc only",
+			data:      testWebP(webpFlagICC|webpFlagXMP, icc, xmp),
+			kinds:     []Kind{KindICC},
+			want:      []Kind{KindICC},
+			wantTypes: []string{"VP8X", "XMP ", "VP8L"},
+			wantFlags: webpFlagXMP,
+		},
+		{
+			name:      "odd chunk size",
+			data:      testWebP(webpFlagXMP, mediatest.WebPChunk("XMP ", []byte(secret)), anim),
+			kinds:     all,
+			want:      []Kind{KindXMP},
+			wantTypes: []string{"VP8X", "ANIM", "VP8L"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, removed, err := Strip(tt.data, "image/webp", tt.kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(removed, tt.want) {
+				t.Errorf("got removed %v, want %v", removed, tt.want)
+			}
+
+			types, flags := webpChunks(t, b)
+			if !slices.Equal(types, tt.wantTypes) || flags != tt.wantFlags {
+				t.Errorf("got chunks %q with flags %#x, want %q with flags %#x", types, flags, tt.wantTypes, tt.wantFlags)
+			}
+			if slices.Equal(tt.kinds, all) && bytes.Contains(b, []byte(secret)) {
+				t.Error("secret kept")
+			}
+		})
+	}
+
+	// the orientation is kept in a minimal EXIF chunk
+	b, _, _ := Strip(tests[1].data, "image/webp", all)
+	if !bytes.Contains(b, appendWebPChunk(nil, "EXIF", minimalEXIF(5))) {
+		t.Error("minimal exif missing")
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	all := []Kind{KindEXIF, KindXMP, KindICC}
+
+	tests := []struct {
+		name     string
+		mimeType string
+		data     []byte
+	}{
+		{name: "not a jpeg", mimeType: "image/jpeg", data: []byte("\x89PNG")},
+		{name: "jpeg invalid marker", mimeType: "image/jpeg", data: []byte("\xff\xd8\x00\x00")},
+		{name: "jpeg short segment length", mimeType: "image/jpeg", data: []byte("\xff\xd8\xff\xe1\x00\x01")},
+		{name: "jpeg oversized segment", mimeType: "image/jpeg", data: []byte("\xff\xd8\xff\xe1\xff\xff")},
+		{name: "jpeg without scan", mimeType: "image/jpeg", data: []byte("\xff\xd8\xff\xfe\x00\x02")},
+		{name: "not a png", mimeType: "image/png", data: []byte("\xff\xd8")},
+		{name: "png oversized chunk", mimeType: "image/png", data: append(append([]byte(nil), pngSignature...), "\xff\xff\xff\xffeXIf\x00\x00\x00\x00"...)},
+		{name: "png truncated chunk", mimeType: "image/png", data: append(append([]byte(nil), pngSignature...), "\x00\x00\x00\x00IE"...)},
+		{name: "not a webp", mimeType: "image/webp", data: []byte("RIFF\x00\x00\x00\x00WAVE")},
+		{name: "webp oversized chunk", mimeType: "image/webp", data: []byte("RIFF\x00\x00\x00\x00WEBPEXIF\xff\xff\xff\x7f")},
+		{name: "webp truncated chunk header", mimeType: "image/webp", data: []byte("RIFF\x00\x00\x00\x00WEBPEXIF")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Strip(tt.data, tt.mimeType, all); err == nil || errors.Is(err, ErrUnsupported) {
+				t.Errorf("got error %v, want a parsing error", err)
+			}
+		})
+	}
+
+	if _, _, err := Strip([]byte("GIF89a"), "image/gif", all); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("got error %v, want ErrUnsupported", err)
+	}
+}
+
+// TestStripTruncated strips every prefix of images with metadata, they must not panic or leak the metadata.
+func TestStripTruncated(t *testing.T) {
+	var (
+		all  = []Kind{KindEXIF, KindXMP, KindICC}
+		data = map[string][]byte{
+			"image/jpeg": testJPEG(t, jpegSegment(0xe1, exifPrefix, testTIFF(binary.LittleEndian, 6)), jpegSegment(0xe2, iccPrefix, testICC)),
+			"image/png":  testPNG(t, mediatest.PNGChunk("eXIf", testTIFF(binary.BigEndian, 3)), mediatest.PNGChunk("iCCP", []byte("p\x00\x00"), testICC)),
+			"image/webp": testWebP(webpFlagEXIF|webpFlagICC, mediatest.WebPChunk("ICCP", testICC), mediatest.WebPChunk("EXIF", testTIFF(binary.LittleEndian, 6))),
+		}
+	)
+	for mimeType, b := range data {
+		for n := 0; n < len(b); n++ {
+			res, _, err := Strip(b[:n], mimeType, all)
+			if err == nil && bytes.Contains(res, []byte(secret)) {
+				t.Errorf("%s prefix of %d bytes stripped with the secret kept", mimeType, n)
+			}
+		}
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	valid := testTIFF(binary.LittleEndian, 6)
+
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{name: "little-endian", tiff: valid, want: 6},
+		{name: "big-endian", tiff: testTIFF(binary.BigEndian, 8), want: 8},
+		{name: "missing", tiff: testTIFF(binary.LittleEndian, 0)},
+		{name: "out of range", tiff: testTIFF(binary.LittleEndian, 9)},
+		{name: "empty", tiff: nil},
+		{name: "bad byte order", tiff: append([]byte("XX"), valid[2:]...)},
+		{name: "bad magic", tiff: append([]byte("II\x2b\x00"), valid[4:]...)},
+		{name: "ifd offset out of bounds", tiff: append([]byte("II\x2a\x00\xff\xff\x00\x00"), valid[8:]...)},
+		{name: "ifd offset in the header", tiff: append([]byte("II\x2a\x00\x02\x00\x00\x00"), valid[8:]...)},
+		{name: "truncated entries", tiff: valid[:20]},
+		{name: "entry count overflow", tiff: append(append([]byte(nil), valid[:8]...), 0xff, 0xff)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orientation(tt.tiff); got != tt.want {
+				t.Errorf("got orientation %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := orientation(minimalEXIF(7)); got != 7 {
+		t.Errorf("minimal exif has orientation %d, want 7", got)
+	}
+	if minimalEXIF(1) != nil {
+		t.Error("minimal exif created for the default orientation")
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, k := range Kinds {
+		if k0, ok := ParseKind(string(k)); !ok || k0 != k {
+			t.Errorf("kind %s not parsed", k)
+		}
+	}
+	if _, ok := ParseKind("gps"); ok {
+		t.Error("unknown kind parsed")
+	}
+}
+
+// TestAppendPNGChunk checks the checksums written by appendPNGChunk.
+func TestAppendPNGChunk(t *testing.T) {
+	c := appendPNGChunk(nil, "tEXt", []byte("a\x00b"))
+	if got, want := binary.BigEndian.Uint32(c[len(c)-4:]), crc32.ChecksumIEEE(c[4:len(c)-4]); got != want {
+		t.Errorf("got checksum %#x, want %#x", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/zlataovce/nero/internal/errors"
+)
+
+// Flags of the VP8X chunk of WebP images, signalling the presence of metadata chunks.
+const (
+	webpFlagICC  = 0x20
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP removes the metadata chunks of a WebP image and clears their flags in the extended format header,
+// images in the simple format have no metadata.
+func (s *stripper) stripWebP(b []byte) ([]byte, error) {
+	if len(b) < 12 || !bytes.Equal(b[:4], []byte("RIFF")) || !bytes.Equal(b[8:12], []byte("WEBP")) {
+		return nil, errors.New("not a webp image")
+	}
+
+	var (
+		res   = append(make([]byte, 0, len(b)), b[:12]...)
+		vp8x  = -1 // offset of the VP8X chunk data in res
+		flags byte // of the removed chunks
+	)
+	for off := 12; off < len(b); {
+		if off+8 > len(b) {
+			return nil, errTruncated
+		}
+
+		var (
+			type_ = string(b[off : off+4])
+			size  = int(binary.LittleEndian.Uint32(b[off+4:]))
+		)
+		if size < 0 || off+8+size > len(b) {
+			return nil, errTruncated
+		}
+
+		end := off + 8 + size
+		if size%2 != 0 && end < len(b) {
+			end++ // padding
+		}
+
+		var (
+			chunk = b[off:end]
+			data  = chunk[8 : 8+size]
+			drop  bool
+		)
+		off = end
+
+		switch type_ {
+		case "VP8X":
+			vp8x = len(res) + 8
+		case "EXIF":
+			if drop = s.strip(KindEXIF); drop {
+				if tiff := minimalEXIF(orientation(bytes.TrimPrefix(data, exifPrefix))); tiff != nil {
+					res = appendWebPChunk(res, "EXIF", tiff)
+				} else {
+					flags |= webpFlagEXIF
+				}
+			}
+		case "XMP ":
+			if drop = s.strip(KindXMP); drop {
+				flags |= webpFlagXMP
+			}
+		case "ICCP":
+			if drop = s.strip(KindICC); drop {
+				flags |= webpFlagICC
+			}
+		}
+		if !drop {
+			res = append(res, chunk...)
+		}
+	}
+
+	if vp8x >= 0 && vp8x < len(res) {
+		res[vp8x] &^= flags
+	}
+	binary.LittleEndian.PutUint32(res[4:], uint32(len(res)-8))
+
+	return res, nil
+}
+
+// appendWebPChunk appends a chunk with a type and data, padded to an even size.
+func appendWebPChunk(b []byte, type_ string, data []byte) []byte {
+	b = append(b, type_...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	b = append(b, data...)
+	if len(data)%2 != 0 {
+		b = append(b, 0)
+	}
+
+	return b
+}
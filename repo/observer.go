@@ -0,0 +1,31 @@
+package repo
+
+import "time"
+
+// Observer receives measurements of repository operations, i.e. for metrics.
+// Observers are called synchronously, so they should not block.
+type Observer interface {
+	// ObserveCreate is called after media has been created from data of a size in bytes.
+	ObserveCreate(repo string, size int)
+	// ObserveSave is called after the index file has been saved, err is the outcome.
+	ObserveSave(repo string, d time.Duration, err error)
+	// ObserveCall is called on a query method call, with the name of the method ("find" or "random").
+	ObserveCall(repo, method string)
+}
+
+// Observe registers an observer of repository operations.
+func (r *Repository) Observe(o Observer) {
+	r.listenersMu.Lock()
+	defer r.listenersMu.Unlock()
+
+	r.observers = append(r.observers, o)
+}
+
+func (r *Repository) observe(fn func(o Observer)) {
+	r.listenersMu.RLock()
+	defer r.listenersMu.RUnlock()
+
+	for _, o := range r.observers {
+		fn(o)
+	}
+}
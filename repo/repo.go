@@ -2,29 +2,65 @@ package repo
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/zlataovce/nero/internal/errors"
 	"github.com/zlataovce/nero/repo/media"
 	"github.com/zlataovce/nero/repo/media/meta"
+	"github.com/zlataovce/nero/repo/media/placeholder"
+	"github.com/zlataovce/nero/repo/media/probe"
+	"github.com/zlataovce/nero/repo/media/sanitize"
 	mime "github.com/gabriel-vasile/mimetype"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	// AuthKey is an authentication key metadata key.
 	AuthKey = "auth_key"
+	// FormatKey is a metadata key overriding the format of the repository, see Repository.Format.
+	FormatKey = "format"
 )
 
+// writableCheckInterval is the time a result of Repository.CheckWritable is reused for.
+const writableCheckInterval = 30 * time.Second
+
+// KnownMetaKeys are the repository metadata keys understood by nero.
+var KnownMetaKeys = []string{AuthKey, FormatKey}
+
 // Metadata is repository metadata.
 type Metadata map[string]string
 
+// Validate checks the metadata for unknown keys and malformed values, returns the problems by offending key.
+func (m Metadata) Validate() map[string]error {
+	problems := make(map[string]error)
+	for k, v := range m {
+		switch k {
+		case FormatKey:
+			if _, ok := media.ParseFormat(v); !ok {
+				problems[k] = fmt.Errorf("unknown format %s, expected image, animated_image or video", v)
+			}
+		case AuthKey:
+		default:
+			problems[k] = errors.New("unknown metadata key")
+		}
+	}
+
+	return problems
+}
+
 // Value looks up a metadata value by key, returns false if the lookup failed.
 func (m Metadata) Value(key string) (string, bool) {
 	if m == nil {
@@ -35,14 +71,103 @@ func (m Metadata) Value(key string) (string, bool) {
 	return v, ok
 }
 
+// Thumbnail is a thumbnail preset of a repository, see derivative.Options.
+type Thumbnail struct {
+	// Width is the requested width in pixels, it is derived from the aspect ratio if 0.
+	Width int
+	// Height is the requested height in pixels, it is derived from the aspect ratio if 0.
+	Height int
+	// Fit is the way the image is fitted into the requested size.
+	Fit string
+}
+
+// Limits are the restrictions of media created in a repository, zero values mean no restriction.
+type Limits struct {
+	// MIMETypes are the allowed MIME types, i.e. image/png, or type wildcards, i.e. image/*.
+	MIMETypes []string
+	// Formats are the allowed media formats.
+	Formats []media.Format
+	// MaxSize is the maximum file size in bytes.
+	MaxSize int64
+	// MaxWidth is the maximum width in pixels.
+	MaxWidth int
+	// MaxHeight is the maximum height in pixels.
+	MaxHeight int
+	// MinWidth is the minimum width in pixels.
+	MinWidth int
+	// MinHeight is the minimum height in pixels.
+	MinHeight int
+}
+
+// Equal checks whether the limits are the same as other limits.
+func (l Limits) Equal(other Limits) bool {
+	return slices.Equal(l.MIMETypes, other.MIMETypes) && slices.Equal(l.Formats, other.Formats) &&
+		l.MaxSize == other.MaxSize && l.MaxWidth == other.MaxWidth && l.MaxHeight == other.MaxHeight &&
+		l.MinWidth == other.MinWidth && l.MinHeight == other.MinHeight
+}
+
+// checkType checks whether media of a MIME type and a format are allowed.
+func (l *Limits) checkType(mimeType string, format media.Format) error {
+	if len(l.MIMETypes) > 0 && !slices.ContainsFunc(l.MIMETypes, func(t string) bool {
+		prefix, ok := strings.CutSuffix(t, "*")
+		return t == mimeType || (ok && strings.HasPrefix(mimeType, prefix))
+	}) {
+		return fmt.Errorf("%w: %s", ErrTypeNotAllowed, mimeType)
+	}
+	if len(l.Formats) > 0 && !slices.Contains(l.Formats, format) {
+		return fmt.Errorf("%w: %s (%s)", ErrTypeNotAllowed, format, mimeType)
+	}
+
+	return nil
+}
+
+// checkDimensions checks whether media of dimensions are allowed, unknown dimensions are only allowed without
+// dimension limits.
+func (l *Limits) checkDimensions(d *media.Dimensions) error {
+	if l.MaxWidth <= 0 && l.MaxHeight <= 0 && l.MinWidth <= 0 && l.MinHeight <= 0 {
+		return nil
+	}
+	if d == nil {
+		return fmt.Errorf("%w: unknown dimensions", ErrDimensionsNotAllowed)
+	}
+
+	switch {
+	case l.MaxWidth > 0 && d.Width > l.MaxWidth:
+		return fmt.Errorf("%w: width %d, at most %d allowed", ErrDimensionsNotAllowed, d.Width, l.MaxWidth)
+	case l.MaxHeight > 0 && d.Height > l.MaxHeight:
+		return fmt.Errorf("%w: height %d, at most %d allowed", ErrDimensionsNotAllowed, d.Height, l.MaxHeight)
+	case d.Width < l.MinWidth:
+		return fmt.Errorf("%w: width %d, at least %d required", ErrDimensionsNotAllowed, d.Width, l.MinWidth)
+	case d.Height < l.MinHeight:
+		return fmt.Errorf("%w: height %d, at least %d required", ErrDimensionsNotAllowed, d.Height, l.MinHeight)
+	}
+
+	return nil
+}
+
 // Repository is a media repository.
 type Repository struct {
 	id, path, lockPath string
-	meta               Metadata
 	logger             *zap.Logger
 
-	items map[uuid.UUID]*media.Media
-	mu    sync.RWMutex
+	meta          Metadata
+	thumbnails    map[string]Thumbnail
+	limits        Limits
+	stripMetadata []sanitize.Kind
+	metaMu        sync.RWMutex
+
+	items   map[uuid.UUID]*media.Media
+	formats map[media.Format]int // item count by format
+	closed  bool
+	mu      sync.RWMutex
+
+	listeners   []Listener
+	observers   []Observer
+	listenersMu sync.RWMutex
+
+	writableErr error
+	writableAt  time.Time // of the last writability check
+	writableMu  sync.Mutex
 }
 
 // NewMemory creates a Repository without a backing lock file and storage directory.
@@ -109,7 +234,10 @@ func NewFile(id, path, lockPath string, meta Metadata, logger *zap.Logger) (*Rep
 				absPath = filepath.Join(path, m.Path)
 			}
 
-			if _, err := os.Stat(absPath); errors.Is(err, os.ErrNotExist) {
+			var size int64
+			if fi, err := os.Stat(absPath); err == nil {
+				size = fi.Size()
+			} else if errors.Is(err, os.ErrNotExist) {
 				logger.Warn(
 					"missing item in index",
 					zap.String("repo", id),
@@ -118,11 +246,22 @@ func NewFile(id, path, lockPath string, meta Metadata, logger *zap.Logger) (*Rep
 				continue
 			}
 
+			for _, d := range m.Derivatives {
+				if !filepath.IsAbs(d.Path) {
+					d.Path = filepath.Join(path, d.Path)
+				}
+			}
+
 			items[m.ID] = &media.Media{
-				ID:     m.ID,
-				Format: m.Format,
-				Path:   absPath,
-				Meta:   m.Meta,
+				ID:               m.ID,
+				Format:           m.Format,
+				Path:             absPath,
+				Size:             size,
+				Meta:             m.Meta,
+				Dimensions:       m.Dimensions,
+				Placeholder:      m.Placeholder,
+				Derivatives:      m.Derivatives,
+				StrippedMetadata: m.StrippedMetadata,
 			}
 		}
 
@@ -131,6 +270,11 @@ func NewFile(id, path, lockPath string, meta Metadata, logger *zap.Logger) (*Rep
 		}
 	}
 
+	formats := make(map[media.Format]int)
+	for _, m := range items {
+		formats[m.Format]++
+	}
+
 	return &Repository{
 		id:       id,
 		path:     path,
@@ -138,6 +282,7 @@ func NewFile(id, path, lockPath string, meta Metadata, logger *zap.Logger) (*Rep
 		meta:     meta,
 		logger:   logger,
 		items:    items,
+		formats:  formats,
 	}, err
 }
 
@@ -165,9 +310,108 @@ func (r *Repository) Memory() bool {
 
 // Meta returns the repository metadata, may be nil.
 func (r *Repository) Meta() Metadata {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+
 	return r.meta
 }
 
+// SetMeta replaces the repository metadata, i.e. to rotate the authentication key.
+func (r *Repository) SetMeta(meta Metadata) {
+	r.metaMu.Lock()
+	defer r.metaMu.Unlock()
+
+	r.meta = meta
+}
+
+// Thumbnails returns the thumbnail presets of the repository by name, may be nil.
+func (r *Repository) Thumbnails() map[string]Thumbnail {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+
+	return r.thumbnails
+}
+
+// SetThumbnails replaces the thumbnail presets of the repository, existing thumbnails are not regenerated.
+func (r *Repository) SetThumbnails(thumbnails map[string]Thumbnail) {
+	r.metaMu.Lock()
+	defer r.metaMu.Unlock()
+
+	r.thumbnails = thumbnails
+}
+
+// Limits returns the restrictions of media created in the repository.
+func (r *Repository) Limits() Limits {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+
+	return r.limits
+}
+
+// SetLimits replaces the restrictions of media created in the repository, existing media are kept.
+func (r *Repository) SetLimits(limits Limits) {
+	r.metaMu.Lock()
+	defer r.metaMu.Unlock()
+
+	r.limits = limits
+}
+
+// StripMetadata returns the kinds of metadata stripped from media created in the repository, may be nil.
+func (r *Repository) StripMetadata() []sanitize.Kind {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+
+	return r.stripMetadata
+}
+
+// SetStripMetadata replaces the kinds of metadata stripped from media created in the repository,
+// existing media are kept.
+func (r *Repository) SetStripMetadata(kinds []sanitize.Kind) {
+	r.metaMu.Lock()
+	defer r.metaMu.Unlock()
+
+	r.stripMetadata = kinds
+}
+
+// CheckWritable checks whether the storage directory and the lock file directory of the repository are writable
+// by creating and removing a probe file in them, it is a no-op for in-memory repositories.
+// The result is reused for writableCheckInterval, so that frequent readiness probes don't keep the disk busy.
+func (r *Repository) CheckWritable() error {
+	if r.Memory() {
+		return nil
+	}
+
+	r.writableMu.Lock()
+	defer r.writableMu.Unlock()
+
+	if now := time.Now(); now.Sub(r.writableAt) >= writableCheckInterval {
+		r.writableErr, r.writableAt = r.checkWritable(), now
+	}
+
+	return r.writableErr
+}
+
+func (r *Repository) checkWritable() error {
+	dirs := []string{r.path}
+	if lockDir := filepath.Dir(r.lockPath); lockDir != r.path {
+		dirs = append(dirs, lockDir)
+	}
+
+	for _, dir := range dirs {
+		f, err := os.CreateTemp(dir, ".nero-probe-*")
+		if err != nil {
+			return errors.Wrap(err, "failed to create probe file")
+		}
+
+		err = multierr.Append(f.Close(), os.Remove(f.Name()))
+		if err != nil {
+			return errors.Wrap(err, "failed to remove probe file")
+		}
+	}
+
+	return nil
+}
+
 // Get tries to find media by its ID, returns nil if nothing was found.
 func (r *Repository) Get(id uuid.UUID) *media.Media {
 	r.mu.RLock()
@@ -179,11 +423,23 @@ func (r *Repository) Get(id uuid.UUID) *media.Media {
 	return r.items[id]
 }
 
-// Find tries to find media by a metadata query (meta.Matchable) and a format, returns nil if nothing was found.
-// Supplying media.FormatUnknown means any format should be accepted.
-func (r *Repository) Find(query string, format media.Format, amount int) []*media.Media {
+// Find tries to find media by a metadata query (meta.Matchable), a format and the name of its dominant color
+// (see placeholder.ColorName), returns nil if nothing was found.
+// Supplying media.FormatUnknown means any format should be accepted, an empty color means any color.
+func (r *Repository) Find(ctx context.Context, query string, format media.Format, color string, amount int) []*media.Media {
+	r.observe(func(o Observer) { o.ObserveCall(r.id, "find") })
+
+	_, span := tracer.Start(ctx, "Repository.Find", trace.WithAttributes(
+		attrRepo.String(r.id),
+		attribute.String("nero.query", query),
+		attribute.String("nero.color", color),
+		attribute.Int("nero.amount", amount),
+	))
+	defer span.End()
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	span.AddEvent("acquired index lock")
 
 	if r.items == nil {
 		return nil
@@ -199,6 +455,10 @@ func (r *Repository) Find(query string, format media.Format, amount int) []*medi
 			continue
 		}
 
+		if color != "" && (m.Placeholder == nil || placeholder.ColorName(m.Placeholder.DominantColor) != color) { // color mismatch
+			continue
+		}
+
 		if m.Meta == nil { // no meta to match against
 			continue
 		}
@@ -211,16 +471,29 @@ func (r *Repository) Find(query string, format media.Format, amount int) []*medi
 		res = append(res, m)
 	}
 
+	span.SetAttributes(attribute.Int("nero.results", len(res)))
 	return res
 }
 
-// Random picks N random media out of the repository.
-func (r *Repository) Random(n int) []*media.Media {
+// Random picks N random media out of the repository, skipping media of the excluded formats.
+func (r *Repository) Random(ctx context.Context, n int, exclude ...media.Format) []*media.Media {
+	r.observe(func(o Observer) { o.ObserveCall(r.id, "random") })
+
+	_, span := tracer.Start(ctx, "Repository.Random", trace.WithAttributes(
+		attrRepo.String(r.id),
+		attribute.Int("nero.amount", n),
+	))
+	defer span.End()
+
 	if n <= 0 {
 		return nil
 	}
 
 	v := r.Items()
+	span.AddEvent("acquired index lock")
+	if len(exclude) > 0 {
+		v = slices.DeleteFunc(v, func(m *media.Media) bool { return slices.Contains(exclude, m.Format) })
+	}
 	rand.Shuffle(len(v), func(i, j int) {
 		v[i], v[j] = v[j], v[i]
 	})
@@ -231,38 +504,42 @@ func (r *Repository) Random(n int) []*media.Media {
 	return v
 }
 
-// Create creates and inserts new media into the repository.
-// Returns errors.ErrUnsupported for repositories without a backing storage directory.
-func (r *Repository) Create(b []byte, m meta.Metadata) (*media.Media, error) {
+// Create creates and inserts new media into the repository, metadata of the kinds set by SetStripMetadata
+// are removed from images beforehand.
+// Returns errors.ErrUnsupported for repositories without a backing storage directory, ErrTooLarge,
+// ErrTypeNotAllowed or ErrDimensionsNotAllowed if the media is outside the limits of the repository (see Limits).
+// The written file is removed if the media can't be added to the index.
+func (r *Repository) Create(ctx context.Context, b []byte, m meta.Metadata) (_ *media.Media, err error) {
 	if r.path == "" {
 		return nil, errors.ErrUnsupported
 	}
+	if r.isClosed() { // don't write orphaned files
+		return nil, ErrClosed
+	}
 
-	var (
-		err error
+	ctx, span := tracer.Start(ctx, "Repository.Create", trace.WithAttributes(
+		attrRepo.String(r.id),
+		attribute.Int("nero.size", len(b)),
+	))
+	defer func() { endSpan(span, err) }()
 
+	limits := r.Limits()
+	if limits.MaxSize > 0 && int64(len(b)) > limits.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrTooLarge, len(b), limits.MaxSize)
+	}
+
+	var (
 		id    = uuid.New()
 		type_ = mime.Detect(b)
 		path  = filepath.Join(r.path, id.String()+type_.Extension())
 	)
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open file")
-	}
-	defer func() {
-		if err0 := f.Close(); err0 != nil {
-			err = multierr.Append(err, errors.Wrap(err0, "failed to close file"))
-		}
-	}()
-
-	if _, err = f.Write(b); err != nil {
-		return nil, errors.Wrap(err, "failed to write file")
-	}
+	span.SetAttributes(attribute.String("nero.mime", type_.String()))
 
 	m0 := &media.Media{
 		ID:     id,
 		Format: media.FormatUnknown,
 		Path:   path,
+		Size:   int64(len(b)),
 		Meta:   m,
 	}
 	switch type_.String() {
@@ -270,17 +547,279 @@ func (r *Repository) Create(b []byte, m meta.Metadata) (*media.Media, error) {
 		m0.Format = media.FormatImage
 	case "image/vnd.mozilla.apng", "image/gif", "image/webp":
 		m0.Format = media.FormatAnimatedImage
+	case "video/mp4", "video/webm":
+		m0.Format = media.FormatVideo
+	}
+	if err := limits.checkType(type_.String(), m0.Format); err != nil {
+		return nil, err
 	}
 
-	err = r.Add(m0)
-	return m0, err
+	if kinds := r.StripMetadata(); len(kinds) > 0 {
+		if b0, removed, err := sanitize.Strip(b, type_.String(), kinds); err == nil {
+			b, m0.Size = b0, int64(len(b0))
+			for _, k := range removed {
+				m0.StrippedMetadata = append(m0.StrippedMetadata, string(k))
+			}
+		} else if !errors.Is(err, sanitize.ErrUnsupported) { // the media is kept as-is, like media of other types
+			r.logger.Warn("failed to strip media metadata", zap.String("repo", r.id), zap.String("id", id.String()), zap.Error(err))
+		}
+	}
+
+	if d, err := probe.Probe(b, type_.String()); err == nil {
+		m0.Dimensions = d
+		span.SetAttributes(attribute.Int("nero.width", d.Width), attribute.Int("nero.height", d.Height))
+	} else if !errors.Is(err, probe.ErrUnsupported) { // dimensions are optional
+		r.logger.Warn("failed to probe media dimensions", zap.String("repo", r.id), zap.String("id", id.String()), zap.Error(err))
+	}
+	if err := limits.checkDimensions(m0.Dimensions); err != nil {
+		return nil, err
+	}
+
+	if m0.Format.Image() {
+		if p, err := placeholder.Compute(b); err == nil {
+			m0.Placeholder = p
+		} else { // placeholders are optional, i.e. animated WebP images can't be decoded
+			r.logger.Debug("failed to compute media placeholder", zap.String("repo", r.id), zap.String("id", id.String()), zap.Error(err))
+		}
+	}
+
+	if err = writeFile(ctx, path, b); err != nil {
+		return nil, err
+	}
+
+	if err = r.Add(ctx, m0); err != nil {
+		if err0 := os.Remove(path); err0 != nil && !errors.Is(err0, os.ErrNotExist) {
+			r.logger.Warn("failed to remove media file", zap.String("repo", r.id), zap.String("id", id.String()), zap.Error(err0))
+		}
+		return nil, err
+	}
+
+	r.observe(func(o Observer) { o.ObserveCreate(r.id, len(b)) })
+	return m0, nil
 }
 
 // Add inserts new media into the repository.
-func (r *Repository) Add(m *media.Media) error {
+func (r *Repository) Add(ctx context.Context, m *media.Media) error {
+	if err := r.add(ctx, m); err != nil {
+		return err
+	}
+
+	r.emit(EventCreate, m)
+	return nil
+}
+
+// Remove removes media from the repository by its ID, deleting the files of its derivatives.
+// The media file itself is kept.
+func (r *Repository) Remove(ctx context.Context, id uuid.UUID) error {
+	m, err := r.remove(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		for name, d := range m.Derivatives {
+			if err := os.Remove(d.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				r.logger.Warn(
+					"failed to delete derivative",
+					zap.String("repo", r.id),
+					zap.String("id", id.String()),
+					zap.String("derivative", name),
+					zap.Error(err),
+				)
+			}
+		}
+
+		r.emit(EventDelete, m)
+	}
+	return nil
+}
+
+// SetDerivatives replaces the derivatives of media, i.e. after generating its thumbnails.
+// Returns ErrNotFound if the media is not in the repository, i.e. if it was removed in the meantime.
+func (r *Repository) SetDerivatives(ctx context.Context, id uuid.UUID, derivatives map[string]*media.Derivative) error {
+	m, err := r.setDerivatives(ctx, id, derivatives)
+	if err != nil {
+		return err
+	}
+
+	r.emit(EventUpdate, m)
+	return nil
+}
+
+// Items returns all pieces of media in the repository.
+func (r *Repository) Items() []*media.Media {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return maps.Values(r.items)
+}
+
+// Formats returns the number of items in the repository by their format.
+func (r *Repository) Formats() map[media.Format]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return maps.Clone(r.formats)
+}
+
+// Format returns the format of the repository, which is the format overridden by the FormatKey metadata,
+// or the dominant format of its items. Returns media.FormatUnknown for repositories without known items.
+func (r *Repository) Format() media.Format {
+	if s, ok := r.Meta().Value(FormatKey); ok {
+		if f, ok := media.ParseFormat(s); ok {
+			return f
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dominant := media.FormatUnknown
+	for f, n := range r.formats {
+		if f == media.FormatUnknown {
+			continue
+		}
+
+		// ties are resolved to the lower format for a stable result
+		if n0 := r.formats[dominant]; dominant == media.FormatUnknown || n > n0 || (n == n0 && f < dominant) {
+			dominant = f
+		}
+	}
+
+	return dominant
+}
+
+// Backfill probes the dimensions and computes the placeholders of items without them, i.e. created by older versions,
+// and saves the index once if any were found. Items that can't be probed are skipped.
+// Returns the number of items updated.
+func (r *Repository) Backfill(ctx context.Context) (n int, err error) {
+	if r.lockPath == "" {
+		return 0, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "Repository.Backfill", trace.WithAttributes(attrRepo.String(r.id)))
+	defer func() {
+		span.SetAttributes(attribute.Int("nero.updated", n))
+		endSpan(span, err)
+	}()
+
+	type result struct {
+		dimensions  *media.Dimensions
+		placeholder *media.Placeholder
+	}
+
+	probed := make(map[uuid.UUID]result)
+	for _, m := range r.Items() {
+		if m.Dimensions != nil && (m.Placeholder != nil || !m.Format.Image()) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		b, err := os.ReadFile(m.Path)
+		if err != nil {
+			r.logger.Warn("failed to read media for probing", zap.String("repo", r.id), zap.String("id", m.ID.String()), zap.Error(err))
+			continue
+		}
+
+		res := result{dimensions: m.Dimensions, placeholder: m.Placeholder}
+		if res.dimensions == nil {
+			if res.dimensions, err = probe.Probe(b, mime.Detect(b).String()); err != nil && !errors.Is(err, probe.ErrUnsupported) {
+				r.logger.Warn("failed to probe media dimensions", zap.String("repo", r.id), zap.String("id", m.ID.String()), zap.Error(err))
+			}
+		}
+		if res.placeholder == nil && m.Format.Image() {
+			if res.placeholder, err = placeholder.Compute(b); err != nil {
+				r.logger.Debug("failed to compute media placeholder", zap.String("repo", r.id), zap.String("id", m.ID.String()), zap.Error(err))
+			}
+		}
+		if res.dimensions == m.Dimensions && res.placeholder == m.Placeholder { // nothing new
+			continue
+		}
+
+		probed[m.ID] = res
+	}
+	if len(probed) == 0 {
+		return 0, nil
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if r.closed {
+		return 0, ErrClosed
+	}
+	for id, res := range probed {
+		m, ok := r.items[id]
+		if !ok {
+			continue // removed in the meantime
+		}
+
+		// items are shared with readers, replace them instead of modifying them
+		m0 := *m
+		m0.Dimensions, m0.Placeholder = res.dimensions, res.placeholder
+		r.items[id] = &m0
+		n++
+	}
+
+	return n, r.save(ctx)
+}
+
+// Close waits for in-flight index writes, flushes the index file to stable storage
+// and rejects further modifications with ErrClosed.
+// The repository should not be used anymore after calling Close.
+func (r *Repository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if r.lockPath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(r.lockPath, os.O_WRONLY, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // nothing written yet
+		}
+
+		return errors.Wrap(err, "failed to open index file")
+	}
+
+	err = f.Sync()
+	if err0 := f.Close(); err0 != nil {
+		err = multierr.Append(err, errors.Wrap(err0, "failed to close index file"))
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to flush index file")
+	}
+
+	return nil
+}
+
+func (r *Repository) isClosed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.closed
+}
+
+func (r *Repository) add(ctx context.Context, m *media.Media) error {
+	ctx, span := tracer.Start(ctx, "Repository.add", trace.WithAttributes(attrRepo.String(r.id)))
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	span.AddEvent("acquired index lock")
+
+	if r.closed {
+		return ErrClosed
+	}
 	if r.items == nil {
 		r.items = make(map[uuid.UUID]*media.Media, 1)
 	} else if _, ok := r.items[m.ID]; ok {
@@ -289,39 +828,91 @@ func (r *Repository) Add(m *media.Media) error {
 			Repo: r.id,
 		}
 	}
+	if r.formats == nil {
+		r.formats = make(map[media.Format]int, 1)
+	}
 
 	r.items[m.ID] = m
-	return r.save()
+	r.formats[m.Format]++
+	if err := r.save(ctx); err != nil {
+		delete(r.items, m.ID) // not persisted, its file may be removed
+		if r.formats[m.Format]--; r.formats[m.Format] <= 0 {
+			delete(r.formats, m.Format)
+		}
+		return err
+	}
+
+	return nil
 }
 
-// Remove removes media from the repository by its ID.
-func (r *Repository) Remove(id uuid.UUID) error {
+func (r *Repository) remove(ctx context.Context, id uuid.UUID) (*media.Media, error) {
+	ctx, span := tracer.Start(ctx, "Repository.remove", trace.WithAttributes(attrRepo.String(r.id)))
+	defer span.End()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	span.AddEvent("acquired index lock")
+
+	if r.closed {
+		return nil, ErrClosed
+	}
+
+	m, ok := r.items[id]
+	if !ok {
+		return nil, nil
+	}
 
 	delete(r.items, id)
-	return r.save()
+	if r.formats[m.Format]--; r.formats[m.Format] <= 0 {
+		delete(r.formats, m.Format)
+	}
+	return m, r.save(ctx)
 }
 
-// Items returns all pieces of media in the repository.
-func (r *Repository) Items() []*media.Media {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+func (r *Repository) setDerivatives(ctx context.Context, id uuid.UUID, derivatives map[string]*media.Derivative) (*media.Media, error) {
+	ctx, span := tracer.Start(ctx, "Repository.setDerivatives", trace.WithAttributes(
+		attrRepo.String(r.id),
+		attribute.Int("nero.derivatives", len(derivatives)),
+	))
+	defer span.End()
 
-	return maps.Values(r.items)
-}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	span.AddEvent("acquired index lock")
 
-// Close cleans up after the repository.
-// The repository should not be used anymore after calling Close.
-func (r *Repository) Close() error {
-	return nil
+	if r.closed {
+		return nil, ErrClosed
+	}
+
+	m, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	// items are shared with readers, replace them instead of modifying them
+	m0 := *m
+	m0.Derivatives = derivatives
+	r.items[id] = &m0
+
+	return &m0, r.save(ctx)
 }
 
-func (r *Repository) save() (err error) {
+func (r *Repository) save(ctx context.Context) (err error) {
 	if r.lockPath == "" {
 		return nil
 	}
 
+	_, span := tracer.Start(ctx, "Repository.save", trace.WithAttributes(
+		attrRepo.String(r.id),
+		attribute.Int("nero.items", len(r.items)),
+	))
+	start := time.Now()
+	defer func() {
+		d := time.Since(start)
+		r.observe(func(o Observer) { o.ObserveSave(r.id, d, err) })
+		endSpan(span, err)
+	}()
+
 	if _, err := os.Stat(r.lockPath); err == nil {
 		if err = os.Rename(r.lockPath, r.lockPath+".old"); err != nil {
 			return errors.Wrap(err, "failed to move index file")
@@ -353,11 +944,27 @@ func (r *Repository) write(f *os.File, m *media.Media) error {
 		path = m.Path
 	}
 
+	var derivatives map[string]*media.Derivative
+	if len(m.Derivatives) > 0 {
+		derivatives = make(map[string]*media.Derivative, len(m.Derivatives))
+		for name, d := range m.Derivatives {
+			d0 := *d
+			if rel, err := filepath.Rel(r.path, d.Path); err == nil {
+				d0.Path = rel
+			}
+			derivatives[name] = &d0
+		}
+	}
+
 	b, err := json.Marshal(&media.Media{
-		ID:     m.ID,
-		Format: m.Format,
-		Path:   path,
-		Meta:   m.Meta,
+		ID:               m.ID,
+		Format:           m.Format,
+		Path:             path,
+		Meta:             m.Meta,
+		Dimensions:       m.Dimensions,
+		Placeholder:      m.Placeholder,
+		Derivatives:      derivatives,
+		StrippedMetadata: m.StrippedMetadata,
 	})
 	if err != nil {
 		return errors.Wrap(err, "failed to serialize index item")
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestRepo(t *testing.T, id string, meta Metadata) *Repository {
+	t.Helper()
+
+	dir := t.TempDir()
+	r, err := NewFile(id, filepath.Join(dir, id), filepath.Join(dir, id+".lock"), meta, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r
+}
+
+func TestCheckWritable(t *testing.T) {
+	r := newTestRepo(t, "pat", nil)
+	if err := r.CheckWritable(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.RemoveAll(r.Path()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckWritable(); err != nil {
+		t.Errorf("result not reused: %v", err)
+	}
+
+	r.writableAt = r.writableAt.Add(-writableCheckInterval - time.Second)
+	if err := r.CheckWritable(); err == nil {
+		t.Error("removed storage directory writable")
+	}
+}
+
+type testObserver struct {
+	creates int
+}
+
+func (o *testObserver) ObserveCreate(string, int)                { o.creates++ }
+func (o *testObserver) ObserveSave(string, time.Duration, error) {}
+func (o *testObserver) ObserveCall(string, string)               {}
+
+func TestCreateIndexFailure(t *testing.T) {
+	dir := t.TempDir()
+	indexDir := filepath.Join(dir, "index")
+	if err := os.Mkdir(indexDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewFile("pat", filepath.Join(dir, "pat"), filepath.Join(indexDir, "pat.lock"), nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	o := &testObserver{}
+	r.Observe(o)
+
+	// the index file can't be written
+	if err := os.RemoveAll(indexDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Create(context.Background(), mediatest.PNG(t, mediatest.Image(4, 4)), nil); err == nil {
+		t.Fatal("created media without an index")
+	}
+
+	if entries, _ := os.ReadDir(r.Path()); len(entries) != 0 {
+		t.Errorf("media files kept: %v", entries)
+	}
+	if len(r.Items()) != 0 || len(r.Formats()) != 0 {
+		t.Error("media kept in the index")
+	}
+	if o.creates != 0 {
+		t.Error("failed creation observed")
+	}
+
+	if err := os.Mkdir(indexDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m, err := r.Create(context.Background(), mediatest.PNG(t, mediatest.Image(4, 4)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Get(m.ID) == nil || o.creates != 1 {
+		t.Error("created media not added or observed")
+	}
+}
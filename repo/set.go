@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"fmt"
+	"golang.org/x/exp/maps"
+	"sync/atomic"
+)
+
+// Set is a set of repositories keyed by their ID, which can be replaced atomically while in use.
+type Set struct {
+	repos atomic.Pointer[map[string]*Repository]
+}
+
+// NewSet creates a set of repositories, IDs must be unique.
+func NewSet(repos ...*Repository) (*Set, error) {
+	s := &Set{}
+	if _, err := s.Swap(repos...); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get looks up a repository by its ID.
+func (s *Set) Get(id string) (*Repository, bool) {
+	r, ok := s.Map()[id]
+	return r, ok
+}
+
+// Map returns a snapshot of the set keyed by repository IDs, it must not be modified.
+func (s *Set) Map() map[string]*Repository {
+	if m := s.repos.Load(); m != nil {
+		return *m
+	}
+
+	return nil
+}
+
+// Values returns a snapshot of the repositories in the set.
+func (s *Set) Values() []*Repository {
+	return maps.Values(s.Map())
+}
+
+// Swap replaces the repositories in the set, IDs must be unique.
+// Returns the previous repositories keyed by their IDs, the set is left unchanged on error.
+func (s *Set) Swap(repos ...*Repository) (map[string]*Repository, error) {
+	m := make(map[string]*Repository, len(repos))
+	for _, r := range repos {
+		if _, ok := m[r.id]; ok {
+			return nil, fmt.Errorf("duplicate repository ID %s", r.id)
+		}
+
+		m[r.id] = r
+	}
+
+	old := s.repos.Swap(&m)
+	if old != nil {
+		return *old, nil
+	}
+	return nil, nil
+}
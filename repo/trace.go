@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"github.com/zlataovce/nero/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"os"
+)
+
+// tracer is the tracer of repository operations, a no-op until a global tracer provider is set.
+var tracer = otel.Tracer("github.com/zlataovce/nero/repo")
+
+// attrRepo is the span attribute key of the repository ID.
+const attrRepo = attribute.Key("nero.repo")
+
+// endSpan records an error to a span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// writeFile writes data to a new file in the storage directory.
+func writeFile(ctx context.Context, path string, b []byte) (err error) {
+	_, span := tracer.Start(ctx, "storage.write", trace.WithAttributes(
+		attribute.String("file.path", path),
+		attribute.Int("file.size", len(b)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	defer func() {
+		if err0 := f.Close(); err0 != nil {
+			err = multierr.Append(err, errors.Wrap(err0, "failed to close file"))
+		}
+	}()
+
+	if _, err = f.Write(b); err != nil {
+		return errors.Wrap(err, "failed to write file")
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"net/http"
+	"runtime/debug"
+)
+
+// AdminOptions are the options of an admin router.
+type AdminOptions struct {
+	// Gatherer is the source of metrics exposed at /metrics, the endpoint is disabled if nil.
+	Gatherer prometheus.Gatherer
+	// Ready is the readiness check of /readyz, the server is considered always ready if nil.
+	Ready func() error
+	// Pprof is whether the net/http/pprof profiling endpoints should be exposed under /debug/pprof.
+	Pprof bool
+}
+
+// BuildInfo is the build information of the running binary.
+type BuildInfo struct {
+	// Path is the main package path.
+	Path string `json:"path"`
+	// Version is the main module version.
+	Version string `json:"version"`
+	// GoVersion is the version of the Go toolchain that built the binary.
+	GoVersion string `json:"go_version"`
+	// Revision is the VCS revision, empty if not known.
+	Revision string `json:"revision,omitempty"`
+	// Time is the VCS revision time, empty if not known.
+	Time string `json:"time,omitempty"`
+	// Modified is whether the source tree had local modifications.
+	Modified bool `json:"modified"`
+}
+
+// ReadBuildInfo reads the build information embedded in the running binary.
+func ReadBuildInfo() *BuildInfo {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return &BuildInfo{Version: "(unknown)"}
+	}
+
+	info := &BuildInfo{
+		Path:      bi.Path,
+		Version:   bi.Main.Version,
+		GoVersion: bi.GoVersion,
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// NewAdminRouter creates a new router for operational endpoints, meant to be exposed on a separate, private listener.
+func NewAdminRouter(opts AdminOptions, logger *zap.Logger) (http.Handler, error) {
+	buildInfo, err := json.Marshal(ReadBuildInfo())
+	if err != nil {
+		return nil, err
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.Recoverer)
+
+	r.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	r.Get("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if opts.Ready != nil {
+			if err := opts.Ready(); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(err.Error() + "\n"))
+				return
+			}
+		}
+
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	r.Get("/buildinfo", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buildInfo)
+	})
+
+	if opts.Gatherer != nil {
+		r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(opts.Gatherer, promhttp.HandlerOpts{
+			ErrorLog: zap.NewStdLog(logger),
+		}))
+	}
+	if opts.Pprof {
+		r.Mount("/debug", middleware.Profiler())
+	}
+
+	return r, nil
+}
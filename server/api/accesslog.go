@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"time"
+)
+
+type accessEntryKey struct{}
+
+// accessEntry is the mutable part of an access log entry, filled in by handlers.
+type accessEntry struct {
+	key string
+}
+
+// AccessLog creates a middleware writing a structured access log entry for every request.
+// It should be used on the top-level router after middleware.RequestID and RealIP,
+// the route pattern and repository are resolved after routing.
+// Server errors are logged at the error level, so that they can be sampled separately.
+func AccessLog(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				start = time.Now()
+				ww    = middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+				entry = &accessEntry{}
+			)
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK // nothing was written
+				}
+
+				level := zapcore.InfoLevel
+				if status >= http.StatusInternalServerError {
+					level = zapcore.ErrorLevel
+				}
+
+				ce := logger.Check(level, "handled request")
+				if ce == nil {
+					return
+				}
+
+				fields := []zap.Field{
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Int("status", status),
+					zap.Int("bytes", ww.BytesWritten()),
+					zap.Duration("latency", time.Since(start)),
+					zap.String("request_id", middleware.GetReqID(r.Context())),
+					zap.String("client_ip", ClientIP(r)),
+				}
+				if rctx := chi.RouteContext(r.Context()); rctx != nil {
+					if pattern := rctx.RoutePattern(); pattern != "" {
+						fields = append(fields, zap.String("route", pattern))
+					}
+					if repoId := routeRepo(rctx); repoId != "" {
+						fields = append(fields, zap.String("repo", repoId))
+					}
+				}
+				if entry.key != "" {
+					fields = append(fields, zap.String("key", entry.key))
+				}
+				if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+					fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+				}
+
+				ce.Write(fields...)
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, entry)))
+		})
+	}
+}
+
+// SetAccessKey records the name of the key or token a request was authorized with in its access log entry.
+// It is a no-op if the request is not logged by AccessLog.
+func SetAccessKey(ctx context.Context, name string) {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.key = name
+	}
+}
+
+// routeRepo returns the repository ID path parameter of a routed request, empty if there is none.
+func routeRepo(rctx *chi.Context) string {
+	if repoId := rctx.URLParam("repo"); repoId != "" {
+		return repoId // nero API
+	}
+
+	return rctx.URLParam("category") // nekos API
+}
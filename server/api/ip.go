@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// RealIP creates a middleware resolving the client IP address of requests.
+// The X-Forwarded-For header is only honored if the request came from a trusted proxy,
+// in which case the rightmost untrusted address of the header is used.
+func RealIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveIP(r, trustedProxies)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// ClientIP returns the client IP address of a request, as resolved by RealIP.
+// Falls back to the remote address of the request if RealIP was not used.
+func ClientIP(r *http.Request) string {
+	if ip := ContextClientIP(r.Context()); ip != "" {
+		return ip
+	}
+
+	return remoteIP(r)
+}
+
+// ContextClientIP returns the client IP address stored in a request context by RealIP, empty if there is none.
+func ContextClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// ParsePrefixes parses IP addresses and CIDR networks into prefixes, addresses are treated as single-address networks.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "/") {
+			p, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, err
+			}
+
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+
+		a, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes = append(prefixes, netip.PrefixFrom(a, a.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+func resolveIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	ip := remoteIP(r)
+	if !trusted(ip, trustedProxies) {
+		return ip
+	}
+
+	// walk the forwarding chain from the closest hop, stop at the first untrusted address
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break // malformed header, don't trust anything further
+		}
+
+		ip = hop
+		if !trusted(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func trusted(ip string, trustedProxies []netip.Prefix) bool {
+	a, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	a = a.Unmap()
+	for _, p := range trustedProxies {
+		if p.Contains(a) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -98,7 +98,7 @@ type ClientInterface interface {
 	GetCategoryFiles(ctx context.Context, category string, params *GetCategoryFilesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// GetCategoryFile request
-	GetCategoryFile(ctx context.Context, category string, filename string, format string, reqEditors ...RequestEditorFn) (*http.Response, error)
+	GetCategoryFile(ctx context.Context, category string, filename string, format string, params *GetCategoryFileParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
 
 func (c *Client) GetCategories(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
@@ -137,8 +137,8 @@ func (c *Client) GetCategoryFiles(ctx context.Context, category string, params *
 	return c.Client.Do(req)
 }
 
-func (c *Client) GetCategoryFile(ctx context.Context, category string, filename string, format string, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewGetCategoryFileRequest(c.Server, category, filename, format)
+func (c *Client) GetCategoryFile(ctx context.Context, category string, filename string, format string, params *GetCategoryFileParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetCategoryFileRequest(c.Server, category, filename, format, params)
 	if err != nil {
 		return nil, err
 	}
@@ -254,6 +254,22 @@ func NewSearchRequest(server string, params *SearchParams) (*http.Request, error
 
 		}
 
+		if params.Color != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "color", runtime.ParamLocationQuery, *params.Color); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
 		queryURL.RawQuery = queryValues.Encode()
 	}
 
@@ -310,6 +326,22 @@ func NewGetCategoryFilesRequest(server string, category string, params *GetCateg
 
 		}
 
+		if params.Video != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "video", runtime.ParamLocationQuery, *params.Video); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
 		queryURL.RawQuery = queryValues.Encode()
 	}
 
@@ -322,7 +354,7 @@ func NewGetCategoryFilesRequest(server string, category string, params *GetCateg
 }
 
 // NewGetCategoryFileRequest generates requests for GetCategoryFile
-func NewGetCategoryFileRequest(server string, category string, filename string, format string) (*http.Request, error) {
+func NewGetCategoryFileRequest(server string, category string, filename string, format string, params *GetCategoryFileParams) (*http.Request, error) {
 	var err error
 
 	var pathParam0 string
@@ -361,6 +393,76 @@ func NewGetCategoryFileRequest(server string, category string, filename string,
 		return nil, err
 	}
 
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Width != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "width", runtime.ParamLocationQuery, *params.Width); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Height != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "height", runtime.ParamLocationQuery, *params.Height); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Fit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "fit", runtime.ParamLocationQuery, *params.Fit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Thumbnail != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "thumbnail", runtime.ParamLocationQuery, *params.Thumbnail); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
 	req, err := http.NewRequest("GET", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -422,14 +524,18 @@ type ClientWithResponsesInterface interface {
 	GetCategoryFilesWithResponse(ctx context.Context, category string, params *GetCategoryFilesParams, reqEditors ...RequestEditorFn) (*GetCategoryFilesResponse, error)
 
 	// GetCategoryFileWithResponse request
-	GetCategoryFileWithResponse(ctx context.Context, category string, filename string, format string, reqEditors ...RequestEditorFn) (*GetCategoryFileResponse, error)
+	GetCategoryFileWithResponse(ctx context.Context, category string, filename string, format string, params *GetCategoryFileParams, reqEditors ...RequestEditorFn) (*GetCategoryFileResponse, error)
 }
 
 type GetCategoriesResponse struct {
 	Body         []byte
 	HTTPResponse *http.Response
 	JSON200      *map[string]struct {
+		// Format The dominant format of the category, `png`, `gif` or `mp4`.
 		Format string `json:"format"`
+
+		// Formats All formats in the category, most common first, an extension for mixed categories.
+		Formats *[]string `json:"formats,omitempty"`
 	}
 }
 
@@ -502,6 +608,7 @@ func (r GetCategoryFilesResponse) StatusCode() int {
 type GetCategoryFileResponse struct {
 	Body         []byte
 	HTTPResponse *http.Response
+	JSON400      *Error
 	JSON404      *Error
 }
 
@@ -549,8 +656,8 @@ func (c *ClientWithResponses) GetCategoryFilesWithResponse(ctx context.Context,
 }
 
 // GetCategoryFileWithResponse request returning *GetCategoryFileResponse
-func (c *ClientWithResponses) GetCategoryFileWithResponse(ctx context.Context, category string, filename string, format string, reqEditors ...RequestEditorFn) (*GetCategoryFileResponse, error) {
-	rsp, err := c.GetCategoryFile(ctx, category, filename, format, reqEditors...)
+func (c *ClientWithResponses) GetCategoryFileWithResponse(ctx context.Context, category string, filename string, format string, params *GetCategoryFileParams, reqEditors ...RequestEditorFn) (*GetCategoryFileResponse, error) {
+	rsp, err := c.GetCategoryFile(ctx, category, filename, format, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
@@ -573,7 +680,11 @@ func ParseGetCategoriesResponse(rsp *http.Response) (*GetCategoriesResponse, err
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
 		var dest map[string]struct {
+			// Format The dominant format of the category, `png`, `gif` or `mp4`.
 			Format string `json:"format"`
+
+			// Formats All formats in the category, most common first, an extension for mixed categories.
+			Formats *[]string `json:"formats,omitempty"`
 		}
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
@@ -669,6 +780,13 @@ func ParseGetCategoryFileResponse(rsp *http.Response) (*GetCategoryFileResponse,
 	}
 
 	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
 		var dest Error
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
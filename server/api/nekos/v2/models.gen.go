@@ -3,19 +3,74 @@
 // Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.0 DO NOT EDIT.
 package v2
 
+// Defines values for SearchParamsColor.
+const (
+	Black  SearchParamsColor = "black"
+	Blue   SearchParamsColor = "blue"
+	Brown  SearchParamsColor = "brown"
+	Cyan   SearchParamsColor = "cyan"
+	Gray   SearchParamsColor = "gray"
+	Green  SearchParamsColor = "green"
+	Orange SearchParamsColor = "orange"
+	Pink   SearchParamsColor = "pink"
+	Purple SearchParamsColor = "purple"
+	Red    SearchParamsColor = "red"
+	White  SearchParamsColor = "white"
+	Yellow SearchParamsColor = "yellow"
+)
+
+// Defines values for GetCategoryFileParamsFit.
+const (
+	Contain GetCategoryFileParamsFit = "contain"
+	Cover   GetCategoryFileParamsFit = "cover"
+	Fill    GetCategoryFileParamsFit = "fill"
+)
+
+// Dimensions defines model for Dimensions.
+type Dimensions struct {
+	// Duration The total duration of all frames of animated images or the duration of videos in milliseconds,
+	// only present for animated images and videos of a known duration.
+	Duration *int `json:"duration,omitempty"`
+
+	// Frames The number of frames, only present for animated images.
+	Frames *int `json:"frames,omitempty"`
+
+	// Height The height in pixels.
+	Height int `json:"height"`
+
+	// Width The width in pixels.
+	Width int `json:"width"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// Placeholder A preview of the media shown while it loads, only present for images that can be decoded.
+type Placeholder struct {
+	// AverageColor The average color in hexadecimal notation, i.e. `#ff8000`.
+	AverageColor string `json:"average_color"`
+
+	// Blurhash The BlurHash of the media, see https://blurha.sh.
+	Blurhash string `json:"blurhash"`
+
+	// DominantColor The most common color in hexadecimal notation, i.e. `#ff8000`.
+	DominantColor string `json:"dominant_color"`
+}
+
 // Result defines model for Result.
 type Result struct {
-	AnimeName  *string `json:"anime_name,omitempty"`
-	ArtistHref *string `json:"artist_href,omitempty"`
-	ArtistName *string `json:"artist_name,omitempty"`
-	SourceUrl  *string `json:"source_url,omitempty"`
-	Url        string  `json:"url"`
+	AnimeName  *string     `json:"anime_name,omitempty"`
+	ArtistHref *string     `json:"artist_href,omitempty"`
+	ArtistName *string     `json:"artist_name,omitempty"`
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+
+	// Placeholder A preview of the media shown while it loads, only present for images that can be decoded.
+	Placeholder *Placeholder `json:"placeholder,omitempty"`
+	SourceUrl   *string      `json:"source_url,omitempty"`
+	Url         string       `json:"url"`
 }
 
 // SearchParams defines parameters for Search.
@@ -24,9 +79,39 @@ type SearchParams struct {
 	Type     int     `form:"type" json:"type"`
 	Category *string `form:"category,omitempty" json:"category,omitempty"`
 	Amount   *int    `form:"amount,omitempty" json:"amount,omitempty"`
+
+	// Color The name of the dominant color of the results, assets without a known color are excluded.
+	Color *SearchParamsColor `form:"color,omitempty" json:"color,omitempty"`
 }
 
+// SearchParamsColor defines parameters for Search.
+type SearchParamsColor string
+
 // GetCategoryFilesParams defines parameters for GetCategoryFiles.
 type GetCategoryFilesParams struct {
 	Amount *int `form:"amount,omitempty" json:"amount,omitempty"`
+
+	// Video Whether videos may be returned, defaults to true.
+	Video *bool `form:"video,omitempty" json:"video,omitempty"`
+}
+
+// GetCategoryFileParams defines parameters for GetCategoryFile.
+type GetCategoryFileParams struct {
+	// Width The width to resize the image to, it must be one of the sizes allowed by the server.
+	Width *int `form:"width,omitempty" json:"width,omitempty"`
+
+	// Height The height to resize the image to, it must be one of the sizes allowed by the server.
+	Height *int `form:"height,omitempty" json:"height,omitempty"`
+
+	// Fit How the image is fitted into the requested size when both width and height are specified,
+	// `contain` (default) keeps the aspect ratio, `cover` keeps the aspect ratio and crops the overflow, `fill` stretches the image.
+	// Images are only scaled down, except with `fill`. Resized images are JPEG for JPEG originals and PNG otherwise.
+	Fit *GetCategoryFileParamsFit `form:"fit,omitempty" json:"fit,omitempty"`
+
+	// Thumbnail The name of a thumbnail preset of the category, serves the pre-generated thumbnail instead of the image.
+	// It can't be combined with `width`, `height` or `fit`.
+	Thumbnail *string `form:"thumbnail,omitempty" json:"thumbnail,omitempty"`
 }
+
+// GetCategoryFileParamsFit defines parameters for GetCategoryFile.
+type GetCategoryFileParamsFit string
@@ -28,7 +28,7 @@ type ServerInterface interface {
 	GetCategoryFiles(w http.ResponseWriter, r *http.Request, category string, params GetCategoryFilesParams)
 	// Gets a specific image from our categories.
 	// (GET /{category}/{filename}.{format})
-	GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string)
+	GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string, params GetCategoryFileParams)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
@@ -54,7 +54,7 @@ func (_ Unimplemented) GetCategoryFiles(w http.ResponseWriter, r *http.Request,
 
 // Gets a specific image from our categories.
 // (GET /{category}/{filename}.{format})
-func (_ Unimplemented) GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string) {
+func (_ Unimplemented) GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string, params GetCategoryFileParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -137,6 +137,14 @@ func (siw *ServerInterfaceWrapper) Search(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// ------------- Optional query parameter "color" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "color", r.URL.Query(), &params.Color)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "color", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.Search(w, r, params)
 	}))
@@ -174,6 +182,14 @@ func (siw *ServerInterfaceWrapper) GetCategoryFiles(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// ------------- Optional query parameter "video" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "video", r.URL.Query(), &params.Video)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "video", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.GetCategoryFiles(w, r, category, params)
 	}))
@@ -218,8 +234,43 @@ func (siw *ServerInterfaceWrapper) GetCategoryFile(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCategoryFileParams
+
+	// ------------- Optional query parameter "width" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "width", r.URL.Query(), &params.Width)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "width", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "height" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "height", r.URL.Query(), &params.Height)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "height", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "fit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fit", r.URL.Query(), &params.Fit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "fit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "thumbnail" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "thumbnail", r.URL.Query(), &params.Thumbnail)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "thumbnail", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetCategoryFile(w, r, category, filename, format)
+		siw.Handler.GetCategoryFile(w, r, category, filename, format, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -366,7 +417,11 @@ type GetCategoriesResponseObject interface {
 }
 
 type GetCategories200JSONResponse map[string]struct {
+	// Format The dominant format of the category, `png`, `gif` or `mp4`.
 	Format string `json:"format"`
+
+	// Formats All formats in the category, most common first, an extension for mixed categories.
+	Formats *[]string `json:"formats,omitempty"`
 }
 
 func (response GetCategories200JSONResponse) VisitGetCategoriesResponse(w http.ResponseWriter, _ *http.Request) error {
@@ -437,6 +492,7 @@ type GetCategoryFileRequestObject struct {
 	Category string `json:"category"`
 	Filename string `json:"filename"`
 	Format   string `json:"format"`
+	Params   GetCategoryFileParams
 }
 
 type GetCategoryFileResponseObject interface {
@@ -474,6 +530,15 @@ func (response GetCategoryFile200SchemaResponse) VisitGetCategoryFileResponse(w
 	return err
 }
 
+type GetCategoryFile400JSONResponse Error
+
+func (response GetCategoryFile400JSONResponse) VisitGetCategoryFileResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type GetCategoryFile404JSONResponse Error
 
 func (response GetCategoryFile404JSONResponse) VisitGetCategoryFileResponse(w http.ResponseWriter, _ *http.Request) error {
@@ -605,12 +670,13 @@ func (sh *strictHandler) GetCategoryFiles(w http.ResponseWriter, r *http.Request
 }
 
 // GetCategoryFile operation middleware
-func (sh *strictHandler) GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string) {
+func (sh *strictHandler) GetCategoryFile(w http.ResponseWriter, r *http.Request, category string, filename string, format string, params GetCategoryFileParams) {
 	var request GetCategoryFileRequestObject
 
 	request.Category = category
 	request.Filename = filename
 	request.Format = format
+	request.Params = params
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.GetCategoryFile(ctx, request.(GetCategoryFileRequestObject))
@@ -90,17 +90,42 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 
 // The interface specification for the client above.
 type ClientInterface interface {
+	// GetAudit request
+	GetAudit(ctx context.Context, params *GetAuditParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEvents request
+	GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetRepos request
+	GetRepos(ctx context.Context, params *GetReposParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostReposWithBody request with any body
+	PostReposWithBody(ctx context.Context, params *PostReposParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostRepos(ctx context.Context, params *PostReposParams, body PostReposJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteRepo request
+	DeleteRepo(ctx context.Context, repo string, params *DeleteRepoParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PatchRepoWithBody request with any body
+	PatchRepoWithBody(ctx context.Context, repo string, params *PatchRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PatchRepo(ctx context.Context, repo string, params *PatchRepoParams, body PatchRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// PostRepoWithBody request with any body
 	PostRepoWithBody(ctx context.Context, repo string, params *PostRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	PostRepo(ctx context.Context, repo string, params *PostRepoParams, body PostRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// GetRepoEvents request
+	GetRepoEvents(ctx context.Context, repo string, params *GetRepoEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// DeleteRepoId request
 	DeleteRepoId(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
 
-func (c *Client) PostRepoWithBody(ctx context.Context, repo string, params *PostRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewPostRepoRequestWithBody(c.Server, repo, params, contentType, body)
+func (c *Client) GetAudit(ctx context.Context, params *GetAuditParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAuditRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +136,8 @@ func (c *Client) PostRepoWithBody(ctx context.Context, repo string, params *Post
 	return c.Client.Do(req)
 }
 
-func (c *Client) PostRepo(ctx context.Context, repo string, params *PostRepoParams, body PostRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewPostRepoRequest(c.Server, repo, params, body)
+func (c *Client) GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEventsRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -123,8 +148,8 @@ func (c *Client) PostRepo(ctx context.Context, repo string, params *PostRepoPara
 	return c.Client.Do(req)
 }
 
-func (c *Client) DeleteRepoId(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewDeleteRepoIdRequest(c.Server, repo, id, params)
+func (c *Client) GetRepos(ctx context.Context, params *GetReposParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetReposRequest(c.Server, params)
 	if err != nil {
 		return nil, err
 	}
@@ -135,34 +160,124 @@ func (c *Client) DeleteRepoId(ctx context.Context, repo string, id openapi_types
 	return c.Client.Do(req)
 }
 
-// NewPostRepoRequest calls the generic PostRepo builder with application/json body
-func NewPostRepoRequest(server string, repo string, params *PostRepoParams, body PostRepoJSONRequestBody) (*http.Request, error) {
-	var bodyReader io.Reader
-	buf, err := json.Marshal(body)
+func (c *Client) PostReposWithBody(ctx context.Context, params *PostReposParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostReposRequestWithBody(c.Server, params, contentType, body)
 	if err != nil {
 		return nil, err
 	}
-	bodyReader = bytes.NewReader(buf)
-	return NewPostRepoRequestWithBody(server, repo, params, "application/json", bodyReader)
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewPostRepoRequestWithBody generates requests for PostRepo with any type of body
-func NewPostRepoRequestWithBody(server string, repo string, params *PostRepoParams, contentType string, body io.Reader) (*http.Request, error) {
-	var err error
+func (c *Client) PostRepos(ctx context.Context, params *PostReposParams, body PostReposJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostReposRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	var pathParam0 string
+func (c *Client) DeleteRepo(ctx context.Context, repo string, params *DeleteRepoParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteRepoRequest(c.Server, repo, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+func (c *Client) PatchRepoWithBody(ctx context.Context, repo string, params *PatchRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPatchRepoRequestWithBody(c.Server, repo, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PatchRepo(ctx context.Context, repo string, params *PatchRepoParams, body PatchRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPatchRepoRequest(c.Server, repo, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostRepoWithBody(ctx context.Context, repo string, params *PostRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostRepoRequestWithBody(c.Server, repo, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostRepo(ctx context.Context, repo string, params *PostRepoParams, body PostRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostRepoRequest(c.Server, repo, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetRepoEvents(ctx context.Context, repo string, params *GetRepoEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetRepoEventsRequest(c.Server, repo, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteRepoId(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteRepoIdRequest(c.Server, repo, id, params)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewGetAuditRequest generates requests for GetAudit
+func NewGetAuditRequest(server string, params *GetAuditParams) (*http.Request, error) {
+	var err error
 
 	serverURL, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
 
-	operationPath := fmt.Sprintf("/repos/%s", pathParam0)
+	operationPath := fmt.Sprintf("/audit")
 	if operationPath[0] == '/' {
 		operationPath = "." + operationPath
 	}
@@ -172,13 +287,97 @@ func NewPostRepoRequestWithBody(server string, repo string, params *PostRepoPara
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Repo != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "repo", runtime.ParamLocationQuery, *params.Repo); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Item != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "item", runtime.ParamLocationQuery, *params.Item); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Since != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "since", runtime.ParamLocationQuery, *params.Since); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Until != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "until", runtime.ParamLocationQuery, *params.Until); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", contentType)
-
 	if params != nil {
 
 		if params.XNeroKey != nil {
@@ -197,30 +396,58 @@ func NewPostRepoRequestWithBody(server string, repo string, params *PostRepoPara
 	return req, nil
 }
 
-// NewDeleteRepoIdRequest generates requests for DeleteRepoId
-func NewDeleteRepoIdRequest(server string, repo string, id openapi_types.UUID, params *DeleteRepoIdParams) (*http.Request, error) {
+// NewGetEventsRequest generates requests for GetEvents
+func NewGetEventsRequest(server string, params *GetEventsParams) (*http.Request, error) {
 	var err error
 
-	var pathParam0 string
-
-	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	serverURL, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
 
-	var pathParam1 string
+	operationPath := fmt.Sprintf("/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
 
-	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	queryURL, err := serverURL.Parse(operationPath)
 	if err != nil {
 		return nil, err
 	}
 
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.LastEventID != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "Last-Event-ID", runtime.ParamLocationHeader, *params.LastEventID)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Last-Event-ID", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewGetReposRequest generates requests for GetRepos
+func NewGetReposRequest(server string, params *GetReposParams) (*http.Request, error) {
+	var err error
+
 	serverURL, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
 
-	operationPath := fmt.Sprintf("/repos/%s/%s", pathParam0, pathParam1)
+	operationPath := fmt.Sprintf("/repos")
 	if operationPath[0] == '/' {
 		operationPath = "." + operationPath
 	}
@@ -230,7 +457,7 @@ func NewDeleteRepoIdRequest(server string, repo string, id openapi_types.UUID, p
 		return nil, err
 	}
 
-	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -253,65 +480,589 @@ func NewDeleteRepoIdRequest(server string, repo string, id openapi_types.UUID, p
 	return req, nil
 }
 
-func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
-	for _, r := range c.RequestEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
-	}
-	for _, r := range additionalEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
+// NewPostReposRequest calls the generic PostRepos builder with application/json body
+func NewPostReposRequest(server string, params *PostReposParams, body PostReposJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	bodyReader = bytes.NewReader(buf)
+	return NewPostReposRequestWithBody(server, params, "application/json", bodyReader)
 }
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
-}
+// NewPostReposRequestWithBody generates requests for PostRepos with any type of body
+func NewPostReposRequestWithBody(server string, params *PostReposParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+	serverURL, err := url.Parse(server)
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
-}
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
-			return err
-		}
-		c.Server = newBaseURL.String()
-		return nil
+	operationPath := fmt.Sprintf("/repos")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
 	}
-}
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// PostRepoWithBodyWithResponse request with any body
-	PostRepoWithBodyWithResponse(ctx context.Context, repo string, params *PostRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostRepoResponse, error)
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
 
-	PostRepoWithResponse(ctx context.Context, repo string, params *PostRepoParams, body PostRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*PostRepoResponse, error)
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
 
-	// DeleteRepoIdWithResponse request
-	DeleteRepoIdWithResponse(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*DeleteRepoIdResponse, error)
-}
+	req.Header.Add("Content-Type", contentType)
 
-type PostRepoResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *Media
-	JSON400      *Error
-	JSON401      *Error
-}
+	if params != nil {
+
+		if params.XNeroKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Nero-Key", runtime.ParamLocationHeader, *params.XNeroKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Nero-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewDeleteRepoRequest generates requests for DeleteRepo
+func NewDeleteRepoRequest(server string, repo string, params *DeleteRepoParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/repos/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Purge != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "purge", runtime.ParamLocationQuery, *params.Purge); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.XNeroKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Nero-Key", runtime.ParamLocationHeader, *params.XNeroKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Nero-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewPatchRepoRequest calls the generic PatchRepo builder with application/json body
+func NewPatchRepoRequest(server string, repo string, params *PatchRepoParams, body PatchRepoJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPatchRepoRequestWithBody(server, repo, params, "application/json", bodyReader)
+}
+
+// NewPatchRepoRequestWithBody generates requests for PatchRepo with any type of body
+func NewPatchRepoRequestWithBody(server string, repo string, params *PatchRepoParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/repos/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params != nil {
+
+		if params.XNeroKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Nero-Key", runtime.ParamLocationHeader, *params.XNeroKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Nero-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewPostRepoRequest calls the generic PostRepo builder with application/json body
+func NewPostRepoRequest(server string, repo string, params *PostRepoParams, body PostRepoJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostRepoRequestWithBody(server, repo, params, "application/json", bodyReader)
+}
+
+// NewPostRepoRequestWithBody generates requests for PostRepo with any type of body
+func NewPostRepoRequestWithBody(server string, repo string, params *PostRepoParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/repos/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params != nil {
+
+		if params.XNeroKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Nero-Key", runtime.ParamLocationHeader, *params.XNeroKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Nero-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewGetRepoEventsRequest generates requests for GetRepoEvents
+func NewGetRepoEventsRequest(server string, repo string, params *GetRepoEventsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/repos/%s/events", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.LastEventID != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "Last-Event-ID", runtime.ParamLocationHeader, *params.LastEventID)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Last-Event-ID", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewDeleteRepoIdRequest generates requests for DeleteRepoId
+func NewDeleteRepoIdRequest(server string, repo string, id openapi_types.UUID, params *DeleteRepoIdParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "repo", runtime.ParamLocationPath, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/repos/%s/%s", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.XNeroKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Nero-Key", runtime.ParamLocationHeader, *params.XNeroKey)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Nero-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// GetAuditWithResponse request
+	GetAuditWithResponse(ctx context.Context, params *GetAuditParams, reqEditors ...RequestEditorFn) (*GetAuditResponse, error)
+
+	// GetEventsWithResponse request
+	GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error)
+
+	// GetReposWithResponse request
+	GetReposWithResponse(ctx context.Context, params *GetReposParams, reqEditors ...RequestEditorFn) (*GetReposResponse, error)
+
+	// PostReposWithBodyWithResponse request with any body
+	PostReposWithBodyWithResponse(ctx context.Context, params *PostReposParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostReposResponse, error)
+
+	PostReposWithResponse(ctx context.Context, params *PostReposParams, body PostReposJSONRequestBody, reqEditors ...RequestEditorFn) (*PostReposResponse, error)
+
+	// DeleteRepoWithResponse request
+	DeleteRepoWithResponse(ctx context.Context, repo string, params *DeleteRepoParams, reqEditors ...RequestEditorFn) (*DeleteRepoResponse, error)
+
+	// PatchRepoWithBodyWithResponse request with any body
+	PatchRepoWithBodyWithResponse(ctx context.Context, repo string, params *PatchRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PatchRepoResponse, error)
+
+	PatchRepoWithResponse(ctx context.Context, repo string, params *PatchRepoParams, body PatchRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*PatchRepoResponse, error)
+
+	// PostRepoWithBodyWithResponse request with any body
+	PostRepoWithBodyWithResponse(ctx context.Context, repo string, params *PostRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostRepoResponse, error)
+
+	PostRepoWithResponse(ctx context.Context, repo string, params *PostRepoParams, body PostRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*PostRepoResponse, error)
+
+	// GetRepoEventsWithResponse request
+	GetRepoEventsWithResponse(ctx context.Context, repo string, params *GetRepoEventsParams, reqEditors ...RequestEditorFn) (*GetRepoEventsResponse, error)
+
+	// DeleteRepoIdWithResponse request
+	DeleteRepoIdWithResponse(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*DeleteRepoIdResponse, error)
+}
+
+type GetAuditResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]AuditEntry
+	JSON400      *Error
+	JSON401      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAuditResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAuditResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetReposResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Repo
+	JSON400      *Error
+	JSON401      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetReposResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetReposResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostReposResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Repo
+	JSON400      *Error
+	JSON401      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r PostReposResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostReposResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteRepoResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Repo
+	JSON400      *Error
+	JSON401      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteRepoResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteRepoResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PatchRepoResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Repo
+	JSON400      *Error
+	JSON401      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r PatchRepoResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PatchRepoResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostRepoResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Media
+	JSON400      *Error
+	JSON401      *Error
+	JSON413      *Error
+	JSON415      *Error
+	JSON422      *Error
+}
 
 // Status returns HTTPResponse.Status
 func (r PostRepoResponse) Status() string {
@@ -329,6 +1080,28 @@ func (r PostRepoResponse) StatusCode() int {
 	return 0
 }
 
+type GetRepoEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetRepoEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetRepoEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
 type DeleteRepoIdResponse struct {
 	Body         []byte
 	HTTPResponse *http.Response
@@ -337,20 +1110,90 @@ type DeleteRepoIdResponse struct {
 	JSON401      *Error
 }
 
-// Status returns HTTPResponse.Status
-func (r DeleteRepoIdResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// Status returns HTTPResponse.Status
+func (r DeleteRepoIdResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteRepoIdResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// GetAuditWithResponse request returning *GetAuditResponse
+func (c *ClientWithResponses) GetAuditWithResponse(ctx context.Context, params *GetAuditParams, reqEditors ...RequestEditorFn) (*GetAuditResponse, error) {
+	rsp, err := c.GetAudit(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAuditResponse(rsp)
+}
+
+// GetEventsWithResponse request returning *GetEventsResponse
+func (c *ClientWithResponses) GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error) {
+	rsp, err := c.GetEvents(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEventsResponse(rsp)
+}
+
+// GetReposWithResponse request returning *GetReposResponse
+func (c *ClientWithResponses) GetReposWithResponse(ctx context.Context, params *GetReposParams, reqEditors ...RequestEditorFn) (*GetReposResponse, error) {
+	rsp, err := c.GetRepos(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetReposResponse(rsp)
+}
+
+// PostReposWithBodyWithResponse request with arbitrary body returning *PostReposResponse
+func (c *ClientWithResponses) PostReposWithBodyWithResponse(ctx context.Context, params *PostReposParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostReposResponse, error) {
+	rsp, err := c.PostReposWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostReposResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostReposWithResponse(ctx context.Context, params *PostReposParams, body PostReposJSONRequestBody, reqEditors ...RequestEditorFn) (*PostReposResponse, error) {
+	rsp, err := c.PostRepos(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostReposResponse(rsp)
+}
+
+// DeleteRepoWithResponse request returning *DeleteRepoResponse
+func (c *ClientWithResponses) DeleteRepoWithResponse(ctx context.Context, repo string, params *DeleteRepoParams, reqEditors ...RequestEditorFn) (*DeleteRepoResponse, error) {
+	rsp, err := c.DeleteRepo(ctx, repo, params, reqEditors...)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
+	return ParseDeleteRepoResponse(rsp)
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r DeleteRepoIdResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// PatchRepoWithBodyWithResponse request with arbitrary body returning *PatchRepoResponse
+func (c *ClientWithResponses) PatchRepoWithBodyWithResponse(ctx context.Context, repo string, params *PatchRepoParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PatchRepoResponse, error) {
+	rsp, err := c.PatchRepoWithBody(ctx, repo, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
 	}
-	return 0
+	return ParsePatchRepoResponse(rsp)
+}
+
+func (c *ClientWithResponses) PatchRepoWithResponse(ctx context.Context, repo string, params *PatchRepoParams, body PatchRepoJSONRequestBody, reqEditors ...RequestEditorFn) (*PatchRepoResponse, error) {
+	rsp, err := c.PatchRepo(ctx, repo, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePatchRepoResponse(rsp)
 }
 
 // PostRepoWithBodyWithResponse request with arbitrary body returning *PostRepoResponse
@@ -370,6 +1213,15 @@ func (c *ClientWithResponses) PostRepoWithResponse(ctx context.Context, repo str
 	return ParsePostRepoResponse(rsp)
 }
 
+// GetRepoEventsWithResponse request returning *GetRepoEventsResponse
+func (c *ClientWithResponses) GetRepoEventsWithResponse(ctx context.Context, repo string, params *GetRepoEventsParams, reqEditors ...RequestEditorFn) (*GetRepoEventsResponse, error) {
+	rsp, err := c.GetRepoEvents(ctx, repo, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetRepoEventsResponse(rsp)
+}
+
 // DeleteRepoIdWithResponse request returning *DeleteRepoIdResponse
 func (c *ClientWithResponses) DeleteRepoIdWithResponse(ctx context.Context, repo string, id openapi_types.UUID, params *DeleteRepoIdParams, reqEditors ...RequestEditorFn) (*DeleteRepoIdResponse, error) {
 	rsp, err := c.DeleteRepoId(ctx, repo, id, params, reqEditors...)
@@ -379,6 +1231,222 @@ func (c *ClientWithResponses) DeleteRepoIdWithResponse(ctx context.Context, repo
 	return ParseDeleteRepoIdResponse(rsp)
 }
 
+// ParseGetAuditResponse parses an HTTP response from a GetAuditWithResponse call
+func ParseGetAuditResponse(rsp *http.Response) (*GetAuditResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAuditResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []AuditEntry
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEventsResponse parses an HTTP response from a GetEventsWithResponse call
+func ParseGetEventsResponse(rsp *http.Response) (*GetEventsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseGetReposResponse parses an HTTP response from a GetReposWithResponse call
+func ParseGetReposResponse(rsp *http.Response) (*GetReposResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetReposResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Repo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostReposResponse parses an HTTP response from a PostReposWithResponse call
+func ParsePostReposResponse(rsp *http.Response) (*PostReposResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostReposResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Repo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteRepoResponse parses an HTTP response from a DeleteRepoWithResponse call
+func ParseDeleteRepoResponse(rsp *http.Response) (*DeleteRepoResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteRepoResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Repo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePatchRepoResponse parses an HTTP response from a PatchRepoWithResponse call
+func ParsePatchRepoResponse(rsp *http.Response) (*PatchRepoResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PatchRepoResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Repo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
 // ParsePostRepoResponse parses an HTTP response from a PostRepoWithResponse call
 func ParsePostRepoResponse(rsp *http.Response) (*PostRepoResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
@@ -414,6 +1482,53 @@ func ParsePostRepoResponse(rsp *http.Response) (*PostRepoResponse, error) {
 		}
 		response.JSON401 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 413:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON413 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 415:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON415 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 422:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON422 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetRepoEventsResponse parses an HTTP response from a GetRepoEventsWithResponse call
+func ParseGetRepoEventsResponse(rsp *http.Response) (*GetRepoEventsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetRepoEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
 	}
 
 	return response, nil
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/zlataovce/nero/repo/media/meta"
+	"github.com/zlataovce/nero/server/api"
+)
+
+// WrapEvent converts a repository change to its nero v1 API representation.
+func WrapEvent(e *repo.Event) (Event, error) {
+	m, err := WrapMedia(e.Media)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Id:    e.ID,
+		Media: m,
+		Repo:  e.Repo,
+		Time:  e.Time,
+		Type:  wrapEventType(e.Type),
+	}, nil
+}
+
+func wrapEventType(t repo.EventType) EventType {
+	switch t {
+	case repo.EventUpdate:
+		return EventTypeUpdate
+	case repo.EventDelete:
+		return EventTypeDelete
+	default:
+		return EventTypeCreate
+	}
+}
+
+// WrapMedia converts media to the API representation.
+func WrapMedia(m *media.Media) (Media, error) {
+	var (
+		m0  = &Media_Meta{}
+		err error
+	)
+	switch v := wrapMetadata(m.Meta).(type) {
+	case GenericMetadata:
+		err = m0.FromGenericMetadata(v)
+	case AnimeMetadata:
+		err = m0.FromAnimeMetadata(v)
+	}
+
+	if err != nil {
+		return Media{}, err
+	}
+
+	res := Media{
+		Format:      wrapFormat(m.Format),
+		Id:          m.ID,
+		Meta:        m0,
+		Dimensions:  wrapDimensions(m.Dimensions),
+		Placeholder: wrapPlaceholder(m.Placeholder),
+	}
+	if len(m.StrippedMetadata) > 0 {
+		kinds := make([]MetadataKind, len(m.StrippedMetadata))
+		for i, k := range m.StrippedMetadata {
+			kinds[i] = MetadataKind(k)
+		}
+
+		res.StrippedMetadata = &kinds
+	}
+
+	return res, nil
+}
+
+// wrapPlaceholder converts a media placeholder to the API representation.
+func wrapPlaceholder(p *media.Placeholder) *Placeholder {
+	if p == nil {
+		return nil
+	}
+
+	return &Placeholder{Blurhash: p.BlurHash, AverageColor: p.AverageColor, DominantColor: p.DominantColor}
+}
+
+// wrapDimensions converts media dimensions to the API representation, the frames are only included
+// for animated images and the duration for animated images and videos.
+func wrapDimensions(d *media.Dimensions) *Dimensions {
+	if d == nil {
+		return nil
+	}
+
+	res := &Dimensions{Width: d.Width, Height: d.Height}
+	if d.Frames > 0 {
+		frames := d.Frames
+		res.Frames = &frames
+	}
+	if d.Frames > 0 || d.Duration > 0 {
+		duration := int(d.Duration.Milliseconds())
+		res.Duration = &duration
+	}
+
+	return res
+}
+
+func wrapFormat(f media.Format) MediaFormat {
+	switch f {
+	case media.FormatImage:
+		return Image
+	case media.FormatAnimatedImage:
+		return AnimatedImage
+	case media.FormatVideo:
+		return Video
+	default:
+		return Unknown
+	}
+}
+
+func wrapMetadata(v meta.Metadata) interface{} {
+	switch m := v.(type) {
+	case *meta.GenericMetadata:
+		return GenericMetadata{
+			Source:     api.MakeOptString(m.Source),
+			Artist:     api.MakeOptString(m.Artist),
+			ArtistLink: api.MakeOptString(m.ArtistLink),
+		}
+	case *meta.AnimeMetadata:
+		return AnimeMetadata{
+			Name: api.MakeOptString(m.Name),
+		}
+	}
+
+	return nil
+}
@@ -6,17 +6,36 @@ package v1
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for AuditAction.
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionUpdate AuditAction = "update"
+)
+
 // Defines values for ErrorType.
 const (
-	BadRequest    ErrorType = "bad_request"
-	InternalError ErrorType = "internal_error"
-	NotFound      ErrorType = "not_found"
-	Unauthorized  ErrorType = "unauthorized"
+	BadRequest           ErrorType = "bad_request"
+	InternalError        ErrorType = "internal_error"
+	InvalidDimensions    ErrorType = "invalid_dimensions"
+	NotFound             ErrorType = "not_found"
+	PayloadTooLarge      ErrorType = "payload_too_large"
+	TooManyRequests      ErrorType = "too_many_requests"
+	Unauthorized         ErrorType = "unauthorized"
+	UnsupportedMediaType ErrorType = "unsupported_media_type"
+)
+
+// Defines values for EventType.
+const (
+	EventTypeCreate EventType = "create"
+	EventTypeDelete EventType = "delete"
+	EventTypeUpdate EventType = "update"
 )
 
 // Defines values for MediaFormat.
@@ -24,6 +43,14 @@ const (
 	AnimatedImage MediaFormat = "animated_image"
 	Image         MediaFormat = "image"
 	Unknown       MediaFormat = "unknown"
+	Video         MediaFormat = "video"
+)
+
+// Defines values for MetadataKind.
+const (
+	Exif MetadataKind = "exif"
+	Icc  MetadataKind = "icc"
+	Xmp  MetadataKind = "xmp"
 )
 
 // Defines values for MetadataType.
@@ -38,6 +65,44 @@ type AnimeMetadata struct {
 	Type MetadataType `json:"type"`
 }
 
+// AuditAction defines model for AuditAction.
+type AuditAction string
+
+// AuditEntry defines model for AuditEntry.
+type AuditEntry struct {
+	Action AuditAction `json:"action"`
+
+	// After The media after the operation.
+	After *Media `json:"after"`
+
+	// Before The media before the operation.
+	Before   *Media             `json:"before"`
+	ClientIp string             `json:"client_ip"`
+	Item     openapi_types.UUID `json:"item"`
+
+	// Key The name of the key or token the operation was authorized with.
+	Key       string    `json:"key"`
+	Repo      string    `json:"repo"`
+	RequestId string    `json:"request_id"`
+	Time      time.Time `json:"time"`
+}
+
+// Dimensions defines model for Dimensions.
+type Dimensions struct {
+	// Duration The total duration of all frames of animated images or the duration of videos in milliseconds,
+	// only present for animated images and videos of a known duration.
+	Duration *int `json:"duration,omitempty"`
+
+	// Frames The number of frames, only present for animated images.
+	Frames *int `json:"frames,omitempty"`
+
+	// Height The height in pixels.
+	Height int `json:"height"`
+
+	// Width The width in pixels.
+	Width int `json:"width"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	// Description The error description.
@@ -48,6 +113,18 @@ type Error struct {
 // ErrorType defines model for ErrorType.
 type ErrorType string
 
+// Event A repository change, delivered by webhooks and event streams.
+type Event struct {
+	Id    openapi_types.UUID `json:"id"`
+	Media Media              `json:"media"`
+	Repo  string             `json:"repo"`
+	Time  time.Time          `json:"time"`
+	Type  EventType          `json:"type"`
+}
+
+// EventType defines model for EventType.
+type EventType string
+
 // GenericMetadata defines model for GenericMetadata.
 type GenericMetadata struct {
 	Artist     *string      `json:"artist"`
@@ -58,11 +135,18 @@ type GenericMetadata struct {
 
 // Media defines model for Media.
 type Media struct {
-	Format MediaFormat        `json:"format"`
-	Id     openapi_types.UUID `json:"id"`
+	Dimensions *Dimensions        `json:"dimensions,omitempty"`
+	Format     MediaFormat        `json:"format"`
+	Id         openapi_types.UUID `json:"id"`
 
 	// Meta The media metadata.
 	Meta *Media_Meta `json:"meta"`
+
+	// Placeholder A preview of the media shown while it loads, only present for images that can be decoded.
+	Placeholder *Placeholder `json:"placeholder,omitempty"`
+
+	// StrippedMetadata The kinds of metadata removed from the media file on upload, only present if any were removed.
+	StrippedMetadata *[]MetadataKind `json:"stripped_metadata,omitempty"`
 }
 
 // Media_Meta The media metadata.
@@ -78,9 +162,24 @@ type Metadata struct {
 	Type MetadataType `json:"type"`
 }
 
+// MetadataKind defines model for MetadataKind.
+type MetadataKind string
+
 // MetadataType defines model for MetadataType.
 type MetadataType string
 
+// Placeholder A preview of the media shown while it loads, only present for images that can be decoded.
+type Placeholder struct {
+	// AverageColor The average color in hexadecimal notation, i.e. `#ff8000`.
+	AverageColor string `json:"average_color"`
+
+	// Blurhash The BlurHash of the media, see https://blurha.sh.
+	Blurhash string `json:"blurhash"`
+
+	// DominantColor The most common color in hexadecimal notation, i.e. `#ff8000`.
+	DominantColor string `json:"dominant_color"`
+}
+
 // ProtoMedia defines model for ProtoMedia.
 type ProtoMedia struct {
 	Data string           `json:"data"`
@@ -92,16 +191,118 @@ type ProtoMedia_Meta struct {
 	union json.RawMessage
 }
 
+// ProtoRepo defines model for ProtoRepo.
+type ProtoRepo struct {
+	Id string `json:"id"`
+
+	// Meta The repository metadata, i.e. the `auth_key` authentication key.
+	Meta *RepoMetadata `json:"meta,omitempty"`
+}
+
+// Repo defines model for Repo.
+type Repo struct {
+	Id string `json:"id"`
+
+	// Items The number of media in the repository.
+	Items int `json:"items"`
+
+	// Managed Whether the repository is managed at runtime, repositories from the configuration file can't be modified.
+	Managed bool `json:"managed"`
+
+	// Meta The repository metadata, i.e. the `auth_key` authentication key.
+	Meta RepoMetadata `json:"meta"`
+}
+
+// RepoMetadata The repository metadata, i.e. the `auth_key` authentication key.
+type RepoMetadata map[string]string
+
+// RepoPatch defines model for RepoPatch.
+type RepoPatch struct {
+	// Id The new repository ID.
+	Id *string `json:"id,omitempty"`
+
+	// Meta The repository metadata, i.e. the `auth_key` authentication key.
+	Meta *RepoMetadata `json:"meta,omitempty"`
+}
+
+// GetAuditParams defines parameters for GetAudit.
+type GetAuditParams struct {
+	// Repo The repository ID.
+	Repo *string `form:"repo,omitempty" json:"repo,omitempty"`
+
+	// Item The media ID.
+	Item *openapi_types.UUID `form:"item,omitempty" json:"item,omitempty"`
+
+	// Since The inclusive lower bound of the entry time.
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Until The exclusive upper bound of the entry time.
+	Until *time.Time `form:"until,omitempty" json:"until,omitempty"`
+
+	// Limit The maximum number of entries, defaults to 100.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// XNeroKey The repository authentication key, not needed if authenticated with an `Authorization` bearer token.
+	XNeroKey *string `json:"X-Nero-Key,omitempty"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// LastEventID The ID of the last received event, buffered events following it are replayed.
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
+// GetReposParams defines parameters for GetRepos.
+type GetReposParams struct {
+	// XNeroKey The admin key, not needed if authenticated with an `Authorization` bearer token with the admin scope.
+	XNeroKey *string `json:"X-Nero-Key,omitempty"`
+}
+
+// PostReposParams defines parameters for PostRepos.
+type PostReposParams struct {
+	// XNeroKey The admin key, not needed if authenticated with an `Authorization` bearer token with the admin scope.
+	XNeroKey *string `json:"X-Nero-Key,omitempty"`
+}
+
+// DeleteRepoParams defines parameters for DeleteRepo.
+type DeleteRepoParams struct {
+	// Purge Whether the repository storage should be removed too, defaults to false.
+	Purge *bool `form:"purge,omitempty" json:"purge,omitempty"`
+
+	// XNeroKey The admin key, not needed if authenticated with an `Authorization` bearer token with the admin scope.
+	XNeroKey *string `json:"X-Nero-Key,omitempty"`
+}
+
+// PatchRepoParams defines parameters for PatchRepo.
+type PatchRepoParams struct {
+	// XNeroKey The admin key, not needed if authenticated with an `Authorization` bearer token with the admin scope.
+	XNeroKey *string `json:"X-Nero-Key,omitempty"`
+}
+
 // PostRepoParams defines parameters for PostRepo.
 type PostRepoParams struct {
+	// XNeroKey The repository authentication key, not needed if authenticated with an `Authorization` bearer token.
 	XNeroKey *string `json:"X-Nero-Key,omitempty"`
 }
 
+// GetRepoEventsParams defines parameters for GetRepoEvents.
+type GetRepoEventsParams struct {
+	// LastEventID The ID of the last received event, buffered events following it are replayed.
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
 // DeleteRepoIdParams defines parameters for DeleteRepoId.
 type DeleteRepoIdParams struct {
+	// XNeroKey The repository authentication key, not needed if authenticated with an `Authorization` bearer token.
 	XNeroKey *string `json:"X-Nero-Key,omitempty"`
 }
 
+// PostReposJSONRequestBody defines body for PostRepos for application/json ContentType.
+type PostReposJSONRequestBody = ProtoRepo
+
+// PatchRepoJSONRequestBody defines body for PatchRepo for application/json ContentType.
+type PatchRepoJSONRequestBody = RepoPatch
+
 // PostRepoJSONRequestBody defines body for PostRepo for application/json ContentType.
 type PostRepoJSONRequestBody = ProtoMedia
 
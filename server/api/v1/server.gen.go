@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -18,36 +19,404 @@ import (
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (GET /audit)
+	GetAudit(w http.ResponseWriter, r *http.Request, params GetAuditParams)
+
+	// (GET /events)
+	GetEvents(w http.ResponseWriter, r *http.Request, params GetEventsParams)
+
+	// (GET /repos)
+	GetRepos(w http.ResponseWriter, r *http.Request, params GetReposParams)
+
+	// (POST /repos)
+	PostRepos(w http.ResponseWriter, r *http.Request, params PostReposParams)
+
+	// (DELETE /repos/{repo})
+	DeleteRepo(w http.ResponseWriter, r *http.Request, repo string, params DeleteRepoParams)
+
+	// (PATCH /repos/{repo})
+	PatchRepo(w http.ResponseWriter, r *http.Request, repo string, params PatchRepoParams)
+
 	// (POST /repos/{repo})
 	PostRepo(w http.ResponseWriter, r *http.Request, repo string, params PostRepoParams)
 
+	// (GET /repos/{repo}/events)
+	GetRepoEvents(w http.ResponseWriter, r *http.Request, repo string, params GetRepoEventsParams)
+
 	// (DELETE /repos/{repo}/{id})
 	DeleteRepoId(w http.ResponseWriter, r *http.Request, repo string, id openapi_types.UUID, params DeleteRepoIdParams)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 
-type Unimplemented struct{}
+type Unimplemented struct{}
+
+// (GET /audit)
+func (_ Unimplemented) GetAudit(w http.ResponseWriter, r *http.Request, params GetAuditParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /events)
+func (_ Unimplemented) GetEvents(w http.ResponseWriter, r *http.Request, params GetEventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /repos)
+func (_ Unimplemented) GetRepos(w http.ResponseWriter, r *http.Request, params GetReposParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /repos)
+func (_ Unimplemented) PostRepos(w http.ResponseWriter, r *http.Request, params PostReposParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /repos/{repo})
+func (_ Unimplemented) DeleteRepo(w http.ResponseWriter, r *http.Request, repo string, params DeleteRepoParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PATCH /repos/{repo})
+func (_ Unimplemented) PatchRepo(w http.ResponseWriter, r *http.Request, repo string, params PatchRepoParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /repos/{repo})
+func (_ Unimplemented) PostRepo(w http.ResponseWriter, r *http.Request, repo string, params PostRepoParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /repos/{repo}/events)
+func (_ Unimplemented) GetRepoEvents(w http.ResponseWriter, r *http.Request, repo string, params GetRepoEventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /repos/{repo}/{id})
+func (_ Unimplemented) DeleteRepoId(w http.ResponseWriter, r *http.Request, repo string, id openapi_types.UUID, params DeleteRepoIdParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// GetAudit operation middleware
+func (siw *ServerInterfaceWrapper) GetAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuditParams
+
+	// ------------- Optional query parameter "repo" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "repo", r.URL.Query(), &params.Repo)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "repo", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "item" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "item", r.URL.Query(), &params.Item)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "item", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", r.URL.Query(), &params.Since)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "since", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "until" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "until", r.URL.Query(), &params.Until)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "until", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Nero-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Nero-Key")]; found {
+		var XNeroKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Nero-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Nero-Key", valueList[0], &XNeroKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Nero-Key", Err: err})
+			return
+		}
+
+		params.XNeroKey = &XNeroKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAudit(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetEventsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Last-Event-ID", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Last-Event-ID", Err: err})
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEvents(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetRepos operation middleware
+func (siw *ServerInterfaceWrapper) GetRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetReposParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Nero-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Nero-Key")]; found {
+		var XNeroKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Nero-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Nero-Key", valueList[0], &XNeroKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Nero-Key", Err: err})
+			return
+		}
+
+		params.XNeroKey = &XNeroKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRepos(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostRepos operation middleware
+func (siw *ServerInterfaceWrapper) PostRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostReposParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Nero-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Nero-Key")]; found {
+		var XNeroKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Nero-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Nero-Key", valueList[0], &XNeroKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Nero-Key", Err: err})
+			return
+		}
+
+		params.XNeroKey = &XNeroKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostRepos(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteRepo operation middleware
+func (siw *ServerInterfaceWrapper) DeleteRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "repo" -------------
+	var repo string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "repo", chi.URLParam(r, "repo"), &repo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "repo", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteRepoParams
+
+	// ------------- Optional query parameter "purge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "purge", r.URL.Query(), &params.Purge)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "purge", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Nero-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Nero-Key")]; found {
+		var XNeroKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Nero-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Nero-Key", valueList[0], &XNeroKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Nero-Key", Err: err})
+			return
+		}
+
+		params.XNeroKey = &XNeroKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteRepo(w, r, repo, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PatchRepo operation middleware
+func (siw *ServerInterfaceWrapper) PatchRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "repo" -------------
+	var repo string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "repo", chi.URLParam(r, "repo"), &repo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "repo", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchRepoParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Nero-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Nero-Key")]; found {
+		var XNeroKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Nero-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Nero-Key", valueList[0], &XNeroKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Nero-Key", Err: err})
+			return
+		}
+
+		params.XNeroKey = &XNeroKey
+
+	}
 
-// (POST /repos/{repo})
-func (_ Unimplemented) PostRepo(w http.ResponseWriter, r *http.Request, repo string, params PostRepoParams) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchRepo(w, r, repo, params)
+	}))
 
-// (DELETE /repos/{repo}/{id})
-func (_ Unimplemented) DeleteRepoId(w http.ResponseWriter, r *http.Request, repo string, id openapi_types.UUID, params DeleteRepoIdParams) {
-	w.WriteHeader(http.StatusNotImplemented)
-}
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-type MiddlewareFunc func(http.Handler) http.Handler
-
 // PostRepo operation middleware
 func (siw *ServerInterfaceWrapper) PostRepo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -98,6 +467,56 @@ func (siw *ServerInterfaceWrapper) PostRepo(w http.ResponseWriter, r *http.Reque
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// GetRepoEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetRepoEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "repo" -------------
+	var repo string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "repo", chi.URLParam(r, "repo"), &repo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "repo", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRepoEventsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Last-Event-ID", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Last-Event-ID", Err: err})
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRepoEvents(w, r, repo, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // DeleteRepoId operation middleware
 func (siw *ServerInterfaceWrapper) DeleteRepoId(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -213,71 +632,298 @@ func (e *InvalidParamFormatError) Error() string {
 	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
 }
 
-func (e *InvalidParamFormatError) Unwrap() error {
-	return e.Err
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/audit", wrapper.GetAudit)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/events", wrapper.GetEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/repos", wrapper.GetRepos)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/repos", wrapper.PostRepos)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/repos/{repo}", wrapper.DeleteRepo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/repos/{repo}", wrapper.PatchRepo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/repos/{repo}", wrapper.PostRepo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/repos/{repo}/events", wrapper.GetRepoEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/repos/{repo}/{id}", wrapper.DeleteRepoId)
+	})
+
+	return r
+}
+
+type GetAuditRequestObject struct {
+	Params GetAuditParams
+}
+
+type GetAuditResponseObject interface {
+	VisitGetAuditResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type GetAudit200JSONResponse []AuditEntry
+
+func (response GetAudit200JSONResponse) VisitGetAuditResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAudit400JSONResponse Error
+
+func (response GetAudit400JSONResponse) VisitGetAuditResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetAudit401JSONResponse Error
+
+func (response GetAudit401JSONResponse) VisitGetAuditResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetEventsRequestObject struct {
+	Params GetEventsParams
+}
+
+type GetEventsResponseObject interface {
+	VisitGetEventsResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type GetEvents200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetEvents200TexteventStreamResponse) VisitGetEventsResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetReposRequestObject struct {
+	Params GetReposParams
+}
+
+type GetReposResponseObject interface {
+	VisitGetReposResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type GetRepos200JSONResponse []Repo
+
+func (response GetRepos200JSONResponse) VisitGetReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRepos400JSONResponse Error
+
+func (response GetRepos400JSONResponse) VisitGetReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRepos401JSONResponse Error
+
+func (response GetRepos401JSONResponse) VisitGetReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostReposRequestObject struct {
+	Params PostReposParams
+	Body   *PostReposJSONRequestBody
+}
+
+type PostReposResponseObject interface {
+	VisitPostReposResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type PostRepos200JSONResponse Repo
+
+func (response PostRepos200JSONResponse) VisitPostReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRepos400JSONResponse Error
+
+func (response PostRepos400JSONResponse) VisitPostReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRepos401JSONResponse Error
+
+func (response PostRepos401JSONResponse) VisitPostReposResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteRepoRequestObject struct {
+	Repo   string `json:"repo"`
+	Params DeleteRepoParams
+}
+
+type DeleteRepoResponseObject interface {
+	VisitDeleteRepoResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type DeleteRepo200JSONResponse Repo
+
+func (response DeleteRepo200JSONResponse) VisitDeleteRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteRepo400JSONResponse Error
+
+func (response DeleteRepo400JSONResponse) VisitDeleteRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteRepo401JSONResponse Error
+
+func (response DeleteRepo401JSONResponse) VisitDeleteRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PatchRepoRequestObject struct {
+	Repo   string `json:"repo"`
+	Params PatchRepoParams
+	Body   *PatchRepoJSONRequestBody
 }
 
-type TooManyValuesForParamError struct {
-	ParamName string
-	Count     int
+type PatchRepoResponseObject interface {
+	VisitPatchRepoResponse(w http.ResponseWriter, r *http.Request) error
 }
 
-func (e *TooManyValuesForParamError) Error() string {
-	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
-}
+type PatchRepo200JSONResponse Repo
 
-// Handler creates http.Handler with routing matching OpenAPI spec.
-func Handler(si ServerInterface) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{})
-}
+func (response PatchRepo200JSONResponse) VisitPatchRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
 
-type ChiServerOptions struct {
-	BaseURL          string
-	BaseRouter       chi.Router
-	Middlewares      []MiddlewareFunc
-	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+	return json.NewEncoder(w).Encode(response)
 }
 
-// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
-func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{
-		BaseRouter: r,
-	})
-}
+type PatchRepo400JSONResponse Error
 
-func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{
-		BaseURL:    baseURL,
-		BaseRouter: r,
-	})
-}
+func (response PatchRepo400JSONResponse) VisitPatchRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
 
-// HandlerWithOptions creates http.Handler with additional options
-func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
-	r := options.BaseRouter
+	return json.NewEncoder(w).Encode(response)
+}
 
-	if r == nil {
-		r = chi.NewRouter()
-	}
-	if options.ErrorHandlerFunc == nil {
-		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		}
-	}
-	wrapper := ServerInterfaceWrapper{
-		Handler:            si,
-		HandlerMiddlewares: options.Middlewares,
-		ErrorHandlerFunc:   options.ErrorHandlerFunc,
-	}
+type PatchRepo401JSONResponse Error
 
-	r.Group(func(r chi.Router) {
-		r.Post(options.BaseURL+"/repos/{repo}", wrapper.PostRepo)
-	})
-	r.Group(func(r chi.Router) {
-		r.Delete(options.BaseURL+"/repos/{repo}/{id}", wrapper.DeleteRepoId)
-	})
+func (response PatchRepo401JSONResponse) VisitPatchRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
 
-	return r
+	return json.NewEncoder(w).Encode(response)
 }
 
 type PostRepoRequestObject struct {
@@ -317,6 +963,70 @@ func (response PostRepo401JSONResponse) VisitPostRepoResponse(w http.ResponseWri
 	return json.NewEncoder(w).Encode(response)
 }
 
+type PostRepo413JSONResponse Error
+
+func (response PostRepo413JSONResponse) VisitPostRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(413)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRepo415JSONResponse Error
+
+func (response PostRepo415JSONResponse) VisitPostRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(415)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRepo422JSONResponse Error
+
+func (response PostRepo422JSONResponse) VisitPostRepoResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(422)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRepoEventsRequestObject struct {
+	Repo   string `json:"repo"`
+	Params GetRepoEventsParams
+}
+
+type GetRepoEventsResponseObject interface {
+	VisitGetRepoEventsResponse(w http.ResponseWriter, r *http.Request) error
+}
+
+type GetRepoEvents200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetRepoEvents200TexteventStreamResponse) VisitGetRepoEventsResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetRepoEvents400JSONResponse Error
+
+func (response GetRepoEvents400JSONResponse) VisitGetRepoEventsResponse(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type DeleteRepoIdRequestObject struct {
 	Repo   string             `json:"repo"`
 	Id     openapi_types.UUID `json:"id"`
@@ -357,9 +1067,30 @@ func (response DeleteRepoId401JSONResponse) VisitDeleteRepoIdResponse(w http.Res
 // StrictServerInterface represents all server handlers.
 type StrictServerInterface interface {
 
+	// (GET /audit)
+	GetAudit(ctx context.Context, request GetAuditRequestObject) (GetAuditResponseObject, error)
+
+	// (GET /events)
+	GetEvents(ctx context.Context, request GetEventsRequestObject) (GetEventsResponseObject, error)
+
+	// (GET /repos)
+	GetRepos(ctx context.Context, request GetReposRequestObject) (GetReposResponseObject, error)
+
+	// (POST /repos)
+	PostRepos(ctx context.Context, request PostReposRequestObject) (PostReposResponseObject, error)
+
+	// (DELETE /repos/{repo})
+	DeleteRepo(ctx context.Context, request DeleteRepoRequestObject) (DeleteRepoResponseObject, error)
+
+	// (PATCH /repos/{repo})
+	PatchRepo(ctx context.Context, request PatchRepoRequestObject) (PatchRepoResponseObject, error)
+
 	// (POST /repos/{repo})
 	PostRepo(ctx context.Context, request PostRepoRequestObject) (PostRepoResponseObject, error)
 
+	// (GET /repos/{repo}/events)
+	GetRepoEvents(ctx context.Context, request GetRepoEventsRequestObject) (GetRepoEventsResponseObject, error)
+
 	// (DELETE /repos/{repo}/{id})
 	DeleteRepoId(ctx context.Context, request DeleteRepoIdRequestObject) (DeleteRepoIdResponseObject, error)
 }
@@ -392,6 +1123,178 @@ type strictHandler struct {
 	options     StrictHTTPServerOptions
 }
 
+// GetAudit operation middleware
+func (sh *strictHandler) GetAudit(w http.ResponseWriter, r *http.Request, params GetAuditParams) {
+	var request GetAuditRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetAudit(ctx, request.(GetAuditRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetAudit")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetAuditResponseObject); ok {
+		if err := validResponse.VisitGetAuditResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetEvents operation middleware
+func (sh *strictHandler) GetEvents(w http.ResponseWriter, r *http.Request, params GetEventsParams) {
+	var request GetEventsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetEvents(ctx, request.(GetEventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetEvents")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetEventsResponseObject); ok {
+		if err := validResponse.VisitGetEventsResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetRepos operation middleware
+func (sh *strictHandler) GetRepos(w http.ResponseWriter, r *http.Request, params GetReposParams) {
+	var request GetReposRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRepos(ctx, request.(GetReposRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRepos")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetReposResponseObject); ok {
+		if err := validResponse.VisitGetReposResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PostRepos operation middleware
+func (sh *strictHandler) PostRepos(w http.ResponseWriter, r *http.Request, params PostReposParams) {
+	var request PostReposRequestObject
+
+	request.Params = params
+
+	var body PostReposJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.PostRepos(ctx, request.(PostReposRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PostRepos")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(PostReposResponseObject); ok {
+		if err := validResponse.VisitPostReposResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteRepo operation middleware
+func (sh *strictHandler) DeleteRepo(w http.ResponseWriter, r *http.Request, repo string, params DeleteRepoParams) {
+	var request DeleteRepoRequestObject
+
+	request.Repo = repo
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteRepo(ctx, request.(DeleteRepoRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteRepo")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(DeleteRepoResponseObject); ok {
+		if err := validResponse.VisitDeleteRepoResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PatchRepo operation middleware
+func (sh *strictHandler) PatchRepo(w http.ResponseWriter, r *http.Request, repo string, params PatchRepoParams) {
+	var request PatchRepoRequestObject
+
+	request.Repo = repo
+	request.Params = params
+
+	var body PatchRepoJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.PatchRepo(ctx, request.(PatchRepoRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PatchRepo")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(PatchRepoResponseObject); ok {
+		if err := validResponse.VisitPatchRepoResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // PostRepo operation middleware
 func (sh *strictHandler) PostRepo(w http.ResponseWriter, r *http.Request, repo string, params PostRepoParams) {
 	var request PostRepoRequestObject
@@ -426,6 +1329,33 @@ func (sh *strictHandler) PostRepo(w http.ResponseWriter, r *http.Request, repo s
 	}
 }
 
+// GetRepoEvents operation middleware
+func (sh *strictHandler) GetRepoEvents(w http.ResponseWriter, r *http.Request, repo string, params GetRepoEventsParams) {
+	var request GetRepoEventsRequestObject
+
+	request.Repo = repo
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRepoEvents(ctx, request.(GetRepoEventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRepoEvents")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetRepoEventsResponseObject); ok {
+		if err := validResponse.VisitGetRepoEventsResponse(w, r); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // DeleteRepoId operation middleware
 func (sh *strictHandler) DeleteRepoId(w http.ResponseWriter, r *http.Request, repo string, id openapi_types.UUID, params DeleteRepoIdParams) {
 	var request DeleteRepoIdRequestObject
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// AllRepos is a Principal repository wildcard, granting access to all repositories.
+const AllRepos = "*"
+
+type principalKey struct{}
+
+// Principal is an authenticated client.
+type Principal struct {
+	// Name is the name of the client, i.e. the token subject.
+	Name string
+	// Repos are the IDs of repositories the client has access to, may contain AllRepos.
+	Repos []string
+	// Admin is whether the client has access to repository management.
+	Admin bool
+}
+
+// CanAccess returns whether the principal has access to a repository.
+func (p *Principal) CanAccess(repoId string) bool {
+	return slices.Contains(p.Repos, AllRepos) || slices.Contains(p.Repos, repoId)
+}
+
+// WithPrincipal returns a copy of the context carrying an authenticated principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the authenticated principal of the context, returns nil if there is none.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// scopePrincipal creates a principal from granted scopes, scopes with the prefix grant access to the repository
+// after it and the admin scope grants access to repository management, unless empty.
+func scopePrincipal(name string, scopes []string, scopePrefix, adminScope string) *Principal {
+	p := &Principal{Name: name}
+	for _, scope := range scopes {
+		if adminScope != "" && scope == adminScope {
+			p.Admin = true
+		}
+		if repoId, ok := strings.CutPrefix(scope, scopePrefix); ok && repoId != "" {
+			p.Repos = append(p.Repos, repoId)
+		}
+	}
+
+	return p
+}
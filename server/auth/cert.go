@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/x509"
+)
+
+// CertOptions are options of a CertMapper.
+type CertOptions struct {
+	// ScopePrefix is the prefix of scopes granting access to a repository.
+	ScopePrefix string
+	// AdminScope is the scope granting access to repository management, not granted by any scope if empty.
+	AdminScope string
+}
+
+// CertMapper maps verified TLS client certificates to principals.
+// The principal is named after the certificate's common name, its organizational units are the granted scopes.
+type CertMapper struct {
+	scopePrefix string
+	adminScope  string
+}
+
+// NewCertMapper creates a CertMapper.
+func NewCertMapper(opts CertOptions) *CertMapper {
+	return &CertMapper{
+		scopePrefix: opts.ScopePrefix,
+		adminScope:  opts.AdminScope,
+	}
+}
+
+// Principal returns the principal a verified client certificate represents.
+func (m *CertMapper) Principal(cert *x509.Certificate) *Principal {
+	return scopePrincipal(cert.Subject.CommonName, cert.Subject.OrganizationalUnit, m.scopePrefix, m.adminScope)
+}
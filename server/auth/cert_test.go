@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+)
+
+func TestCertMapperPrincipal(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminScope string
+		subject    pkix.Name
+		want       *Principal
+	}{
+		{
+			name:    "repositories",
+			subject: pkix.Name{CommonName: "uploader", OrganizationalUnit: []string{"repo:pat", "repo:kitsune", "ops"}},
+			want:    &Principal{Name: "uploader", Repos: []string{"pat", "kitsune"}},
+		},
+		{
+			name:    "all repositories",
+			subject: pkix.Name{CommonName: "backup", OrganizationalUnit: []string{"repo:*"}},
+			want:    &Principal{Name: "backup", Repos: []string{AllRepos}},
+		},
+		{
+			name:       "admin",
+			adminScope: "admin",
+			subject:    pkix.Name{CommonName: "operator", OrganizationalUnit: []string{"admin"}},
+			want:       &Principal{Name: "operator", Admin: true},
+		},
+		{
+			name:    "admin scope disabled",
+			subject: pkix.Name{CommonName: "operator", OrganizationalUnit: []string{"admin", ""}},
+			want:    &Principal{Name: "operator"},
+		},
+		{
+			name:    "empty repository",
+			subject: pkix.Name{CommonName: "nobody", OrganizationalUnit: []string{"repo:"}},
+			want:    &Principal{Name: "nobody"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewCertMapper(CertOptions{ScopePrefix: "repo:", AdminScope: test.adminScope})
+
+			p := m.Principal(&x509.Certificate{Subject: test.subject})
+			if !reflect.DeepEqual(p, test.want) {
+				t.Errorf("principal = %+v, want %+v", p, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zlataovce/nero/internal/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JWTOptions are options of a JWTVerifier.
+type JWTOptions struct {
+	// Secret is the shared secret for HMAC-signed tokens, may be empty.
+	Secret string
+	// KeyPath is the path of a PEM-encoded public key, may be empty.
+	KeyPath string
+	// JWKSPath is the path of a JSON Web Key Set file, may be empty.
+	JWKSPath string
+	// Issuer is the expected "iss" claim, not checked if empty.
+	Issuer string
+	// Audience is the expected "aud" claim, not checked if empty.
+	Audience string
+	// ScopeClaim is the name of the claim holding the granted scopes.
+	ScopeClaim string
+	// ScopePrefix is the prefix of scopes granting access to a repository.
+	ScopePrefix string
+	// AdminScope is the scope granting access to repository management, not granted by any scope if empty.
+	AdminScope string
+}
+
+// JWTVerifier verifies JWT bearer tokens and maps their scopes to repository access.
+type JWTVerifier struct {
+	staticKeys []jwt.VerificationKey
+	keySet     map[string]interface{}
+
+	parser      *jwt.Parser
+	scopeClaim  string
+	scopePrefix string
+	adminScope  string
+}
+
+// NewJWTVerifier creates a JWTVerifier, loading keys from the configured sources.
+func NewJWTVerifier(opts JWTOptions) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		scopeClaim:  opts.ScopeClaim,
+		scopePrefix: opts.ScopePrefix,
+		adminScope:  opts.AdminScope,
+	}
+
+	if opts.Secret != "" {
+		v.staticKeys = append(v.staticKeys, []byte(opts.Secret))
+	}
+	if opts.KeyPath != "" {
+		k, err := readPublicKey(opts.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		v.staticKeys = append(v.staticKeys, k)
+	}
+	if opts.JWKSPath != "" {
+		ks, err := readJWKS(opts.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+
+		v.keySet = ks
+	}
+	if len(v.staticKeys) == 0 && len(v.keySet) == 0 {
+		return nil, errors.New("no verification keys configured")
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	v.parser = jwt.NewParser(parserOpts...)
+	return v, nil
+}
+
+// Verify verifies a raw token and returns the principal it represents.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	var claims jwt.MapClaims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := v.scopes(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return scopePrincipal(sub, scopes, v.scopePrefix, v.adminScope), nil
+}
+
+func (v *JWTVerifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	if kid, ok := t.Header["kid"].(string); ok && v.keySet != nil {
+		if k, ok := v.keySet[kid]; ok {
+			return k, nil
+		}
+	}
+
+	// the jwt package tries all keys in a set until one verifies
+	if len(v.staticKeys) > 0 {
+		return jwt.VerificationKeySet{Keys: v.staticKeys}, nil
+	}
+
+	return nil, errors.New("no matching verification key")
+}
+
+// scopes reads the scope claim, either a space-delimited string or an array of strings.
+func (v *JWTVerifier) scopes(claims jwt.MapClaims) ([]string, error) {
+	switch s := claims[v.scopeClaim].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.Fields(s), nil
+	case []interface{}:
+		scopes := make([]string, 0, len(s))
+		for _, s0 := range s {
+			s1, ok := s0.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s claim contains a non-string value", v.scopeClaim)
+			}
+
+			scopes = append(scopes, s1)
+		}
+
+		return scopes, nil
+	}
+
+	return nil, fmt.Errorf("%s claim is not a string or an array", v.scopeClaim)
+}
+
+func readPublicKey(path string) (interface{}, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read public key file")
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("public key file is not PEM-encoded")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+
+		return k, nil
+	case "RSA PUBLIC KEY":
+		k, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+
+		return k, nil
+	case "CERTIFICATE":
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+
+		return c.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// testKeys are the keys the verifiers of the tests accept.
+type testKeys struct {
+	pub     ed25519.PublicKey
+	priv    ed25519.PrivateKey
+	pubPEM  []byte
+	keyPath string
+	jwks    string
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	k := &testKeys{
+		pub:     pub,
+		priv:    priv,
+		pubPEM:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		keyPath: filepath.Join(dir, "key.pem"),
+		jwks:    filepath.Join(dir, "jwks.json"),
+	}
+	if err := os.WriteFile(k.keyPath, k.pubPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(pub)},
+			{"kty": "oct", "kid": "enc", "use": "enc", "k": base64.RawURLEncoding.EncodeToString([]byte(testSecret))},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(k.jwks, set, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, header map[string]interface{}, claims jwt.MapClaims, key interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	for k, v := range header {
+		token.Header[k] = v
+	}
+
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestJWTVerifier(t *testing.T) {
+	var (
+		keys = newTestKeys(t)
+		exp  = time.Now().Add(time.Hour).Unix()
+	)
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"sub": "client", "exp": exp, "iss": "nero", "aud": "api", "scope": "nero:pat admin"}
+		for k, v := range extra {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		opts  JWTOptions
+		token string
+		// want are the expected repositories of the principal, nil if the token should be rejected
+		want  []string
+		admin bool
+	}{
+		{
+			name:  "hmac",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(nil), []byte(testSecret)),
+			want:  []string{"pat"},
+			admin: true,
+		},
+		{
+			name:  "public key",
+			opts:  JWTOptions{KeyPath: keys.keyPath},
+			token: sign(t, jwt.SigningMethodEdDSA, nil, claims(nil), keys.priv),
+			want:  []string{"pat"},
+			admin: true,
+		},
+		{
+			name:  "key set",
+			opts:  JWTOptions{JWKSPath: keys.jwks},
+			token: sign(t, jwt.SigningMethodEdDSA, map[string]interface{}{"kid": "ed"}, claims(nil), keys.priv),
+			want:  []string{"pat"},
+			admin: true,
+		},
+		{
+			name:  "scope array",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(jwt.MapClaims{"scope": []string{"nero:a", "nero:b"}}), []byte(testSecret)),
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "wrong secret",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(nil), []byte("another secret, just as long......")),
+		},
+		{
+			name:  "alg none",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodNone, nil, claims(nil), jwt.UnsafeAllowNoneSignatureType),
+		},
+		{
+			name:  "public key as hmac secret",
+			opts:  JWTOptions{KeyPath: keys.keyPath},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(nil), keys.pubPEM),
+		},
+		{
+			name:  "key set public key as hmac secret",
+			opts:  JWTOptions{JWKSPath: keys.jwks},
+			token: sign(t, jwt.SigningMethodHS256, map[string]interface{}{"kid": "ed"}, claims(nil), []byte(keys.pub)),
+		},
+		{
+			name:  "unknown kid",
+			opts:  JWTOptions{JWKSPath: keys.jwks},
+			token: sign(t, jwt.SigningMethodEdDSA, map[string]interface{}{"kid": "forged"}, claims(nil), keys.priv),
+		},
+		{
+			name:  "non-signature kid",
+			opts:  JWTOptions{JWKSPath: keys.jwks},
+			token: sign(t, jwt.SigningMethodHS256, map[string]interface{}{"kid": "enc"}, claims(nil), []byte(testSecret)),
+		},
+		{
+			name:  "forged kid falls back to static keys",
+			opts:  JWTOptions{Secret: testSecret, JWKSPath: keys.jwks},
+			token: sign(t, jwt.SigningMethodHS256, map[string]interface{}{"kid": "forged"}, claims(nil), []byte(testSecret)),
+			want:  []string{"pat"},
+			admin: true,
+		},
+		{
+			name:  "expired",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), []byte(testSecret)),
+		},
+		{
+			name:  "missing expiration",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(jwt.MapClaims{"exp": nil}), []byte(testSecret)),
+		},
+		{
+			name:  "wrong issuer",
+			opts:  JWTOptions{Secret: testSecret, Issuer: "other"},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(nil), []byte(testSecret)),
+		},
+		{
+			name:  "wrong audience",
+			opts:  JWTOptions{Secret: testSecret, Audience: "other"},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(nil), []byte(testSecret)),
+		},
+		{
+			name:  "non-string scope",
+			opts:  JWTOptions{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodHS256, nil, claims(jwt.MapClaims{"scope": []interface{}{"nero:a", 1}}), []byte(testSecret)),
+		},
+		{
+			name:  "malformed",
+			opts:  JWTOptions{Secret: testSecret},
+			token: "not.a.token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ScopeClaim, tt.opts.ScopePrefix, tt.opts.AdminScope = "scope", "nero:", "admin"
+
+			v, err := NewJWTVerifier(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := v.Verify(tt.token)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("token accepted, principal %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("token rejected: %v", err)
+			}
+
+			if p.Name != "client" || !slices.Equal(p.Repos, tt.want) || p.Admin != tt.admin {
+				t.Errorf("got principal %+v, want repos %v and admin %t", p, tt.want, tt.admin)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierWithoutKeys(t *testing.T) {
+	if _, err := NewJWTVerifier(JWTOptions{}); err == nil {
+		t.Error("verifier without keys created")
+	}
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"fmt"
+	"github.com/zlataovce/nero/repo"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is the number of events buffered for a subscriber, before it's considered too slow.
+const subscriberBuffer = 64
+
+// Entry is a repository change with its position in the stream.
+type Entry struct {
+	// ID is the stream ID of the entry, in the "<epoch>-<sequence number>" format.
+	// The epoch identifies the broker, so that resuming a stream of a previous process replays all buffered entries.
+	ID string
+	// Event is the repository change.
+	Event *repo.Event
+
+	seq uint64
+}
+
+// Subscription is a stream of repository changes.
+type Subscription struct {
+	// C receives the entries, it is closed if the subscriber could not keep up or the broker was closed.
+	C <-chan *Entry
+	// Backlog are the buffered entries following the resumed sequence number, to be consumed before C.
+	Backlog []*Entry
+
+	c      chan *Entry
+	repo   string
+	broker *Broker
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// Broker fans out repository changes to subscribers, keeping a bounded ring of the most recent entries,
+// so that subscribers can resume a stream.
+type Broker struct {
+	epoch string
+
+	ring []*Entry
+	next int // index of the next ring slot
+	seq  uint64
+
+	subs   map[*Subscription]struct{}
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewBroker creates a Broker keeping a number of recent entries.
+func NewBroker(size int) *Broker {
+	return &Broker{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		ring:  make([]*Entry, max(size, 1)),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish appends a repository change to the stream, it is a repo.Listener.
+func (b *Broker) Publish(e *repo.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	entry := &Entry{ID: fmt.Sprintf("%s-%d", b.epoch, b.seq), Event: e, seq: b.seq}
+
+	b.ring[b.next] = entry
+	b.next = (b.next + 1) % len(b.ring)
+
+	for s := range b.subs {
+		if s.repo != "" && s.repo != e.Repo {
+			continue
+		}
+
+		select {
+		case s.c <- entry:
+		default: // too slow, let it resume from the ring
+			delete(b.subs, s)
+			close(s.c)
+		}
+	}
+}
+
+// Subscribe creates a subscription to the changes of a repository, or all repositories if repoId is empty.
+// Buffered entries following the entry with lastID are returned as the backlog, none are replayed if it is empty.
+// All buffered entries are replayed if lastID is not from this broker.
+func (b *Broker) Subscribe(repoId, lastID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan *Entry, subscriberBuffer)
+	s := &Subscription{C: c, c: c, repo: repoId, broker: b}
+	if b.closed {
+		close(c)
+		return s
+	}
+
+	if lastID != "" {
+		lastSeq := b.parseSeq(lastID)
+		for i := range b.ring {
+			entry := b.ring[(b.next+i)%len(b.ring)] // oldest first
+			if entry == nil || entry.seq <= lastSeq || (repoId != "" && entry.Event.Repo != repoId) {
+				continue
+			}
+
+			s.Backlog = append(s.Backlog, entry)
+		}
+	}
+
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Close closes all subscriptions, the broker should not be used anymore after calling Close.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+func (b *Broker) unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// parseSeq parses the sequence number of an entry ID, returns 0 if the ID is malformed or from another broker.
+func (b *Broker) parseSeq(id string) uint64 {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
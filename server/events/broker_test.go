@@ -0,0 +1,124 @@
+package events
+
+import (
+	"github.com/zlataovce/nero/repo"
+	"slices"
+	"testing"
+)
+
+// publish publishes n events of a repository, returns the stream IDs of their entries.
+func publish(b *Broker, repoId string, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		b.Publish(&repo.Event{Repo: repoId})
+
+		b.mu.Lock()
+		ids[i] = b.ring[(b.next+len(b.ring)-1)%len(b.ring)].ID
+		b.mu.Unlock()
+	}
+
+	return ids
+}
+
+func entryIDs(entries []*Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	return ids
+}
+
+func TestBrokerReplay(t *testing.T) {
+	b := NewBroker(4)
+	defer b.Close()
+
+	var (
+		a1 = publish(b, "a", 2)
+		b1 = publish(b, "b", 1)
+		a2 = publish(b, "a", 2) // a1[0] is dropped from the ring
+	)
+
+	tests := []struct {
+		name   string
+		repo   string
+		lastID string
+		want   []string
+	}{
+		{name: "no last id", want: nil},
+		{name: "resume", lastID: a1[1], want: append(b1, a2...)},
+		{name: "resume repository", repo: "a", lastID: a1[1], want: a2},
+		{name: "resume other repository", repo: "b", lastID: a1[0], want: b1},
+		{name: "resume dropped", lastID: a1[0], want: append(append(a1[1:], b1...), a2...)},
+		{name: "up to date", lastID: a2[1], want: nil},
+		{name: "previous process", lastID: "0-1", want: append(append(a1[1:], b1...), a2...)},
+		{name: "malformed", lastID: "junk", want: append(append(a1[1:], b1...), a2...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := b.Subscribe(tt.repo, tt.lastID)
+			defer s.Close()
+
+			if got := entryIDs(s.Backlog); !slices.Equal(got, tt.want) {
+				t.Errorf("got backlog %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	b := NewBroker(4)
+	defer b.Close()
+
+	var (
+		all   = b.Subscribe("", "")
+		repoA = b.Subscribe("a", "")
+	)
+	defer all.Close()
+	defer repoA.Close()
+
+	ids := append(publish(b, "a", 1), publish(b, "b", 1)...)
+
+	if got := entryIDs([]*Entry{<-all.C, <-all.C}); !slices.Equal(got, ids) {
+		t.Errorf("got entries %v, want %v", got, ids)
+	}
+	if got := (<-repoA.C).ID; got != ids[0] {
+		t.Errorf("got entry %s, want %s", got, ids[0])
+	}
+	if len(repoA.C) != 0 {
+		t.Error("entry of another repository received")
+	}
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	b := NewBroker(1)
+	defer b.Close()
+
+	s := b.Subscribe("", "")
+	publish(b, "a", subscriberBuffer+1)
+
+	var n int
+	for range s.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d entries before the subscription was closed, want %d", n, subscriberBuffer)
+	}
+
+	s.Close() // already closed by the broker
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(1)
+	s := b.Subscribe("", "")
+	b.Close()
+
+	if _, ok := <-s.C; ok {
+		t.Error("subscription not closed")
+	}
+	if _, ok := <-b.Subscribe("", "").C; ok {
+		t.Error("subscription to a closed broker not closed")
+	}
+
+	b.Publish(&repo.Event{Repo: "a"}) // ignored
+}
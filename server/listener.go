@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/coreos/go-systemd/v22/activation"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// UnixPrefix is the host prefix of Unix domain socket listeners, followed by the socket path.
+	UnixPrefix = "unix:"
+	// SystemdPrefix is the host prefix of systemd socket activation listeners, followed by the socket name
+	// (FileDescriptorName= in the socket unit, defaults to the unit name).
+	SystemdPrefix = "systemd:"
+)
+
+// ListenOptions are the options of a listener.
+type ListenOptions struct {
+	// SocketMode is the permission mode of a Unix domain socket, it is left to the umask if 0.
+	SocketMode fs.FileMode
+	// SocketGroup is the name or ID of the group owning a Unix domain socket, it is left unchanged if empty.
+	SocketGroup string
+}
+
+// Listen opens a listener for a host string, which is either a TCP address, "unix:" followed by a socket path
+// or "systemd:" followed by the name of a socket passed by systemd socket activation.
+func Listen(host string, opts ListenOptions) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(host, UnixPrefix); ok {
+		return listenUnix(path, opts)
+	}
+	if name, ok := strings.CutPrefix(host, SystemdPrefix); ok {
+		return listenSystemd(name)
+	}
+
+	return net.Listen("tcp", host)
+}
+
+func listenUnix(path string, opts ListenOptions) (_ net.Listener, err error) {
+	path = filepath.Clean(path)
+
+	// remove a stale socket left behind by a crash, but never a regular file
+	if fi, err := os.Lstat(path); err == nil && fi.Mode().Type() == fs.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrap(err, "failed to remove stale socket")
+		}
+	}
+
+	// the socket is created in a private directory and linked into place only after its group and mode are changed,
+	// so that it's never reachable with the permissions left by the umask
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".nero")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make socket directory")
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "s")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmpPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	l.SetUnlinkOnClose(false) // the temporary path is removed with the directory
+	defer func() {
+		if err != nil {
+			_ = l.Close()
+		}
+	}()
+
+	if opts.SocketGroup != "" {
+		gid, err := lookupGroup(opts.SocketGroup)
+		if err == nil {
+			err = os.Chown(tmpPath, -1, gid)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to change socket group")
+		}
+	}
+	if opts.SocketMode != 0 {
+		if err := os.Chmod(tmpPath, opts.SocketMode); err != nil {
+			return nil, errors.Wrap(err, "failed to change socket mode")
+		}
+	}
+
+	if err := os.Link(tmpPath, path); err != nil { // fails if the path exists
+		return nil, errors.Wrap(err, "failed to link socket")
+	}
+
+	return &unixListener{UnixListener: l, addr: &net.UnixAddr{Name: path, Net: "unix"}}, nil
+}
+
+// unixListener is a Unix domain socket listener, which removes the socket on close.
+type unixListener struct {
+	*net.UnixListener
+	addr *net.UnixAddr
+}
+
+// Addr returns the listener's network address, the path the socket was linked to.
+func (ul *unixListener) Addr() net.Addr {
+	return ul.addr
+}
+
+// Close closes the listener and removes the socket.
+func (ul *unixListener) Close() error {
+	err := ul.UnixListener.Close()
+	if err0 := os.Remove(ul.addr.Name); err == nil && err0 != nil && !errors.Is(err0, os.ErrNotExist) {
+		err = errors.Wrap(err0, "failed to remove socket")
+	}
+
+	return err
+}
+
+// lookupGroup looks up a group ID by the group name or ID.
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(g.Gid)
+}
+
+var (
+	systemdListeners     map[string][]net.Listener
+	systemdListenersErr  error
+	systemdListenersOnce sync.Once
+	systemdMu            sync.Mutex // guards taking listeners
+)
+
+func listenSystemd(name string) (net.Listener, error) {
+	systemdListenersOnce.Do(func() {
+		systemdListeners, systemdListenersErr = activation.ListenersWithNames()
+	})
+	if systemdListenersErr != nil {
+		return nil, errors.Wrap(systemdListenersErr, "failed to get systemd sockets")
+	}
+
+	systemdMu.Lock()
+	defer systemdMu.Unlock()
+
+	// each socket is only served once, take the first one left
+	ls := systemdListeners[name]
+	for i, l := range ls {
+		if l != nil {
+			ls[i] = nil
+			return l, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no systemd socket named %s passed, check FileDescriptorName= of the socket unit", name)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nero.sock")
+
+	// a stale socket left behind by a crash
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	l, err := Listen(UnixPrefix+path, ListenOptions{SocketMode: 0o660})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Addr().String() != path {
+		t.Errorf("addr = %s, want %s", l.Addr(), path)
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Type() != fs.ModeSocket || fi.Mode().Perm() != 0o660 {
+		t.Errorf("mode = %s", fi.Mode())
+	}
+
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket kept after close: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("temporary files kept: %v", entries)
+	}
+}
+
+func TestListenUnixRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nero.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if l, err := Listen(UnixPrefix+path, ListenOptions{}); err == nil {
+		_ = l.Close()
+		t.Fatal("listened over a regular file")
+	}
+
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("regular file changed: %q, %v", data, err)
+	}
+}
@@ -1,36 +1,80 @@
 package v2
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"github.com/zlataovce/nero/derivative"
 	"github.com/zlataovce/nero/internal/errors"
 	"github.com/zlataovce/nero/repo/media"
 	"github.com/zlataovce/nero/repo/media/meta"
+	"github.com/zlataovce/nero/repo/media/placeholder"
 	"github.com/zlataovce/nero/server/api"
 	"github.com/zlataovce/nero/server/api/nekos/v2"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
+	"golang.org/x/exp/maps"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 )
 
 type category struct {
-	Format string `json:"format"`
+	Format  string    `json:"format"`
+	Formats *[]string `json:"formats,omitempty"`
 }
 
 func (s *Server) GetCategories(_ context.Context, _ v2.GetCategoriesRequestObject) (v2.GetCategoriesResponseObject, error) {
 	res := make(v2.GetCategories200JSONResponse)
-	for i := range s.repos {
-		res[i] = category{Format: "gif"} // TODO: make an educated guess about the content
+	for i, r := range s.repos.Map() {
+		c := category{Format: formatName(r.Format())}
+
+		// mixed categories list all of their formats, most common first
+		counts := r.Formats()
+		delete(counts, media.FormatUnknown)
+		if len(counts) > 1 {
+			formats := maps.Keys(counts)
+			slices.SortFunc(formats, func(a, b media.Format) int {
+				if counts[a] != counts[b] {
+					return counts[b] - counts[a]
+				}
+				return int(a) - int(b)
+			})
+
+			names := make([]string, len(formats))
+			for j, f := range formats {
+				names[j] = formatName(f)
+			}
+			c.Formats = &names
+		}
+
+		res[i] = c
 	}
 
 	return res, nil
 }
 
-func (s *Server) Search(_ context.Context, request v2.SearchRequestObject) (v2.SearchResponseObject, error) {
-	if request.Params.Type < 1 || request.Params.Type > 2 {
+// formatName returns the nekos.best name of a media format, repositories without known items default to gif.
+func formatName(f media.Format) string {
+	switch f {
+	case media.FormatImage:
+		return "png"
+	case media.FormatVideo:
+		return "mp4"
+	}
+
+	return "gif"
+}
+
+func (s *Server) Search(ctx context.Context, request v2.SearchRequestObject) (v2.SearchResponseObject, error) {
+	if request.Params.Type < 1 || request.Params.Type > 3 {
 		return v2.Search400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "invalid type"}), nil
 	}
 
@@ -42,17 +86,24 @@ func (s *Server) Search(_ context.Context, request v2.SearchRequestObject) (v2.S
 		needed = 20
 	}
 
+	var color string
+	if request.Params.Color != nil {
+		if color = string(*request.Params.Color); !placeholder.ValidColor(color) {
+			return v2.Search400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "invalid color"}), nil
+		}
+	}
+
 	var res []*media.Media
 	if request.Params.Category != nil {
-		r, ok := s.repos[*request.Params.Category]
+		r, ok := s.repos.Get(*request.Params.Category)
 		if !ok {
 			return v2.Search400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "invalid category"}), nil
 		}
 
-		res = r.Find(request.Params.Query, media.Format(request.Params.Type), needed)
+		res = r.Find(ctx, request.Params.Query, media.Format(request.Params.Type), color, needed)
 	} else {
-		for _, r := range s.repos {
-			res0 := r.Find(request.Params.Query, media.Format(request.Params.Type), needed)
+		for _, r := range s.repos.Map() {
+			res0 := r.Find(ctx, request.Params.Query, media.Format(request.Params.Type), color, needed)
 			if needed < len(res0) {
 				res0 = res0[:needed]
 			}
@@ -69,8 +120,8 @@ func (s *Server) Search(_ context.Context, request v2.SearchRequestObject) (v2.S
 	return &filesRes{server: s, items: res}, nil
 }
 
-func (s *Server) GetCategoryFiles(_ context.Context, request v2.GetCategoryFilesRequestObject) (v2.GetCategoryFilesResponseObject, error) {
-	r, ok := s.repos[request.Category]
+func (s *Server) GetCategoryFiles(ctx context.Context, request v2.GetCategoryFilesRequestObject) (v2.GetCategoryFilesResponseObject, error) {
+	r, ok := s.repos.Get(request.Category)
 	if !ok {
 		return v2.GetCategoryFiles404JSONResponse(v2.Error{Code: http.StatusNotFound, Message: "category not found"}), nil
 	}
@@ -83,11 +134,16 @@ func (s *Server) GetCategoryFiles(_ context.Context, request v2.GetCategoryFiles
 		num = 20
 	}
 
-	return &filesRes{server: s, items: r.Random(num)}, nil
+	var exclude []media.Format
+	if request.Params.Video != nil && !*request.Params.Video {
+		exclude = append(exclude, media.FormatVideo)
+	}
+
+	return &filesRes{server: s, items: r.Random(ctx, num, exclude...)}, nil
 }
 
-func (s *Server) GetCategoryFile(_ context.Context, request v2.GetCategoryFileRequestObject) (v2.GetCategoryFileResponseObject, error) {
-	r, ok := s.repos[request.Category]
+func (s *Server) GetCategoryFile(ctx context.Context, request v2.GetCategoryFileRequestObject) (v2.GetCategoryFileResponseObject, error) {
+	r, ok := s.repos.Get(request.Category)
 	if !ok {
 		return v2.GetCategoryFile404JSONResponse(v2.Error{Code: http.StatusNotFound, Message: "category not found"}), nil
 	}
@@ -102,7 +158,61 @@ func (s *Server) GetCategoryFile(_ context.Context, request v2.GetCategoryFileRe
 		return v2.GetCategoryFile404JSONResponse(v2.Error{Code: http.StatusNotFound, Message: "file not found"}), nil
 	}
 
-	return &fileRes{item: m}, nil
+	params := request.Params
+	if params.Thumbnail != nil {
+		if params.Width != nil || params.Height != nil || params.Fit != nil {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "thumbnail can't be combined with a size"}), nil
+		}
+
+		d, ok := m.Derivatives[*params.Thumbnail]
+		if !ok {
+			return v2.GetCategoryFile404JSONResponse(v2.Error{Code: http.StatusNotFound, Message: "thumbnail not found"}), nil
+		}
+
+		return &fileRes{item: m, path: d.Path}, nil
+	}
+	if params.Width == nil && params.Height == nil {
+		if params.Fit != nil {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "fit requires a width or a height"}), nil
+		}
+
+		return &fileRes{item: m, path: m.Path}, nil
+	}
+	if s.resizer == nil {
+		return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "resizing is disabled"}), nil
+	}
+
+	var opts derivative.Options
+	if params.Width != nil {
+		if !s.resizer.Allowed(*params.Width) {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "width not allowed"}), nil
+		}
+		opts.Width = *params.Width
+	}
+	if params.Height != nil {
+		if !s.resizer.Allowed(*params.Height) {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "height not allowed"}), nil
+		}
+		opts.Height = *params.Height
+	}
+	if params.Fit != nil {
+		fit, ok := derivative.ParseFit(string(*params.Fit))
+		if !ok {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: "invalid fit"}), nil
+		}
+		opts.Fit = fit
+	}
+
+	b, mimeType, err := s.resizer.Resize(ctx, r, m, opts)
+	if err != nil {
+		if errors.Is(err, derivative.ErrUnsupported) {
+			return v2.GetCategoryFile400JSONResponse(v2.Error{Code: http.StatusBadRequest, Message: err.Error()}), nil
+		}
+
+		return nil, err
+	}
+
+	return &resizedFileRes{item: m, data: b, mimeType: mimeType}, nil
 }
 
 func (s *Server) makeRequestUrl(r *http.Request) *url.URL {
@@ -127,12 +237,36 @@ func (s *Server) makeRequestUrl(r *http.Request) *url.URL {
 	return u
 }
 
+// tracer is the tracer of response encoding and storage reads, a no-op until a global tracer provider is set.
+var tracer = otel.Tracer("github.com/zlataovce/nero/server/nekos/v2")
+
+// endSpan records an error to a span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// contentTypes are the content types of media file extensions missing in the built-in table of the mime package,
+// which would otherwise depend on the system MIME type database.
+var contentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+}
+
 type fileRes struct {
 	item *media.Media
+	path string // of the media or one of its derivatives
 }
 
-func (fr *fileRes) VisitGetCategoryFileResponse(w http.ResponseWriter, r *http.Request) error {
-	f, err := os.Open(fr.item.Path)
+func (fr *fileRes) VisitGetCategoryFileResponse(w http.ResponseWriter, r *http.Request) (err error) {
+	_, span := tracer.Start(r.Context(), "storage.read", trace.WithAttributes(attribute.String("file.path", fr.path)))
+	defer func() { endSpan(span, err) }()
+
+	f, err := os.Open(fr.path)
 	if err != nil {
 		return errors.Wrap(err, "failed to open media")
 	}
@@ -146,13 +280,36 @@ func (fr *fileRes) VisitGetCategoryFileResponse(w http.ResponseWriter, r *http.R
 	if err != nil {
 		return errors.Wrap(err, "failed to stat media")
 	}
+	span.SetAttributes(attribute.Int64("file.size", fi.Size()))
 
 	writeHeaderMeta(w.Header(), fr.item.Meta)
+	if contentType, ok := contentTypes[filepath.Ext(fi.Name())]; ok {
+		w.Header().Set("Content-Type", contentType)
+	}
 
 	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
 	return err
 }
 
+type resizedFileRes struct {
+	item     *media.Media
+	data     []byte
+	mimeType string
+}
+
+func (rfr *resizedFileRes) VisitGetCategoryFileResponse(w http.ResponseWriter, r *http.Request) error {
+	var modTime time.Time // of the original, for conditional requests
+	if fi, err := os.Stat(rfr.item.Path); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	writeHeaderMeta(w.Header(), rfr.item.Meta)
+	w.Header().Set("Content-Type", rfr.mimeType)
+
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(rfr.data))
+	return nil
+}
+
 type filesRes struct {
 	server *Server
 	items  []*media.Media
@@ -162,6 +319,9 @@ func (fr *filesRes) VisitSearchResponse(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
+	_, span := tracer.Start(r.Context(), "encode results", trace.WithAttributes(attribute.Int("nero.results", len(fr.items))))
+	defer span.End()
+
 	u := fr.server.makeRequestUrl(r)
 	return json.NewEncoder(w).Encode(v2.Search200JSONResponse{Results: wrapResults(u, fr.items)})
 }
@@ -170,6 +330,9 @@ func (fr *filesRes) VisitGetCategoryFilesResponse(w http.ResponseWriter, r *http
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
+	_, span := tracer.Start(r.Context(), "encode results", trace.WithAttributes(attribute.Int("nero.results", len(fr.items))))
+	defer span.End()
+
 	u := fr.server.makeRequestUrl(r)
 	return json.NewEncoder(w).Encode(v2.GetCategoryFiles200JSONResponse{Results: wrapResults(u, fr.items)})
 }
@@ -184,7 +347,11 @@ func wrapResults(base *url.URL, ms []*media.Media) []v2.Result {
 }
 
 func wrapResult(base *url.URL, m *media.Media) v2.Result {
-	res := v2.Result{Url: base.JoinPath(m.ID.String() + filepath.Ext(m.Path)).String()}
+	res := v2.Result{
+		Url:         base.JoinPath(m.ID.String() + filepath.Ext(m.Path)).String(),
+		Dimensions:  wrapDimensions(m.Dimensions),
+		Placeholder: wrapPlaceholder(m.Placeholder),
+	}
 
 	switch data := m.Meta.(type) {
 	case *meta.GenericMetadata:
@@ -198,6 +365,35 @@ func wrapResult(base *url.URL, m *media.Media) v2.Result {
 	return res
 }
 
+// wrapDimensions converts media dimensions to the API representation, the frames are only included
+// for animated images and the duration for animated images and videos.
+func wrapDimensions(d *media.Dimensions) *v2.Dimensions {
+	if d == nil {
+		return nil
+	}
+
+	res := &v2.Dimensions{Width: d.Width, Height: d.Height}
+	if d.Frames > 0 {
+		frames := d.Frames
+		res.Frames = &frames
+	}
+	if d.Frames > 0 || d.Duration > 0 {
+		duration := int(d.Duration.Milliseconds())
+		res.Duration = &duration
+	}
+
+	return res
+}
+
+// wrapPlaceholder converts a media placeholder to the API representation.
+func wrapPlaceholder(p *media.Placeholder) *v2.Placeholder {
+	if p == nil {
+		return nil
+	}
+
+	return &v2.Placeholder{Blurhash: p.BlurHash, AverageColor: p.AverageColor, DominantColor: p.DominantColor}
+}
+
 func writeHeaderMeta(h http.Header, m meta.Metadata) {
 	// can't use Header.Add, because that canonicalizes the header name
 	switch data := m.(type) {
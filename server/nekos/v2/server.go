@@ -3,11 +3,12 @@ package v2
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/zlataovce/nero/derivative"
+	"github.com/zlataovce/nero/internal/errors"
 	"github.com/zlataovce/nero/repo"
 	"github.com/zlataovce/nero/server/api"
 	"github.com/zlataovce/nero/server/api/nekos/v2"
 	"go.uber.org/zap"
-	"golang.org/x/exp/maps"
 	"net/http"
 	"net/url"
 )
@@ -25,9 +26,19 @@ var (
 
 	DefaultResponseErrorHandler api.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
 
-		e := v2.Error{Code: http.StatusInternalServerError, Message: err.Error()}
+		var (
+			status = http.StatusInternalServerError
+
+			httpErr *api.HTTPError
+		)
+		if errors.As(err, &httpErr) {
+			status = httpErr.Status
+		}
+
+		w.WriteHeader(status)
+
+		e := v2.Error{Code: status, Message: err.Error()}
 		if err := json.NewEncoder(w).Encode(e); err != nil {
 			_, _ = fmt.Fprintf(w, "{\"code\":\"%d\",\"message\":\"%s\"}", http.StatusInternalServerError, "failed to serialize error")
 		}
@@ -36,26 +47,19 @@ var (
 
 // Server is a REST server for the nekos v2 API.
 type Server struct {
-	repos   map[string]*repo.Repository
+	repos   *repo.Set
 	baseURL *url.URL
+	resizer *derivative.Resizer
 	logger  *zap.Logger
 }
 
-// NewServer creates a new server with pre-defined repositories.
-func NewServer(repos []*repo.Repository, baseURL *url.URL, logger *zap.Logger) (*Server, error) {
-	reposById := make(map[string]*repo.Repository, len(repos))
-	for _, r := range repos {
-		repoId := r.ID()
-		if _, ok := reposById[repoId]; ok {
-			return nil, fmt.Errorf("duplicate repository ID %s", repoId)
-		}
-
-		reposById[repoId] = r
-	}
-
+// NewServer creates a new server serving a set of repositories, which may be replaced while serving.
+// Resizing images is disabled if resizer is nil.
+func NewServer(repos *repo.Set, baseURL *url.URL, resizer *derivative.Resizer, logger *zap.Logger) (*Server, error) {
 	return &Server{
-		repos:   reposById,
+		repos:   repos,
 		baseURL: baseURL,
+		resizer: resizer,
 		logger:  logger,
 	}, nil
 }
@@ -72,7 +76,7 @@ func NewRouter(handler v2.StrictServerInterface) http.Handler {
 
 // Repos returns all repositories available to the server.
 func (s *Server) Repos() []*repo.Repository {
-	return maps.Values(s.repos)
+	return s.repos.Values()
 }
 
 // BaseURL returns the base URL of the server.
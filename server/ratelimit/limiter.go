@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// GlobalBucket is the name of the bucket shared by routes without a specific rule.
+const GlobalBucket = "global"
+
+// sweepInterval is the minimum interval between removals of idle buckets.
+const sweepInterval = time.Minute
+
+// Rule is a token bucket rate limiting rule.
+type Rule struct {
+	// Limit is the bucket capacity, i.e. the number of requests allowed in a burst.
+	Limit int
+	// Period is the time it takes to refill an empty bucket.
+	Period time.Duration
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	// Allowed is whether a token was available.
+	Allowed bool
+	// Bucket is the name of the bucket.
+	Bucket string
+	// Limit is the bucket capacity.
+	Limit int
+	// Remaining is the number of tokens left in the bucket.
+	Remaining int
+	// Reset is the time at which the bucket will be full again.
+	Reset time.Time
+	// RetryAfter is the time until a token becomes available, zero if the request was allowed.
+	RetryAfter time.Duration
+}
+
+type bucketKey struct {
+	bucket, client string
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a token bucket rate limiter, keeping a bucket per client and rule.
+type Limiter struct {
+	global *Rule
+	routes map[string]Rule
+
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// New creates a Limiter with a global rule and route-specific rules, keyed by route pattern.
+// The global rule may be nil, routes without a specific rule are not limited then.
+func New(global *Rule, routes map[string]Rule) *Limiter {
+	return &Limiter{
+		global:  global,
+		routes:  routes,
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+// HasRoutes returns whether the limiter has any route-specific rules.
+func (l *Limiter) HasRoutes() bool {
+	return len(l.routes) > 0
+}
+
+// Take tries to take a token from a client's bucket for a route, returns false if the route is not limited.
+func (l *Limiter) Take(route, client string, now time.Time) (Result, bool) {
+	name, rule := route, (*Rule)(nil)
+	if r, ok := l.routes[route]; ok {
+		rule = &r
+	} else {
+		name, rule = GlobalBucket, l.global
+	}
+	if rule == nil || rule.Limit <= 0 || rule.Period <= 0 {
+		return Result{}, false
+	}
+
+	var (
+		capacity = float64(rule.Limit)
+		perToken = rule.Period / time.Duration(rule.Limit)
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	key := bucketKey{bucket: name, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+		b.last = now
+	}
+
+	res := Result{Bucket: name, Limit: rule.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = now.Add(time.Duration((capacity - b.tokens) * float64(perToken)))
+	return res, true
+}
+
+// sweep removes buckets that would have been refilled completely, they are equivalent to new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		rule := l.global
+		if r, ok := l.routes[k.bucket]; ok {
+			rule = &r
+		}
+
+		if rule == nil || now.Sub(b.last) >= rule.Period {
+			delete(l.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterTake(t *testing.T) {
+	var (
+		start = time.Unix(1_700_000_000, 0)
+		rule  = Rule{Limit: 3, Period: 3 * time.Second} // a token per second
+	)
+
+	type take struct {
+		after     time.Duration // since start
+		route     string
+		client    string
+		allowed   bool
+		remaining int
+	}
+	tests := []struct {
+		name   string
+		global *Rule
+		routes map[string]Rule
+		takes  []take
+	}{
+		{
+			name:   "exhaustion",
+			global: &rule,
+			takes: []take{
+				{allowed: true, remaining: 2},
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{allowed: false, remaining: 0},
+				{after: 500 * time.Millisecond, allowed: false, remaining: 0},
+			},
+		},
+		{
+			name:   "refill",
+			global: &rule,
+			takes: []take{
+				{allowed: true, remaining: 2},
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{after: time.Second, allowed: true, remaining: 0},
+				{after: time.Second, allowed: false, remaining: 0},
+				{after: 10 * time.Second, allowed: true, remaining: 2}, // capped at the capacity
+			},
+		},
+		{
+			name:   "separate clients",
+			global: &rule,
+			takes: []take{
+				{client: "a", allowed: true, remaining: 2},
+				{client: "a", allowed: true, remaining: 1},
+				{client: "a", allowed: true, remaining: 0},
+				{client: "a", allowed: false, remaining: 0},
+				{client: "b", allowed: true, remaining: 2},
+			},
+		},
+		{
+			name:   "route rule",
+			global: &rule,
+			routes: map[string]Rule{"/search": {Limit: 1, Period: time.Minute}},
+			takes: []take{
+				{route: "/search", allowed: true, remaining: 0},
+				{route: "/search", allowed: false, remaining: 0},
+				{route: "/other", allowed: true, remaining: 2}, // the global bucket is separate
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.global, tt.routes)
+			for i, tk := range tt.takes {
+				res, ok := l.Take(tk.route, tk.client, start.Add(tk.after))
+				if !ok {
+					t.Fatalf("take %d: route %q not limited", i, tk.route)
+				}
+				if res.Allowed != tk.allowed || res.Remaining != tk.remaining {
+					t.Errorf("take %d: got allowed %t, remaining %d, want %t, %d", i, res.Allowed, res.Remaining, tk.allowed, tk.remaining)
+				}
+				if !res.Allowed && res.RetryAfter <= 0 {
+					t.Errorf("take %d: denied without a retry delay", i)
+				}
+				if res.Reset.Before(start.Add(tk.after)) {
+					t.Errorf("take %d: reset %s in the past", i, res.Reset)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterRetryAfter(t *testing.T) {
+	var (
+		l   = New(&Rule{Limit: 2, Period: 10 * time.Second}, nil)
+		now = time.Unix(1_700_000_000, 0)
+	)
+	l.Take("", "a", now)
+	l.Take("", "a", now)
+
+	res, _ := l.Take("", "a", now.Add(2*time.Second))
+	if res.Allowed {
+		t.Fatal("empty bucket allowed a request")
+	}
+	if want := 3 * time.Second; res.RetryAfter != want { // 5s per token, 2s refilled
+		t.Errorf("got retry after %s, want %s", res.RetryAfter, want)
+	}
+
+	if res, _ = l.Take("", "a", now.Add(2*time.Second+res.RetryAfter)); !res.Allowed {
+		t.Error("request denied after the retry delay")
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	tests := []struct {
+		name   string
+		global *Rule
+		routes map[string]Rule
+		route  string
+	}{
+		{name: "no rules"},
+		{name: "no global rule", routes: map[string]Rule{"/search": {Limit: 1, Period: time.Second}}, route: "/other"},
+		{name: "zero limit", global: &Rule{Limit: 0, Period: time.Second}},
+		{name: "zero period", global: &Rule{Limit: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := New(tt.global, tt.routes).Take(tt.route, "a", time.Now()); ok {
+				t.Error("route limited")
+			}
+		})
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	var (
+		l   = New(&Rule{Limit: 1, Period: time.Second}, nil)
+		now = time.Unix(1_700_000_000, 0)
+	)
+	l.Take("", "a", now)
+	l.Take("", "b", now.Add(sweepInterval))
+
+	if _, ok := l.buckets[bucketKey{bucket: GlobalBucket, client: "a"}]; ok {
+		t.Error("idle bucket not swept")
+	}
+	if _, ok := l.buckets[bucketKey{bucket: GlobalBucket, client: "b"}]; !ok {
+		t.Error("active bucket swept")
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/auth"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc derives the client key of a request, used for picking its bucket.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys requests by the client IP address (api.ClientIP).
+func ByIP(r *http.Request) string {
+	return "ip:" + api.ClientIP(r)
+}
+
+// ByKey creates a KeyFunc keying requests by their authenticated principal (see auth.PrincipalFrom)
+// or by their API key, if it's the key of a repository in the set. Other requests fall back to ByIP,
+// so that clients can't get a fresh bucket by sending made-up credentials.
+//
+// The principal is only known if the middleware runs after the authentication middlewares.
+func ByKey(repos *repo.Set) KeyFunc {
+	return func(r *http.Request) string {
+		if p := auth.PrincipalFrom(r.Context()); p != nil {
+			return "principal:" + p.Name
+		}
+
+		if key := r.Header.Get("X-Nero-Key"); key != "" && isRepoKey(repos, key) {
+			// don't keep the raw credentials around
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + hex.EncodeToString(sum[:])
+		}
+
+		return ByIP(r)
+	}
+}
+
+// isRepoKey checks whether a key is the key of any repository in the set, in constant time for each of them.
+func isRepoKey(repos *repo.Set, key string) bool {
+	var found bool
+	for _, r := range repos.Values() {
+		if expectedKey, ok := r.Meta().Value(repo.AuthKey); ok && subtle.ConstantTimeCompare([]byte(key), []byte(expectedKey)) == 1 {
+			found = true
+		}
+	}
+
+	return found
+}
+
+// Middleware creates a middleware limiting the request rate of clients, keyed by keyFunc.
+// Limited requests are answered with a 429 Too Many Requests status, translated to a response by errHandler.
+//
+// The x-rate-limit-bucket, x-rate-limit-limit, x-rate-limit-remaining and x-rate-limit-reset headers
+// are set on all limited routes, as done by nekos.best.
+func Middleware(l *Limiter, keyFunc KeyFunc, errHandler api.ErrorHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if Allow(l, keyFunc, errHandler)(w, r) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Allow creates a function taking a token for a request from the bucket of its client, keyed by keyFunc,
+// as done by Middleware. It returns whether the request is allowed, limited requests are already answered.
+// Useful for limiting only some requests, i.e. failed authentication attempts.
+func Allow(l *Limiter, keyFunc KeyFunc, errHandler api.ErrorHandler) func(w http.ResponseWriter, r *http.Request) bool {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		res, ok := l.Take(routePattern(l, r), keyFunc(r), time.Now())
+		if !ok {
+			return true
+		}
+
+		h := w.Header()
+		h.Set("x-rate-limit-bucket", res.Bucket)
+		h.Set("x-rate-limit-limit", strconv.Itoa(res.Limit))
+		h.Set("x-rate-limit-remaining", strconv.Itoa(res.Remaining))
+		h.Set("x-rate-limit-reset", res.Reset.UTC().Format(time.RFC3339))
+
+		if !res.Allowed {
+			h.Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			errHandler(w, r, &api.HTTPError{
+				Err:    fmt.Errorf("rate limit exceeded, retry in %s", res.RetryAfter.Round(time.Millisecond)),
+				Status: http.StatusTooManyRequests,
+			})
+			return false
+		}
+
+		return true
+	}
+}
+
+// routePattern resolves the chi route pattern of a request ahead of routing, returns an empty string if there is none.
+func routePattern(l *Limiter, r *http.Request) string {
+	if !l.HasRoutes() {
+		return "" // no need to resolve anything
+	}
+
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+
+	tctx := chi.NewRouteContext()
+	if !rctx.Routes.Match(tctx, r.Method, r.URL.Path) {
+		return ""
+	}
+
+	return tctx.RoutePattern()
+}
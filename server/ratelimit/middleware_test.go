@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/auth"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestByKey(t *testing.T) {
+	repos, err := repo.NewSet(
+		repo.NewMemory("pat", repo.Metadata{repo.AuthKey: "secret"}, zap.NewNop()),
+		repo.NewMemory("open", nil, zap.NewNop()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		bearer    string
+		principal *auth.Principal
+		want      string // the expected client key prefix
+	}{
+		{name: "anonymous", want: "ip:"},
+		{name: "repository key", key: "secret", want: "key:"},
+		{name: "unknown key", key: "junk", want: "ip:"},
+		{name: "unverified bearer token", bearer: "Bearer junk", want: "ip:"},
+		{name: "principal", principal: &auth.Principal{Name: "client"}, want: "principal:client"},
+		{name: "principal with key", key: "junk", principal: &auth.Principal{Name: "client"}, want: "principal:client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/pat", nil)
+			if tt.key != "" {
+				r.Header.Set("X-Nero-Key", tt.key)
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", tt.bearer)
+			}
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+
+			got := ByKey(repos)(r)
+			if len(got) < len(tt.want) || got[:len(tt.want)] != tt.want {
+				t.Errorf("got client key %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByKeyJunkKeysShareBucket(t *testing.T) {
+	repos, err := repo.NewSet(repo.NewMemory("pat", repo.Metadata{repo.AuthKey: "secret"}, zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := Middleware(New(&Rule{Limit: 2, Period: time.Minute}, nil), ByKey(repos), func(w http.ResponseWriter, r *http.Request, err error) {
+		var he *api.HTTPError
+		if errors.As(err, &he) {
+			w.WriteHeader(he.Status)
+		}
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var codes []int
+	for _, key := range []string{"junk1", "junk2", "junk3"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Nero-Key", key)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("got status codes %v, want the third request limited", codes)
+	}
+}
@@ -1,15 +1,25 @@
 package server
 
 import (
+	"github.com/zlataovce/nero/audit"
+	"github.com/zlataovce/nero/derivative"
 	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/metrics"
+	"github.com/zlataovce/nero/registry"
 	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/auth"
+	"github.com/zlataovce/nero/server/events"
 	"github.com/zlataovce/nero/server/nekos/v2"
+	"github.com/zlataovce/nero/server/ratelimit"
 	"github.com/zlataovce/nero/server/v1"
+	"github.com/zlataovce/nero/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"go.uber.org/zap"
 	"net/http"
+	"net/netip"
 	"net/url"
 )
 
@@ -17,47 +27,135 @@ var corsOpts = cors.Options{
 	AllowedOrigins:   []string{"https://*", "http://*"},
 	AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE"},
 	AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-	ExposedHeaders:   []string{"Link"},
+	ExposedHeaders:   []string{"Link", "x-rate-limit-bucket", "x-rate-limit-limit", "x-rate-limit-remaining", "x-rate-limit-reset"},
 	AllowCredentials: false,
 	MaxAge:           300,
 }
 
+// RouterOptions are the options of an API router.
+type RouterOptions struct {
+	// TrustedProxies are the networks of reverse proxies trusted to report the client address.
+	TrustedProxies []netip.Prefix
+	// JWTVerifier verifies bearer tokens, bearer token authentication is disabled if nil.
+	// Only used by the nero API.
+	JWTVerifier *auth.JWTVerifier
+	// CertMapper maps verified TLS client certificates to principals, client certificate authentication
+	// is disabled if nil. Bearer tokens take precedence. Only used by the nero API.
+	CertMapper *auth.CertMapper
+	// Registry manages the repositories of the set, repository management is disabled if nil or disabled.
+	// Only used by the nero API.
+	Registry *registry.Registry
+	// AdminKey is the key granting access to repository management, only bearer tokens with the admin scope
+	// have access if empty. Only used by the nero API.
+	AdminKey string
+	// AuditLog records mutating operations, auditing is disabled if nil.
+	// Only used by the nero API.
+	AuditLog *audit.Log
+	// Broker is the source of event streams, it should be fed the changes of the repositories.
+	// Only used by the nero API, an empty broker is created if nil.
+	Broker *events.Broker
+	// MaxBodySize is the maximum size of request bodies in bytes, request bodies are not limited if 0.
+	// Only used by the nero API.
+	MaxBodySize int64
+	// Resizer resizes images on demand, resizing is disabled if nil. Only used by the nekos API.
+	Resizer *derivative.Resizer
+	// Metrics records request metrics, metrics are not recorded if nil.
+	Metrics *metrics.Metrics
+	// AccessLogger is the logger of the access log, defaults to the router logger.
+	AccessLogger *zap.Logger
+	// Tracing is whether requests should be traced with the global tracer provider.
+	Tracing bool
+	// Limiter limits the request rate of clients, rate limiting is disabled if nil.
+	Limiter *ratelimit.Limiter
+	// LimiterKeyFunc identifies clients for rate limiting, defaults to ratelimit.ByIP.
+	LimiterKeyFunc ratelimit.KeyFunc
+}
+
 // NewNeroRouter creates a new nero API router.
-func NewNeroRouter(repos []*repo.Repository, logger *zap.Logger) (http.Handler, error) {
-	srv, err := v1.NewServer(repos, logger)
+func NewNeroRouter(repos *repo.Set, opts RouterOptions, logger *zap.Logger) (http.Handler, error) {
+	broker := opts.Broker
+	if broker == nil {
+		broker = events.NewBroker(1)
+	}
+
+	srv, err := v1.NewServer(repos, opts.Registry, opts.AdminKey, opts.AuditLog, broker, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create nero v1 api handler")
 	}
 
 	r := chi.NewRouter()
+	if opts.Metrics != nil {
+		r.Use(opts.Metrics.Middleware("nero"))
+	}
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RequestLogger(&middleware.DefaultLogFormatter{
-		Logger:  zap.NewStdLog(logger),
-		NoColor: true,
-	}))
+	if opts.Tracing {
+		r.Use(tracing.Middleware("nero"))
+	}
+	r.Use(api.RealIP(opts.TrustedProxies))
+	r.Use(api.AccessLog(opts.accessLogger(logger)))
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(corsOpts))
+	if opts.CertMapper != nil {
+		r.Use(v1.ClientCertAuth(opts.CertMapper))
+	}
+	if opts.JWTVerifier != nil {
+		var allowFailure func(http.ResponseWriter, *http.Request) bool
+		if opts.Limiter != nil { // rejected requests never reach the limiter, count them by their address
+			allowFailure = ratelimit.Allow(opts.Limiter, ratelimit.ByIP, v1.DefaultResponseErrorHandler)
+		}
+
+		r.Use(v1.BearerAuth(opts.JWTVerifier, allowFailure))
+	}
+	if opts.Limiter != nil { // after authentication, clients may be keyed by their principal
+		r.Use(ratelimit.Middleware(opts.Limiter, opts.keyFunc(), v1.DefaultResponseErrorHandler))
+	}
+	if opts.MaxBodySize > 0 {
+		r.Use(middleware.RequestSize(opts.MaxBodySize))
+	}
 	r.Mount("/api/v1", v1.NewRouter(srv))
 
 	return r, nil
 }
 
 // NewNekosRouter creates a new nekos API router.
-func NewNekosRouter(repos []*repo.Repository, baseURL *url.URL, logger *zap.Logger) (http.Handler, error) {
-	srv, err := v2.NewServer(repos, baseURL, logger)
+func NewNekosRouter(repos *repo.Set, baseURL *url.URL, opts RouterOptions, logger *zap.Logger) (http.Handler, error) {
+	srv, err := v2.NewServer(repos, baseURL, opts.Resizer, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create nekos v2 api handler")
 	}
 
 	r := chi.NewRouter()
+	if opts.Metrics != nil {
+		r.Use(opts.Metrics.Middleware("nekos"))
+	}
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RequestLogger(&middleware.DefaultLogFormatter{
-		Logger:  zap.NewStdLog(logger),
-		NoColor: true,
-	}))
+	if opts.Tracing {
+		r.Use(tracing.Middleware("nekos"))
+	}
+	r.Use(api.RealIP(opts.TrustedProxies))
+	r.Use(api.AccessLog(opts.accessLogger(logger)))
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(corsOpts))
+	if opts.Limiter != nil {
+		r.Use(ratelimit.Middleware(opts.Limiter, opts.keyFunc(), v2.DefaultResponseErrorHandler))
+	}
 	r.Mount("/api/v2", v2.NewRouter(srv))
 
 	return r, nil
 }
+
+func (ro *RouterOptions) keyFunc() ratelimit.KeyFunc {
+	if ro.LimiterKeyFunc != nil {
+		return ro.LimiterKeyFunc
+	}
+
+	return ratelimit.ByIP
+}
+
+func (ro *RouterOptions) accessLogger(logger *zap.Logger) *zap.Logger {
+	if ro.AccessLogger != nil {
+		return ro.AccessLogger
+	}
+
+	return logger
+}
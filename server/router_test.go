@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/zlataovce/nero/internal/mediatest"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/auth"
+	"github.com/zlataovce/nero/server/ratelimit"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestNeroRouterLimitsInvalidTokens checks that requests with invalid bearer tokens are rate limited,
+// even though they're rejected before reaching the rate limiting middleware.
+func TestNeroRouterLimitsInvalidTokens(t *testing.T) {
+	repos, err := repo.NewSet(repo.NewMemory("pat", nil, zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := auth.NewJWTVerifier(auth.JWTOptions{Secret: "0123456789abcdef0123456789abcdef", ScopeClaim: "scope"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewNeroRouter(repos, RouterOptions{
+		JWTVerifier:    v,
+		Limiter:        ratelimit.New(&ratelimit.Rule{Limit: 2, Period: time.Minute}, nil),
+		LimiterKeyFunc: ratelimit.ByKey(repos),
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []int
+	for _, token := range []string{"forged1", "forged2", "forged3"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/pat", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+
+	// anonymous requests from the same address share the bucket
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pat", nil))
+	codes = append(codes, w.Code)
+
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	if !slices.Equal(codes, want) {
+		t.Errorf("got status codes %v, want %v", codes, want)
+	}
+}
+
+func TestNeroRouterUploadLimits(t *testing.T) {
+	dir := t.TempDir()
+	r, err := repo.NewFile("pat", filepath.Join(dir, "pat"), filepath.Join(dir, "pat.lock"), repo.Metadata{repo.AuthKey: "secret"}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.SetLimits(repo.Limits{MIMETypes: []string{"image/png"}, MaxSize: 1000, MinWidth: 8})
+
+	repos, err := repo.NewSet(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewNeroRouter(repos, RouterOptions{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "too large", data: make([]byte, 1001), want: http.StatusRequestEntityTooLarge},
+		{name: "type not allowed", data: mediatest.JPEG(t, mediatest.Image(8, 8)), want: http.StatusUnsupportedMediaType},
+		{name: "too small", data: mediatest.PNG(t, mediatest.Image(4, 4)), want: http.StatusUnprocessableEntity},
+		{name: "allowed", data: mediatest.PNG(t, mediatest.Image(8, 8)), want: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]string{"data": base64.StdEncoding.EncodeToString(test.data)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/repos/pat", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Nero-Key", "secret")
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != test.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, test.want, w.Body)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(r.Path()); len(entries) != 1 || len(r.Items()) != 1 {
+		t.Errorf("stored files %v, want only the allowed one", entries)
+	}
+}
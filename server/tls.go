@@ -0,0 +1,145 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// certCheckInterval is the minimum interval between checks for changed certificate files.
+const certCheckInterval = 10 * time.Second
+
+// TLSOptions are the options of a TLS listener.
+type TLSOptions struct {
+	// CertPath is the path of the PEM-encoded certificate chain.
+	CertPath string
+	// KeyPath is the path of the PEM-encoded private key.
+	KeyPath string
+	// ClientCAPath is the path of PEM-encoded CA certificates verifying client certificates,
+	// client certificates are not requested if empty.
+	ClientCAPath string
+	// RequireClientCert is whether clients without a verified certificate should be rejected during the handshake.
+	RequireClientCert bool
+}
+
+// NewTLSConfig creates a TLS server configuration, the certificate is reloaded when its files change.
+func NewTLSConfig(opts TLSOptions, logger *zap.Logger) (*tls.Config, error) {
+	cl, err := NewCertLoader(opts.CertPath, opts.KeyPath, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cl.GetCertificate,
+	}
+	if opts.ClientCAPath != "" {
+		b, err := os.ReadFile(filepath.Clean(opts.ClientCAPath))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read client ca certificates")
+		}
+
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.ClientCAPath)
+		}
+
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if opts.RequireClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return cfg, nil
+}
+
+// CertLoader loads a certificate and reloads it when its files change,
+// so that renewed certificates are picked up without a restart.
+type CertLoader struct {
+	certPath, keyPath string
+	logger            *zap.Logger
+
+	cert    *tls.Certificate
+	modTime time.Time // of the newer file
+	checked time.Time
+	mu      sync.Mutex
+}
+
+// NewCertLoader creates a CertLoader, loading the certificate.
+func NewCertLoader(certPath, keyPath string, logger *zap.Logger) (*CertLoader, error) {
+	cl := &CertLoader{
+		certPath: filepath.Clean(certPath),
+		keyPath:  filepath.Clean(keyPath),
+		logger:   logger,
+	}
+
+	modTime, err := cl.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return cl, nil
+}
+
+// GetCertificate returns the current certificate, it is meant to be used as tls.Config.GetCertificate.
+// The files are checked for changes at most every 10 seconds, the previous certificate is kept if reloading fails.
+func (cl *CertLoader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if now := time.Now(); now.Sub(cl.checked) >= certCheckInterval {
+		cl.checked = now
+
+		modTime, err := cl.stat()
+		if err == nil && !modTime.Equal(cl.modTime) {
+			err = cl.load(modTime)
+			if err == nil {
+				cl.logger.Info("reloaded tls certificate", zap.String("path", cl.certPath))
+			}
+		}
+		if err != nil {
+			cl.logger.Error("failed to reload tls certificate, keeping the previous one", zap.Error(err))
+		}
+	}
+
+	return cl.cert, nil
+}
+
+// load loads the certificate, the lock must be held.
+func (cl *CertLoader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(cl.certPath, cl.keyPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load tls certificate")
+	}
+
+	cl.cert = &cert
+	cl.modTime = modTime
+	cl.checked = time.Now()
+	return nil
+}
+
+// stat returns the modification time of the newer certificate file.
+func (cl *CertLoader) stat() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{cl.certPath, cl.keyPath} {
+		fi, err := os.Stat(path) // follows symlinks, i.e. of mounted Kubernetes secrets
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "failed to stat tls certificate")
+		}
+
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+
+	return latest, nil
+}
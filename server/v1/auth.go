@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/zlataovce/nero/server/auth"
+	"net/http"
+	"strings"
+)
+
+// BearerAuth creates a middleware authenticating requests with an "Authorization: Bearer" JWT.
+// Requests without a bearer token are passed through, so that they can still authenticate with a repository key.
+//
+// Requests with an invalid token are passed to allowFailure before being rejected, so that failed attempts
+// can be rate limited (see ratelimit.Allow); they're not answered further if it returns false. It may be nil.
+func BearerAuth(v *auth.JWTVerifier, allowFailure func(w http.ResponseWriter, r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p, err := v.Verify(token)
+			if err != nil {
+				if allowFailure != nil && !allowFailure(w, r) {
+					return
+				}
+
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				DefaultResponseErrorHandler(w, r, &api.HTTPError{
+					Err:    errors.Wrap(err, "invalid bearer token"),
+					Status: http.StatusUnauthorized,
+					Type:   string(v1.Unauthorized),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// ClientCertAuth creates a middleware authenticating requests with a verified TLS client certificate.
+// Requests without one are passed through, so that they can still authenticate with a bearer token or a repository key.
+func ClientCertAuth(m *auth.CertMapper) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p := m.Principal(r.TLS.VerifiedChains[0][0])
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// authorize checks whether the request is authorized to modify a repository,
+// either with a bearer token granting access to it or with the repository key.
+// Returns the name of the key or token used, empty for repositories without authentication.
+// The name is also recorded in the access log entry of the request.
+func authorize(ctx context.Context, r *repo.Repository, key string) (name string, ok bool) {
+	if name, ok = canModify(ctx, r, key); ok {
+		api.SetAccessKey(ctx, name)
+	}
+
+	return name, ok
+}
+
+// canModify checks whether the request is authorized to modify a repository like authorize, without recording it.
+func canModify(ctx context.Context, r *repo.Repository, key string) (name string, ok bool) {
+	if p := auth.PrincipalFrom(ctx); p != nil && p.CanAccess(r.ID()) {
+		return "token:" + p.Name, true
+	}
+
+	if expectedKey, ok := r.Meta().Value(repo.AuthKey); ok {
+		return repo.AuthKey, key == expectedKey
+	}
+	return "", true // no required key, no authentication needed
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/zlataovce/nero/server/events"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// keepAliveInterval is the interval of comments sent to idle event streams, so that proxies don't close them.
+const keepAliveInterval = 30 * time.Second
+
+func (s *Server) GetEvents(_ context.Context, request v1.GetEventsRequestObject) (v1.GetEventsResponseObject, error) {
+	return &eventsRes{
+		sub:    s.broker.Subscribe("", api.MakeString(request.Params.LastEventID)),
+		logger: s.logger,
+	}, nil
+}
+
+func (s *Server) GetRepoEvents(_ context.Context, request v1.GetRepoEventsRequestObject) (v1.GetRepoEventsResponseObject, error) {
+	r, ok := s.repos.Get(request.Repo)
+	if !ok {
+		return v1.GetRepoEvents400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown repository"}), nil
+	}
+
+	return &eventsRes{
+		sub:    s.broker.Subscribe(r.ID(), api.MakeString(request.Params.LastEventID)),
+		logger: s.logger,
+	}, nil
+}
+
+type eventsRes struct {
+	sub    *events.Subscription
+	logger *zap.Logger
+}
+
+func (er *eventsRes) VisitGetEventsResponse(w http.ResponseWriter, r *http.Request) error {
+	return er.stream(w, r)
+}
+
+func (er *eventsRes) VisitGetRepoEventsResponse(w http.ResponseWriter, r *http.Request) error {
+	return er.stream(w, r)
+}
+
+// stream writes server-sent events until the client disconnects or the subscription is closed.
+// Write errors are not returned, they're caused by the client going away and the response has been started already.
+func (er *eventsRes) stream(w http.ResponseWriter, r *http.Request) error {
+	defer er.sub.Close()
+
+	rc := http.NewResponseController(w)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+
+	for _, entry := range er.sub.Backlog {
+		if err := er.write(w, entry); err != nil {
+			return nil
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case entry, ok := <-er.sub.C:
+			if !ok { // closed by the broker, the client has to reconnect
+				return nil
+			}
+
+			if err := er.write(w, entry); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+func (er *eventsRes) write(w http.ResponseWriter, entry *events.Entry) error {
+	e, err := v1.WrapEvent(entry.Event)
+	if err != nil {
+		er.logger.Error("failed to convert event", zap.String("repo", entry.Event.Repo), zap.Error(err))
+		return nil // skip it
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		er.logger.Error("failed to serialize event", zap.String("repo", entry.Event.Repo), zap.Error(err))
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", entry.ID, e.Type, b)
+	return err
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"context"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/registry"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api"
+	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/zlataovce/nero/server/auth"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var (
+	adminUnauthorizedError = &api.HTTPError{
+		Err:    errors.New("wrong or missing admin key or token"),
+		Status: http.StatusUnauthorized,
+		Type:   string(v1.Unauthorized),
+	}
+)
+
+func (s *Server) GetRepos(ctx context.Context, request v1.GetReposRequestObject) (v1.GetReposResponseObject, error) {
+	if _, ok := s.authorizeAdmin(ctx, api.MakeString(request.Params.XNeroKey)); !ok {
+		return nil, adminUnauthorizedError
+	}
+
+	repos := s.repos.Values()
+	slices.SortFunc(repos, func(a, b *repo.Repository) int {
+		return strings.Compare(a.ID(), b.ID())
+	})
+
+	res := make(v1.GetRepos200JSONResponse, len(repos))
+	for i, r := range repos {
+		res[i] = s.wrapRepo(r)
+	}
+
+	return res, nil
+}
+
+func (s *Server) PostRepos(ctx context.Context, request v1.PostReposRequestObject) (v1.PostReposResponseObject, error) {
+	keyName, ok := s.authorizeAdmin(ctx, api.MakeString(request.Params.XNeroKey))
+	if !ok {
+		return nil, adminUnauthorizedError
+	}
+	if s.registry == nil {
+		return v1.PostRepos400JSONResponse(v1.Error{Type: v1.BadRequest, Description: registry.ErrDisabled.Error()}), nil
+	}
+
+	var meta repo.Metadata
+	if request.Body.Meta != nil {
+		meta = maps.Clone(repo.Metadata(*request.Body.Meta))
+	}
+
+	r, err := s.registry.Create(request.Body.Id, meta)
+	if err != nil {
+		if e, ok := registryError(err); ok {
+			return v1.PostRepos400JSONResponse(e), nil
+		}
+
+		return nil, err
+	}
+
+	s.logger.Info("created repository", zap.String("repo", r.ID()), zap.String("key", keyName))
+	return v1.PostRepos200JSONResponse(s.wrapRepo(r)), nil
+}
+
+func (s *Server) PatchRepo(ctx context.Context, request v1.PatchRepoRequestObject) (v1.PatchRepoResponseObject, error) {
+	keyName, ok := s.authorizeAdmin(ctx, api.MakeString(request.Params.XNeroKey))
+	if !ok {
+		return nil, adminUnauthorizedError
+	}
+	if s.registry == nil {
+		return v1.PatchRepo400JSONResponse(v1.Error{Type: v1.BadRequest, Description: registry.ErrDisabled.Error()}), nil
+	}
+
+	r, ok := s.repos.Get(request.Repo)
+	if !ok {
+		return v1.PatchRepo400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown repository"}), nil
+	}
+
+	var p registry.Patch
+	if request.Body.Meta != nil {
+		meta := maps.Clone(repo.Metadata(*request.Body.Meta))
+		p.Meta = &meta
+	}
+	if request.Body.Id != nil && *request.Body.Id != r.ID() {
+		p.ID = request.Body.Id
+	}
+
+	r, err := s.registry.Update(r.ID(), p)
+	if err != nil {
+		if e, ok := registryError(err); ok {
+			return v1.PatchRepo400JSONResponse(e), nil
+		}
+
+		return nil, err
+	}
+
+	s.logger.Info("modified repository", zap.String("repo", request.Repo), zap.String("key", keyName))
+	return v1.PatchRepo200JSONResponse(s.wrapRepo(r)), nil
+}
+
+func (s *Server) DeleteRepo(ctx context.Context, request v1.DeleteRepoRequestObject) (v1.DeleteRepoResponseObject, error) {
+	keyName, ok := s.authorizeAdmin(ctx, api.MakeString(request.Params.XNeroKey))
+	if !ok {
+		return nil, adminUnauthorizedError
+	}
+	if s.registry == nil {
+		return v1.DeleteRepo400JSONResponse(v1.Error{Type: v1.BadRequest, Description: registry.ErrDisabled.Error()}), nil
+	}
+
+	purge := request.Params.Purge != nil && *request.Params.Purge
+
+	r, err := s.registry.Delete(request.Repo, purge)
+	if err != nil {
+		if e, ok := registryError(err); ok {
+			return v1.DeleteRepo400JSONResponse(e), nil
+		}
+
+		return nil, err
+	}
+
+	s.logger.Info("deleted repository", zap.String("repo", r.ID()), zap.Bool("purge", purge), zap.String("key", keyName))
+
+	res := s.wrapRepo(r)
+	res.Managed = true // not managed anymore, but it was
+	return v1.DeleteRepo200JSONResponse(res), nil
+}
+
+// authorizeAdmin checks whether the request is authorized to manage repositories,
+// either with a bearer token with the admin scope or with the admin key.
+// Returns the name of the key or token used, it is also recorded in the access log entry of the request.
+func (s *Server) authorizeAdmin(ctx context.Context, key string) (name string, ok bool) {
+	defer func() {
+		if ok {
+			api.SetAccessKey(ctx, name)
+		}
+	}()
+
+	if p := auth.PrincipalFrom(ctx); p != nil && p.Admin {
+		return "token:" + p.Name, true
+	}
+
+	if s.adminKey != "" && key == s.adminKey {
+		return "admin_key", true
+	}
+	return "", false // no admin key, only tokens can manage repositories
+}
+
+func (s *Server) wrapRepo(r *repo.Repository) v1.Repo {
+	meta := r.Meta()
+	if meta == nil {
+		meta = make(repo.Metadata)
+	}
+
+	return v1.Repo{
+		Id:      r.ID(),
+		Managed: s.registry != nil && s.registry.Managed(r.ID()),
+		Items:   len(r.Items()),
+		Meta:    v1.RepoMetadata(meta),
+	}
+}
+
+// registryError converts a registry error to an API error, returns false if it's not a client error.
+func registryError(err error) (v1.Error, bool) {
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		return v1.Error{Type: v1.NotFound, Description: err.Error()}, true
+	case errors.Is(err, registry.ErrDisabled), errors.Is(err, registry.ErrNotManaged),
+		errors.Is(err, registry.ErrExists), errors.Is(err, registry.ErrInvalidID), errors.Is(err, registry.ErrInvalidMeta):
+		return v1.Error{Type: v1.BadRequest, Description: err.Error()}, true
+	}
+
+	return v1.Error{}, false
+}
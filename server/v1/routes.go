@@ -3,30 +3,34 @@ package v1
 import (
 	"context"
 	"encoding/base64"
+	"github.com/zlataovce/nero/audit"
 	"github.com/zlataovce/nero/internal/errors"
 	"github.com/zlataovce/nero/repo"
-	"github.com/zlataovce/nero/repo/media"
 	"github.com/zlataovce/nero/repo/media/meta"
 	"github.com/zlataovce/nero/server/api"
 	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/zlataovce/nero/server/auth"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
 	"net/http"
 )
 
 var (
 	unauthorizedError = &api.HTTPError{
-		Err:    errors.New("wrong or missing key"),
+		Err:    errors.New("wrong or missing key or token"),
 		Status: http.StatusUnauthorized,
 		Type:   string(v1.Unauthorized),
 	}
 )
 
-func (s *Server) PostRepo(_ context.Context, request v1.PostRepoRequestObject) (v1.PostRepoResponseObject, error) {
-	r, ok := s.repos[request.Repo]
+func (s *Server) PostRepo(ctx context.Context, request v1.PostRepoRequestObject) (v1.PostRepoResponseObject, error) {
+	r, ok := s.repos.Get(request.Repo)
 	if !ok {
 		return v1.PostRepo400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown repository"}), nil
 	}
 
-	if !checkKey(r, api.MakeString(request.Params.XNeroKey)) {
+	keyName, ok := authorize(ctx, r, api.MakeString(request.Params.XNeroKey))
+	if !ok {
 		return nil, unauthorizedError
 	}
 
@@ -45,12 +49,23 @@ func (s *Server) PostRepo(_ context.Context, request v1.PostRepoRequestObject) (
 		return v1.PostRepo400JSONResponse(v1.Error{Type: v1.BadRequest, Description: "failed to decode data"}), nil
 	}
 
-	m0, err := r.Create(d, m)
+	m0, err := r.Create(ctx, d, m)
 	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrTooLarge):
+			return v1.PostRepo413JSONResponse(v1.Error{Type: v1.PayloadTooLarge, Description: err.Error()}), nil
+		case errors.Is(err, repo.ErrTypeNotAllowed):
+			return v1.PostRepo415JSONResponse(v1.Error{Type: v1.UnsupportedMediaType, Description: err.Error()}), nil
+		case errors.Is(err, repo.ErrDimensionsNotAllowed):
+			return v1.PostRepo422JSONResponse(v1.Error{Type: v1.InvalidDimensions, Description: err.Error()}), nil
+		}
+
 		return nil, err
 	}
 
-	m1, err := wrapMedia(m0)
+	s.record(ctx, &audit.Entry{Action: audit.ActionCreate, Key: keyName, Repo: r.ID(), Item: m0.ID, After: m0})
+
+	m1, err := v1.WrapMedia(m0)
 	if err != nil {
 		return nil, err
 	}
@@ -58,13 +73,14 @@ func (s *Server) PostRepo(_ context.Context, request v1.PostRepoRequestObject) (
 	return v1.PostRepo200JSONResponse(m1), nil
 }
 
-func (s *Server) DeleteRepoId(_ context.Context, request v1.DeleteRepoIdRequestObject) (v1.DeleteRepoIdResponseObject, error) {
-	r, ok := s.repos[request.Repo]
+func (s *Server) DeleteRepoId(ctx context.Context, request v1.DeleteRepoIdRequestObject) (v1.DeleteRepoIdResponseObject, error) {
+	r, ok := s.repos.Get(request.Repo)
 	if !ok {
 		return v1.DeleteRepoId400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown repository"}), nil
 	}
 
-	if !checkKey(r, api.MakeString(request.Params.XNeroKey)) {
+	keyName, ok := authorize(ctx, r, api.MakeString(request.Params.XNeroKey))
+	if !ok {
 		return nil, unauthorizedError
 	}
 
@@ -73,11 +89,13 @@ func (s *Server) DeleteRepoId(_ context.Context, request v1.DeleteRepoIdRequestO
 		return v1.DeleteRepoId400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown item id"}), nil
 	}
 
-	if err := r.Remove(request.Id); err != nil {
+	if err := r.Remove(ctx, request.Id); err != nil {
 		return nil, err
 	}
 
-	m0, err := wrapMedia(m)
+	s.record(ctx, &audit.Entry{Action: audit.ActionDelete, Key: keyName, Repo: r.ID(), Item: m.ID, Before: m})
+
+	m0, err := v1.WrapMedia(m)
 	if err != nil {
 		return nil, err
 	}
@@ -85,38 +103,111 @@ func (s *Server) DeleteRepoId(_ context.Context, request v1.DeleteRepoIdRequestO
 	return v1.DeleteRepoId200JSONResponse(m0), nil
 }
 
-func wrapMedia(m *media.Media) (v1.Media, error) {
-	var (
-		m0  = &v1.Media_Meta{}
-		err error
-	)
-	switch v := wrapMetadata(m.Meta).(type) {
-	case v1.GenericMetadata:
-		err = m0.FromGenericMetadata(v)
-	case v1.AnimeMetadata:
-		err = m0.FromAnimeMetadata(v)
+func (s *Server) GetAudit(ctx context.Context, request v1.GetAuditRequestObject) (v1.GetAuditResponseObject, error) {
+	if s.auditLog == nil {
+		return v1.GetAudit400JSONResponse(v1.Error{Type: v1.BadRequest, Description: "audit log is disabled"}), nil
+	}
+
+	key := api.MakeString(request.Params.XNeroKey)
+
+	q := audit.Query{Limit: 100}
+	if request.Params.Repo != nil {
+		r, ok := s.repos.Get(*request.Params.Repo)
+		if !ok {
+			return v1.GetAudit400JSONResponse(v1.Error{Type: v1.NotFound, Description: "unknown repository"}), nil
+		}
+
+		if _, ok := authorize(ctx, r, key); !ok {
+			return nil, unauthorizedError
+		}
+
+		q.Repo = r.ID()
+	}
+	if request.Params.Item != nil {
+		q.Item = *request.Params.Item
+	}
+	if request.Params.Since != nil {
+		q.Since = *request.Params.Since
+	}
+	if request.Params.Until != nil {
+		q.Until = *request.Params.Until
+	}
+	if request.Params.Limit != nil {
+		q.Limit = min(max(*request.Params.Limit, 1), 1000)
 	}
 
+	entries, err := s.auditLog.Query(q, func(e *audit.Entry) bool {
+		r, ok := s.repos.Get(e.Repo)
+		if !ok { // removed repository, only visible with a token granting access to it
+			p := auth.PrincipalFrom(ctx)
+			return p != nil && p.CanAccess(e.Repo)
+		}
+
+		_, ok = canModify(ctx, r, key)
+		return ok
+	})
 	if err != nil {
-		return v1.Media{}, err
+		return nil, err
+	}
+
+	res := make(v1.GetAudit200JSONResponse, len(entries))
+	for i, e := range entries {
+		if res[i], err = wrapAuditEntry(e); err != nil {
+			return nil, err
+		}
 	}
 
-	return v1.Media{
-		Format: wrapFormat(m.Format),
-		Id:     m.ID,
-		Meta:   m0,
-	}, nil
+	return res, nil
 }
 
-func wrapFormat(f media.Format) v1.MediaFormat {
-	switch f {
-	case media.FormatImage:
-		return v1.Image
-	case media.FormatAnimatedImage:
-		return v1.AnimatedImage
-	default:
-		return v1.Unknown
+// record appends an entry to the audit log, filling in the request details from the context.
+// Failures are only logged, the audited operation has already happened at this point.
+func (s *Server) record(ctx context.Context, e *audit.Entry) {
+	if s.auditLog == nil {
+		return
 	}
+
+	e.ClientIP = api.ContextClientIP(ctx)
+	e.RequestID = middleware.GetReqID(ctx)
+	if err := s.auditLog.Record(e); err != nil {
+		s.logger.Error(
+			"failed to record audit log entry",
+			zap.String("repo", e.Repo),
+			zap.String("id", e.Item.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func wrapAuditEntry(e *audit.Entry) (v1.AuditEntry, error) {
+	res := v1.AuditEntry{
+		Action:    v1.AuditAction(e.Action),
+		ClientIp:  e.ClientIP,
+		Item:      e.Item,
+		Key:       e.Key,
+		Repo:      e.Repo,
+		RequestId: e.RequestID,
+		Time:      e.Time,
+	}
+
+	if e.Before != nil {
+		m, err := v1.WrapMedia(e.Before)
+		if err != nil {
+			return v1.AuditEntry{}, err
+		}
+
+		res.Before = &m
+	}
+	if e.After != nil {
+		m, err := v1.WrapMedia(e.After)
+		if err != nil {
+			return v1.AuditEntry{}, err
+		}
+
+		res.After = &m
+	}
+
+	return res, nil
 }
 
 func unwrapMetadata(v interface{}) meta.Metadata {
@@ -135,27 +226,3 @@ func unwrapMetadata(v interface{}) meta.Metadata {
 
 	return nil
 }
-
-func wrapMetadata(v meta.Metadata) interface{} {
-	switch m := v.(type) {
-	case *meta.GenericMetadata:
-		return v1.GenericMetadata{
-			Source:     api.MakeOptString(m.Source),
-			Artist:     api.MakeOptString(m.Artist),
-			ArtistLink: api.MakeOptString(m.ArtistLink),
-		}
-	case *meta.AnimeMetadata:
-		return v1.AnimeMetadata{
-			Name: api.MakeOptString(m.Name),
-		}
-	}
-
-	return nil
-}
-
-func checkKey(r *repo.Repository, key string) bool {
-	if expectedKey, ok := r.Meta().Value(repo.AuthKey); ok {
-		return key == expectedKey
-	}
-	return true // no required key, no authentication needed
-}
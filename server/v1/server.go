@@ -3,21 +3,35 @@ package v1
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/zlataovce/nero/audit"
 	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/registry"
 	"github.com/zlataovce/nero/repo"
 	"github.com/zlataovce/nero/server/api"
 	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/zlataovce/nero/server/events"
 	"go.uber.org/zap"
-	"golang.org/x/exp/maps"
 	"net/http"
 )
 
 var (
 	DefaultRequestErrorHandler api.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
 
-		e := v1.Error{Type: v1.BadRequest, Description: err.Error()}
+		var (
+			status = http.StatusBadRequest
+			type_  = v1.BadRequest
+
+			maxBytesErr *http.MaxBytesError
+		)
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+			type_ = v1.PayloadTooLarge
+		}
+
+		w.WriteHeader(status)
+
+		e := v1.Error{Type: type_, Description: err.Error()}
 		if err := json.NewEncoder(w).Encode(e); err != nil {
 			_, _ = fmt.Fprintf(w, "{\"type\":\"%s\",\"description\":\"%s\"}", v1.InternalError, "failed to serialize error")
 		}
@@ -34,6 +48,7 @@ var (
 		)
 		if errors.As(err, &httpErr) {
 			status = httpErr.Status
+			type_ = statusErrorType(status)
 
 			if httpErr.Type != "" {
 				type_ = v1.ErrorType(httpErr.Type)
@@ -49,27 +64,53 @@ var (
 	}
 )
 
+// statusErrorType guesses the error type of an HTTP status code.
+func statusErrorType(status int) v1.ErrorType {
+	switch status {
+	case http.StatusBadRequest:
+		return v1.BadRequest
+	case http.StatusUnauthorized:
+		return v1.Unauthorized
+	case http.StatusNotFound:
+		return v1.NotFound
+	case http.StatusTooManyRequests:
+		return v1.TooManyRequests
+	case http.StatusRequestEntityTooLarge:
+		return v1.PayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return v1.UnsupportedMediaType
+	}
+
+	return v1.InternalError
+}
+
 // Server is a REST server for the nero v1 API.
 type Server struct {
-	repos  map[string]*repo.Repository
-	logger *zap.Logger
+	repos    *repo.Set
+	registry *registry.Registry
+	adminKey string
+	auditLog *audit.Log
+	broker   *events.Broker
+	logger   *zap.Logger
 }
 
-// NewServer creates a new server with pre-defined repositories.
-func NewServer(repos []*repo.Repository, logger *zap.Logger) (*Server, error) {
-	reposById := make(map[string]*repo.Repository, len(repos))
-	for _, r := range repos {
-		repoId := r.ID()
-		if _, ok := reposById[repoId]; ok {
-			return nil, fmt.Errorf("duplicate repository ID %s", repoId)
-		}
-
-		reposById[repoId] = r
+// NewServer creates a new server serving a set of repositories, which may be replaced while serving.
+// The registry manages the repositories of the set, it may be nil, repository management is disabled then.
+// Repository management requires a bearer token with the admin scope or the admin key, if not empty.
+// The audit log may be nil, mutating operations are not audited then.
+// The broker is the source of event streams, it should be fed the changes of the repositories.
+func NewServer(repos *repo.Set, reg *registry.Registry, adminKey string, auditLog *audit.Log, broker *events.Broker, logger *zap.Logger) (*Server, error) {
+	if reg != nil && !reg.Enabled() {
+		reg = nil
 	}
 
 	return &Server{
-		repos:  reposById,
-		logger: logger,
+		repos:    repos,
+		registry: reg,
+		adminKey: adminKey,
+		auditLog: auditLog,
+		broker:   broker,
+		logger:   logger,
 	}, nil
 }
 
@@ -85,5 +126,5 @@ func NewRouter(handler v1.StrictServerInterface) http.Handler {
 
 // Repos returns all repositories available to the server.
 func (s *Server) Repos() []*repo.Repository {
-	return maps.Values(s.repos)
+	return s.repos.Values()
 }
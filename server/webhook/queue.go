@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// failedDir is the queue subdirectory of deliveries that ran out of attempts.
+const failedDir = "failed"
+
+// delivery is a pending webhook request.
+type delivery struct {
+	// ID is the unique delivery ID.
+	ID uuid.UUID `json:"id"`
+	// Hook is the name of the target hook.
+	Hook string `json:"hook"`
+	// Event is the event type.
+	Event string `json:"event"`
+	// Payload is the request body.
+	Payload json.RawMessage `json:"payload"`
+	// Attempts is the number of failed attempts.
+	Attempts int `json:"attempts"`
+	// NextAttempt is the time of the next attempt.
+	NextAttempt time.Time `json:"next_attempt"`
+
+	inFlight bool
+}
+
+// queue is a directory of pending deliveries, a file per delivery.
+type queue struct {
+	path string
+}
+
+func newQueue(path string) (*queue, error) {
+	if err := os.MkdirAll(filepath.Join(path, failedDir), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to make queue directories")
+	}
+
+	return &queue{path: path}, nil
+}
+
+// load reads all pending deliveries.
+func (q *queue) load() ([]*delivery, error) {
+	entries, err := os.ReadDir(q.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read queue directory")
+	}
+
+	var ds []*delivery
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(q.path, e.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read queued delivery")
+		}
+
+		var d delivery
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse queued delivery %s", e.Name())
+		}
+
+		ds = append(ds, &d)
+	}
+
+	return ds, nil
+}
+
+// save persists a delivery, replacing the previous state atomically.
+func (q *queue) save(d *delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize delivery")
+	}
+
+	path := q.file(d)
+	if err := os.WriteFile(path+".tmp", b, 0600); err != nil {
+		return errors.Wrap(err, "failed to write delivery")
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return errors.Wrap(err, "failed to move delivery")
+	}
+
+	return nil
+}
+
+// remove deletes a completed delivery.
+func (q *queue) remove(d *delivery) error {
+	if err := os.Remove(q.file(d)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to remove delivery")
+	}
+
+	return nil
+}
+
+// fail moves a delivery to the failed subdirectory, where it is kept for inspection.
+func (q *queue) fail(d *delivery) error {
+	if err := os.Rename(q.file(d), filepath.Join(q.path, failedDir, d.ID.String()+".json")); err != nil {
+		return errors.Wrap(err, "failed to move failed delivery")
+	}
+
+	return nil
+}
+
+func (q *queue) file(d *delivery) string {
+	return filepath.Join(q.path, d.ID.String()+".json")
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// SignatureHeader is the header carrying the payload signature.
+	SignatureHeader = "X-Nero-Signature"
+	// EventHeader is the header carrying the event type.
+	EventHeader = "X-Nero-Event"
+	// DeliveryHeader is the header carrying the unique delivery ID, stable across retries.
+	DeliveryHeader = "X-Nero-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign computes the signature of a payload, in the "sha256=<hex HMAC-SHA256>" format.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a payload in constant time.
+func Verify(secret string, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
@@ -0,0 +1,371 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/zlataovce/nero/internal/errors"
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/server/api/v1"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxConcurrency is the maximum number of concurrently sent requests.
+const maxConcurrency = 4
+
+// Hook is a webhook receiving repository changes.
+type Hook struct {
+	// Name is the unique name of the hook.
+	Name string
+	// URL is the URL of the receiver.
+	URL string
+	// Secret is the secret used for signing payloads, payloads are not signed if empty.
+	Secret string
+	// Repos are the IDs of the repositories the hook is interested in, all repositories if empty.
+	Repos []string
+	// Events are the types of events the hook is interested in, all types if empty.
+	Events []repo.EventType
+}
+
+func (h *Hook) accepts(e *repo.Event) bool {
+	return (len(h.Repos) == 0 || slices.Contains(h.Repos, e.Repo)) &&
+		(len(h.Events) == 0 || slices.Contains(h.Events, e.Type))
+}
+
+// Options are the delivery options of a Dispatcher.
+type Options struct {
+	// QueuePath is the path of the persistent delivery queue directory.
+	QueuePath string
+	// MaxAttempts is the number of attempts after which a delivery is given up.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled with every following attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries.
+	MaxBackoff time.Duration
+	// Timeout is the timeout of a single request.
+	Timeout time.Duration
+}
+
+// Dispatcher delivers repository changes to webhooks, retrying failed deliveries with an exponential backoff.
+// Pending deliveries are persisted in a queue directory, so that they survive restarts.
+type Dispatcher struct {
+	hooks  map[string]*Hook
+	opts   Options
+	queue  *queue
+	client *http.Client
+	logger *zap.Logger
+
+	pending map[uuid.UUID]*delivery
+	closed  bool
+	mu      sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	sem  chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher and starts delivering the queued deliveries in the background.
+func NewDispatcher(hooks []*Hook, opts Options, logger *zap.Logger) (*Dispatcher, error) {
+	hooksByName := make(map[string]*Hook, len(hooks))
+	for _, h := range hooks {
+		if _, ok := hooksByName[h.Name]; ok {
+			return nil, fmt.Errorf("duplicate webhook name %s", h.Name)
+		}
+
+		hooksByName[h.Name] = h
+	}
+
+	q, err := newQueue(opts.QueuePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dispatcher{
+		hooks:   hooksByName,
+		opts:    opts,
+		queue:   q,
+		client:  &http.Client{Timeout: opts.Timeout},
+		logger:  logger,
+		pending: make(map[uuid.UUID]*delivery, len(ds)),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		sem:     make(chan struct{}, maxConcurrency),
+	}
+	for _, dl := range ds {
+		if _, ok := hooksByName[dl.Hook]; !ok {
+			logger.Warn(
+				"dropping delivery of removed webhook",
+				zap.String("hook", dl.Hook),
+				zap.String("id", dl.ID.String()),
+			)
+			if err := q.remove(dl); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		d.pending[dl.ID] = dl
+	}
+	if len(d.pending) > 0 {
+		logger.Info("resuming queued webhook deliveries", zap.Int("count", len(d.pending)))
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	return d, nil
+}
+
+// Handle queues deliveries of a repository change to all interested hooks, it is a repo.Listener.
+func (d *Dispatcher) Handle(e *repo.Event) {
+	var hooks []*Hook
+	for _, h := range d.hooks {
+		if h.accepts(e) {
+			hooks = append(hooks, h)
+		}
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	e0, err := v1.WrapEvent(e)
+	if err != nil {
+		d.logger.Error("failed to convert event", zap.String("repo", e.Repo), zap.Error(err))
+		return
+	}
+
+	payload, err := json.Marshal(e0)
+	if err != nil {
+		d.logger.Error("failed to serialize event", zap.String("repo", e.Repo), zap.Error(err))
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, h := range hooks {
+		dl := &delivery{
+			ID:          uuid.New(),
+			Hook:        h.Name,
+			Event:       e.Type.String(),
+			Payload:     payload,
+			NextAttempt: now,
+		}
+		if err := d.queue.save(dl); err != nil {
+			d.logger.Error("failed to queue delivery", zap.String("hook", h.Name), zap.Error(err))
+			continue
+		}
+
+		d.pending[dl.ID] = dl
+	}
+
+	d.notify()
+}
+
+// Close stops delivering, waiting for in-flight requests to complete.
+// Pending deliveries stay in the queue and are resumed by the next Dispatcher.
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.done)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+
+	return nil
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default: // already notified
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		wait := d.dispatchDue()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-d.done:
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue starts the deliveries that are due, returns the time until the next one is.
+func (d *Dispatcher) dispatchDue() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		now  = time.Now()
+		wait = time.Hour
+	)
+	for _, dl := range d.pending {
+		if dl.inFlight {
+			continue
+		}
+
+		if until := dl.NextAttempt.Sub(now); until > 0 {
+			wait = min(wait, until)
+			continue
+		}
+
+		select {
+		case d.sem <- struct{}{}:
+		default:
+			continue // at capacity, woken up again when a request completes
+		}
+
+		dl.inFlight = true
+
+		d.wg.Add(1)
+		go func(dl *delivery) {
+			defer d.wg.Done()
+			defer func() { <-d.sem }()
+
+			d.deliver(dl)
+		}(dl)
+	}
+
+	return wait
+}
+
+func (d *Dispatcher) deliver(dl *delivery) {
+	err := d.send(dl)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.notify()
+
+	dl.inFlight = false
+	if err == nil {
+		delete(d.pending, dl.ID)
+		if err := d.queue.remove(dl); err != nil {
+			d.logger.Error("failed to remove delivery", zap.String("id", dl.ID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	select {
+	case <-d.done:
+		return // aborted by Close, doesn't count as an attempt
+	default:
+	}
+
+	dl.Attempts++
+	if dl.Attempts >= d.opts.MaxAttempts {
+		d.logger.Error(
+			"giving up webhook delivery",
+			zap.String("hook", dl.Hook),
+			zap.String("id", dl.ID.String()),
+			zap.Int("attempts", dl.Attempts),
+			zap.Error(err),
+		)
+
+		delete(d.pending, dl.ID)
+		if err := d.queue.fail(dl); err != nil {
+			d.logger.Error("failed to move delivery", zap.String("id", dl.ID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	dl.NextAttempt = time.Now().Add(d.backoff(dl.Attempts))
+	d.logger.Warn(
+		"webhook delivery failed, retrying",
+		zap.String("hook", dl.Hook),
+		zap.String("id", dl.ID.String()),
+		zap.Int("attempts", dl.Attempts),
+		zap.Time("next_attempt", dl.NextAttempt),
+		zap.Error(err),
+	)
+	if err := d.queue.save(dl); err != nil {
+		d.logger.Error("failed to save delivery", zap.String("id", dl.ID.String()), zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) send(dl *delivery) error {
+	h, ok := d.hooks[dl.Hook]
+	if !ok {
+		return nil // hook was removed, nothing to deliver to
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.done: // abort in-flight requests on close
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nero-webhook")
+	req.Header.Set(EventHeader, dl.Event)
+	req.Header.Set(DeliveryHeader, dl.ID.String())
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.Secret, dl.Payload))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("receiver responded with status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// backoff computes the delay before the next attempt, with up to 10% of random jitter.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.MaxBackoff
+	if attempts < 20 { // avoid overflowing
+		delay = min(d.opts.MinBackoff<<(attempts-1), d.opts.MaxBackoff)
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"github.com/zlataovce/nero/repo"
+	"github.com/zlataovce/nero/repo/media"
+	"github.com/zlataovce/nero/repo/media/meta"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// a known HMAC-SHA256 vector
+	const want = "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+
+	payload := []byte("The quick brown fox jumps over the lazy dog")
+	if got := Sign("key", payload); got != want {
+		t.Errorf("got signature %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		payload   string
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: "key", payload: string(payload), signature: want, want: true},
+		{name: "wrong secret", secret: "other", payload: string(payload), signature: want},
+		{name: "wrong payload", secret: "key", payload: "The quick brown fox", signature: want},
+		{name: "missing prefix", secret: "key", payload: string(payload), signature: want[len(signaturePrefix):]},
+		{name: "empty", secret: "key", payload: string(payload)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, []byte(tt.payload), tt.signature); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{opts: Options{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration // the delay without jitter
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := d.backoff(tt.attempts); got < tt.want || got > tt.want+tt.want/10 {
+				t.Fatalf("got backoff %s after %d attempts, want %s with up to 10%% jitter", got, tt.attempts, tt.want)
+			}
+		}
+	}
+}
+
+// receiver is a webhook receiver responding with the queued status codes, then with 200 OK.
+type receiver struct {
+	codes []int
+
+	requests   []*http.Request
+	bodies     [][]byte
+	mu         sync.Mutex
+	received   chan struct{}
+	httpServer *httptest.Server
+}
+
+func newReceiver(t *testing.T, codes ...int) *receiver {
+	rc := &receiver{codes: codes, received: make(chan struct{}, 100)}
+	rc.httpServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, r)
+		rc.bodies = append(rc.bodies, b)
+		code := http.StatusOK
+		if len(rc.codes) > 0 {
+			code, rc.codes = rc.codes[0], rc.codes[1:]
+		}
+		rc.mu.Unlock()
+
+		w.WriteHeader(code)
+		rc.received <- struct{}{}
+	}))
+	t.Cleanup(rc.httpServer.Close)
+
+	return rc
+}
+
+// wait waits for n requests.
+func (rc *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d requests, want %d", i, n)
+		}
+	}
+}
+
+func testEvent() *repo.Event {
+	return &repo.Event{
+		ID:    uuid.New(),
+		Type:  repo.EventCreate,
+		Repo:  "pat",
+		Time:  time.Now().UTC(),
+		Media: &media.Media{ID: uuid.New(), Format: media.FormatImage, Meta: &meta.GenericMetadata{Artist: "artist"}},
+	}
+}
+
+func newTestDispatcher(t *testing.T, url, queuePath string, maxAttempts int) *Dispatcher {
+	t.Helper()
+
+	d, err := NewDispatcher(
+		[]*Hook{{Name: "hook", URL: url, Secret: "secret"}},
+		Options{QueuePath: queuePath, MaxAttempts: maxAttempts, MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, Timeout: 5 * time.Second},
+		zap.NewNop(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+
+	return d
+}
+
+// waitQueued waits until a queue directory has n delivery files.
+func waitQueued(t *testing.T, path string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(queued(t, path)) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d queued deliveries in %s, want %d", len(queued(t, path)), path, n)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// queued returns the names of the delivery files in a queue directory.
+func queued(t *testing.T, path string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return matches
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	var (
+		rc        = newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+		queuePath = t.TempDir()
+		d         = newTestDispatcher(t, rc.httpServer.URL, queuePath, 5)
+	)
+
+	d.Handle(testEvent())
+	rc.wait(t, 3)
+	waitQueued(t, queuePath, 0) // delivered
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	id := rc.requests[0].Header.Get(DeliveryHeader)
+	for i, r := range rc.requests {
+		if got := r.Header.Get(DeliveryHeader); got != id {
+			t.Errorf("attempt %d has delivery id %s, want %s", i, got, id)
+		}
+		if got := r.Header.Get(EventHeader); got != "create" {
+			t.Errorf("attempt %d has event %s, want create", i, got)
+		}
+		if !Verify("secret", rc.bodies[i], r.Header.Get(SignatureHeader)) {
+			t.Errorf("attempt %d has an invalid signature", i)
+		}
+	}
+}
+
+func TestDispatcherGiveUp(t *testing.T) {
+	var (
+		rc        = newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		queuePath = t.TempDir()
+		d         = newTestDispatcher(t, rc.httpServer.URL, queuePath, 2)
+	)
+
+	d.Handle(testEvent())
+	rc.wait(t, 2)
+	waitQueued(t, filepath.Join(queuePath, failedDir), 1)
+	waitQueued(t, queuePath, 0)
+
+	// no further attempts after giving up
+	select {
+	case <-rc.received:
+		t.Error("delivery attempted after giving up")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDispatcherResume(t *testing.T) {
+	queuePath := t.TempDir()
+
+	// a delivery queued by a previous dispatcher, which didn't get to send it
+	q, err := newQueue(queuePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := &delivery{ID: uuid.New(), Hook: "hook", Event: "delete", Payload: []byte(`{}`), Attempts: 1, NextAttempt: time.Now()}
+	if err := q.save(dl); err != nil {
+		t.Fatal(err)
+	}
+	orphan := &delivery{ID: uuid.New(), Hook: "removed", Event: "delete", Payload: []byte(`{}`), NextAttempt: time.Now()}
+	if err := q.save(orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := newReceiver(t)
+	newTestDispatcher(t, rc.httpServer.URL, queuePath, 5)
+	rc.wait(t, 1)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if got := rc.requests[0].Header.Get(DeliveryHeader); got != dl.ID.String() {
+		t.Errorf("got delivery id %s, want %s", got, dl.ID)
+	}
+	if _, err := os.Stat(q.file(orphan)); !os.IsNotExist(err) {
+		t.Errorf("delivery of a removed hook kept: %v", err)
+	}
+}
+
+func TestDispatcherCloseTwice(t *testing.T) {
+	d := newTestDispatcher(t, "http://127.0.0.1:0", t.TempDir(), 1)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// AttrRequestID is the span attribute key of the chi request ID.
+const AttrRequestID = attribute.Key("http.request_id")
+
+// Middleware creates a middleware starting a server span for every request to an API,
+// continuing the trace of a W3C trace context sent by the client.
+// It should be used on the top-level router after middleware.RequestID, the span is named after the chi route pattern
+// resolved after routing and annotated with the request ID.
+func Middleware(api string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			if reqID := middleware.GetReqID(r.Context()); reqID != "" {
+				span.SetAttributes(AttrRequestID.String(reqID))
+			}
+
+			next.ServeHTTP(w, r)
+
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				span.SetName(r.Method + " " + rctx.RoutePattern())
+				span.SetAttributes(semconv.HTTPRoute(rctx.RoutePattern()))
+			}
+		})
+
+		return otelhttp.NewHandler(
+			handler,
+			api,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method // refined to the route pattern after routing
+			}),
+		)
+	}
+}
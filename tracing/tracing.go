@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"github.com/zlataovce/nero/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Options are the options of a tracer provider.
+type Options struct {
+	// Endpoint is the URL of the OTLP/HTTP collector endpoint, i.e. http://localhost:4318.
+	// The default /v1/traces path is used if the URL has no path.
+	Endpoint string
+	// Headers are additional HTTP headers sent with exports, i.e. for authentication.
+	Headers map[string]string
+	// ServiceName is the reported service name.
+	ServiceName string
+	// ServiceVersion is the reported service version.
+	ServiceVersion string
+	// SampleRatio is the ratio of sampled root traces, between 0 and 1.
+	// Sampling decisions of remote parents are respected.
+	SampleRatio float64
+}
+
+// New creates a tracer provider exporting spans to an OTLP collector and installs it globally,
+// along with the W3C trace context and baggage propagators.
+// The provider should be shut down to flush buffered spans.
+func New(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(
+		ctx,
+		otlptracehttp.WithEndpointURL(opts.Endpoint),
+		otlptracehttp.WithHeaders(opts.Headers),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp exporter")
+	}
+
+	res, err := resource.New(
+		ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(opts.ServiceName),
+			semconv.ServiceVersion(opts.ServiceVersion),
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create resource")
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp, nil
+}